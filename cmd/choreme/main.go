@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	"github.com/choreme/choreme/internal/api"
 	"github.com/choreme/choreme/internal/config"
 	"github.com/choreme/choreme/internal/store"
 	"github.com/gin-gonic/gin"
+
+	// Register database backends
+	_ "github.com/choreme/choreme/internal/store/mysql"
+	_ "github.com/choreme/choreme/internal/store/postgres"
+	_ "github.com/choreme/choreme/internal/store/sqlite"
 )
 
 func main() {
@@ -39,7 +45,19 @@ func main() {
 
 	// Initialize API server
 	log.Println("Initializing API server...")
-	server := api.NewServer(cfg, store)
+	server, err := api.NewServer(cfg, store)
+	if err != nil {
+		log.Fatalf("Failed to initialize API server: %v", err)
+	}
+
+	// Start background jobs
+	if cfg.Jobs.Enabled {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		server.StartJobs(ctx)
+		log.Printf("Background jobs started (schedule every %s, sweep every %s)",
+			cfg.Jobs.ScheduleInterval, cfg.Jobs.SweepInterval)
+	}
 
 	// Start server
 	addr := cfg.Server.Host + ":" + cfg.Server.Port
@@ -8,7 +8,9 @@ require (
 	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/golang-migrate/migrate/v4 v4.17.1
 	github.com/shopspring/decimal v1.4.0
+	github.com/teambition/rrule-go v1.8.2
 	golang.org/x/crypto v0.31.0
+	rsc.io/qr v0.2.0
 )
 
 require (
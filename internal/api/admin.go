@@ -0,0 +1,222 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/choreme/choreme/internal/middleware"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// getAllHouseholds lists every household on the instance.
+func (s *Server) getAllHouseholds(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	households, err := s.services.Admin.GetHouseholds(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get households")
+		return
+	}
+	s.success(c, households)
+}
+
+// getHouseholdMembers lists the members of any household.
+func (s *Server) getHouseholdMembers(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	members, err := s.services.Admin.GetHouseholdMembers(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get members")
+		return
+	}
+	s.success(c, members)
+}
+
+// createHousehold opens a household and returns it with the invitation for
+// its admin.
+func (s *Server) createHousehold(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateHouseholdRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	created, err := s.services.Admin.CreateHousehold(c.Request.Context(), actor, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to create household")
+		return
+	}
+	s.created(c, created)
+}
+
+// createAdminInvitation invites another admin to a household, for example
+// when its admin can't be reached any more.
+func (s *Server) createAdminInvitation(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Email *string `json:"email" binding:"omitempty,email"`
+	}
+	if !s.bindOptionalJSON(c, &req) {
+		return
+	}
+
+	invitation, err := s.services.Admin.CreateAdminInvitation(c.Request.Context(), actor, id, req.Email)
+	if err != nil {
+		s.serviceError(c, err, "Failed to create invitation")
+		return
+	}
+	s.created(c, invitation)
+}
+
+func (s *Server) suspendHousehold(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	household, err := s.services.Admin.SuspendHousehold(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to suspend household")
+		return
+	}
+	s.success(c, household)
+}
+
+func (s *Server) unsuspendHousehold(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	household, err := s.services.Admin.UnsuspendHousehold(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to unsuspend household")
+		return
+	}
+	s.success(c, household)
+}
+
+// impersonateUser returns an access token to act as a user with. It can't
+// be refreshed, doesn't reach the user's account and security settings, and
+// everything done with it is audited with the impersonator.
+func (s *Server) impersonateUser(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+	var req model.ImpersonateRequest
+	if !s.bindOptionalJSON(c, &req) {
+		return
+	}
+
+	user, err := s.services.Admin.Impersonate(c.Request.Context(), actor, id, req.HouseholdID)
+	if err != nil {
+		s.serviceError(c, err, "Failed to impersonate user")
+		return
+	}
+	token, err := s.jwtManager.GenerateImpersonationToken(user, actor.UserID)
+	if err != nil {
+		s.internalError(c, "Failed to generate token")
+		return
+	}
+	s.success(c, model.ImpersonationResponse{
+		Token:          token,
+		ExpiresIn:      int(s.jwtManager.TokenTTL().Seconds()),
+		User:           *user,
+		ImpersonatorID: actor.UserID,
+	})
+}
+
+// adminResetPassword mails a user a password reset link and returns it.
+func (s *Server) adminResetPassword(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	reset, err := s.services.Admin.ResetPassword(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to reset password")
+		return
+	}
+	s.success(c, reset)
+}
+
+// getInstanceAuditLogs lists the audit log of every household.
+func (s *Server) getInstanceAuditLogs(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var filters model.AuditFilters
+	filters.Action = queryString(c, "action")
+	if !s.queryInt(c, "household_id", &filters.HouseholdID) ||
+		!s.queryInt(c, "user_id", &filters.UserID) ||
+		!s.queryTimeRange(c, time.UTC, "date_from", "date_to", &filters.DateFrom, &filters.DateTo) {
+		return
+	}
+	limit, offset, ok := s.queryPage(c)
+	if !ok {
+		return
+	}
+	filters.Limit, filters.Offset = limit, offset
+
+	logs, err := s.services.Admin.GetAuditLogs(c.Request.Context(), actor, filters)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get audit logs")
+		return
+	}
+	s.success(c, logs)
+}
+
+// flagImpersonation audits everything an impersonation token does with the
+// system administrator behind it, and tells clients who that is.
+func (s *Server) flagImpersonation(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if ok && claims.ImpersonatorID != 0 {
+		c.Header("X-Impersonated-By", strconv.Itoa(claims.ImpersonatorID))
+		c.Request = c.Request.WithContext(service.WithImpersonator(c.Request.Context(), claims.ImpersonatorID))
+	}
+	c.Next()
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getAPITokens(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := s.services.Auth.GetAPITokens(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get API tokens")
+		return
+	}
+	s.success(c, tokens)
+}
+
+func (s *Server) createAPIToken(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateAPITokenRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	token, err := s.services.Auth.CreateAPIToken(c.Request.Context(), actor, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to create API token")
+		return
+	}
+	s.created(c, token)
+}
+
+func (s *Server) deleteAPIToken(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := s.services.Auth.DeleteAPIToken(c.Request.Context(), actor, id); err != nil {
+		s.serviceError(c, err, "Failed to delete API token")
+		return
+	}
+	s.success(c, gin.H{"deleted": true})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getAssignments(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	loc, ok := s.location(c, actor)
+	if !ok {
+		return
+	}
+
+	var filters model.AssignmentFilters
+	if status := queryString(c, "status"); status != nil {
+		st := model.AssignmentStatus(*status)
+		filters.Status = &st
+	}
+	if within := queryString(c, "due_within"); within != nil {
+		period := model.Period(*within)
+		filters.DueWithin = &period
+	}
+	var userID *int
+	if !s.queryInt(c, "user_id", &userID) ||
+		!s.queryInt(c, "chore_id", &filters.ChoreID) ||
+		!s.queryTimeRange(c, loc, "due_after", "due_before", &filters.DueAfter, &filters.DueBefore) ||
+		!s.queryBool(c, "completed", &filters.Completed) ||
+		!s.queryBool(c, "approved", &filters.Approved) {
+		return
+	}
+	limit, offset, ok := s.queryPage(c)
+	if !ok {
+		return
+	}
+	filters.Limit, filters.Offset = limit, offset
+
+	var assignments []*model.Assignment
+	var err error
+	if userID != nil {
+		assignments, err = s.services.Assignment.GetAssignmentsByUser(c.Request.Context(), actor, *userID, filters)
+	} else {
+		assignments, err = s.services.Assignment.GetAssignmentsByHousehold(c.Request.Context(), actor, filters)
+	}
+	if err != nil {
+		s.serviceError(c, err, "Failed to get assignments")
+		return
+	}
+
+	s.success(c, assignments)
+}
+
+func (s *Server) getAssignment(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	assignment, err := s.services.Assignment.GetAssignmentByID(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get assignment")
+		return
+	}
+
+	s.success(c, assignment)
+}
+
+func (s *Server) updateProgress(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateProgressRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	assignment, err := s.services.Assignment.UpdateProgress(c.Request.Context(), actor, id, req.PercentComplete)
+	if err != nil {
+		s.serviceError(c, err, "Failed to update progress")
+		return
+	}
+
+	s.success(c, assignment)
+}
+
+func (s *Server) completeChore(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.CompleteChoreRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	assignment, err := s.services.Assignment.CompleteChore(c.Request.Context(), actor, id, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to complete chore")
+		return
+	}
+
+	s.success(c, assignment)
+}
+
+func (s *Server) approveChore(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.ApprovalRequest
+	if !s.bindOptionalJSON(c, &req) {
+		return
+	}
+
+	assignment, err := s.services.Assignment.ApproveChore(c.Request.Context(), actor, id, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to approve chore")
+		return
+	}
+
+	s.success(c, assignment)
+}
+
+func (s *Server) rejectChore(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.ApprovalRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	assignment, err := s.services.Assignment.RejectChore(c.Request.Context(), actor, id, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to reject chore")
+		return
+	}
+
+	s.success(c, assignment)
+}
@@ -1,11 +1,16 @@
 package api
 
 import (
-	"crypto/rand"
-	"encoding/hex"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/choreme/choreme/internal/middleware"
 	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,17 +24,11 @@ func (s *Server) register(c *gin.Context) {
 		return
 	}
 
-	// Check if this is the first user (system admin)
-	isFirstUser, err := s.services.Auth.IsFirstUser(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.APIResponse{
-			Success: false,
-			Error:   "Failed to check user count",
-		})
+	user, err := s.services.Auth.Register(c.Request.Context(), &req)
+	if errors.Is(err, service.ErrForbidden) {
+		s.forbidden(c, err.Error())
 		return
 	}
-
-	user, err := s.services.Auth.Register(c.Request.Context(), &req, isFirstUser)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, model.APIResponse{
 			Success: false,
@@ -38,24 +37,28 @@ func (s *Server) register(c *gin.Context) {
 		return
 	}
 
-	token, err := s.jwtManager.GenerateToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.APIResponse{
-			Success: false,
-			Error:   "Failed to generate token",
-		})
+	tokens, ok := s.issueTokens(c, user)
+	if !ok {
 		return
 	}
 
 	c.JSON(http.StatusCreated, model.APIResponse{
 		Success: true,
-		Data: model.AuthResponse{
-			Token: token,
-			User:  *user,
-		},
+		Data:    tokens,
 	})
 }
 
+// getSetupStatus tells clients whether the first account still has to be
+// registered with the setup token and whether registration is open.
+func (s *Server) getSetupStatus(c *gin.Context) {
+	status, err := s.services.Auth.SetupStatus(c.Request.Context())
+	if err != nil {
+		s.internalError(c, "Failed to check setup status")
+		return
+	}
+	s.success(c, status)
+}
+
 func (s *Server) login(c *gin.Context) {
 	var req model.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -66,7 +69,14 @@ func (s *Server) login(c *gin.Context) {
 		return
 	}
 
-	user, err := s.services.Auth.Login(c.Request.Context(), &req)
+	if !middleware.Throttle(c, s.limiter, "login:email:"+strings.ToLower(req.Email), s.config.RateLimit.LoginEmail) {
+		return
+	}
+
+	result, err := s.services.Auth.Login(c.Request.Context(), &req)
+	if s.accountLocked(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, model.APIResponse{
 			Success: false,
@@ -74,22 +84,19 @@ func (s *Server) login(c *gin.Context) {
 		})
 		return
 	}
+	if result.Challenge != nil {
+		s.success(c, result.Challenge)
+		return
+	}
 
-	token, err := s.jwtManager.GenerateToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.APIResponse{
-			Success: false,
-			Error:   "Failed to generate token",
-		})
+	tokens, ok := s.issueTokens(c, result.User)
+	if !ok {
 		return
 	}
 
 	c.JSON(http.StatusOK, model.APIResponse{
 		Success: true,
-		Data: model.AuthResponse{
-			Token: token,
-			User:  *user,
-		},
+		Data:    tokens,
 	})
 }
 
@@ -112,55 +119,182 @@ func (s *Server) joinHousehold(c *gin.Context) {
 		return
 	}
 
-	token, err := s.jwtManager.GenerateToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.APIResponse{
-			Success: false,
-			Error:   "Failed to generate token",
-		})
+	tokens, ok := s.issueTokens(c, user)
+	if !ok {
 		return
 	}
 
 	c.JSON(http.StatusCreated, model.APIResponse{
 		Success: true,
-		Data: model.AuthResponse{
-			Token: token,
-			User:  *user,
-		},
+		Data:    tokens,
 	})
 }
 
-func (s *Server) generateInvite(c *gin.Context) {
-	householdID, ok := s.getHouseholdID(c)
+// accountLocked writes a 429 response with a Retry-After header when err is
+// a lockout, and reports whether it did.
+func (s *Server) accountLocked(c *gin.Context, err error) bool {
+	var locked *service.AccountLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(locked.Until).Seconds()))))
+	s.error(c, http.StatusTooManyRequests, err.Error())
+	return true
+}
+
+// issueTokens signs an access token for user and starts a refresh token
+// session, writing an error response when either fails.
+func (s *Server) issueTokens(c *gin.Context, user *model.User) (*model.AuthResponse, bool) {
+	token, err := s.jwtManager.GenerateToken(user)
+	if err != nil {
+		s.internalError(c, "Failed to generate token")
+		return nil, false
+	}
+	refreshToken, err := s.services.Auth.CreateSession(c.Request.Context(), user)
+	if err != nil {
+		s.internalError(c, "Failed to generate token")
+		return nil, false
+	}
+	return &model.AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.jwtManager.TokenTTL().Seconds()),
+		User:         *user,
+	}, true
+}
+
+// refresh exchanges a refresh token for a new access token and a new refresh
+// token; the presented one can't be used again.
+func (s *Server) refresh(c *gin.Context) {
+	var req model.RefreshTokenRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	user, refreshToken, err := s.services.Auth.RefreshSession(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		s.serviceError(c, err, "Failed to refresh token")
+		return
+	}
+	token, err := s.jwtManager.GenerateToken(user)
+	if err != nil {
+		s.internalError(c, "Failed to generate token")
+		return
+	}
+
+	s.success(c, model.AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.jwtManager.TokenTTL().Seconds()),
+		User:         *user,
+	})
+}
+
+// logout ends the session of the given refresh token. It needs no access
+// token, so clients can log out after theirs has expired.
+func (s *Server) logout(c *gin.Context) {
+	var req model.RefreshTokenRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	if err := s.services.Auth.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		s.serviceError(c, err, "Failed to log out")
+		return
+	}
+	s.success(c, gin.H{"logged_out": true})
+}
+
+// logoutAll ends every session of the authenticated user.
+func (s *Server) logoutAll(c *gin.Context) {
+	actor, ok := s.getActor(c)
 	if !ok {
 		return
 	}
 
-	// Generate random invite code
-	bytes := make([]byte, 4)
-	if _, err := rand.Read(bytes); err != nil {
-		c.JSON(http.StatusInternalServerError, model.APIResponse{
-			Success: false,
-			Error:   "Failed to generate invite code",
-		})
+	if err := s.services.Auth.LogoutAll(c.Request.Context(), actor); err != nil {
+		s.serviceError(c, err, "Failed to log out")
+		return
+	}
+	s.success(c, gin.H{"logged_out": true})
+}
+
+// forgotPassword mails a reset link. It answers the same whether or not the
+// address belongs to an account.
+func (s *Server) forgotPassword(c *gin.Context) {
+	var req model.ForgotPasswordRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+	if !middleware.Throttle(c, s.limiter, "forgot_password:email:"+strings.ToLower(req.Email), s.config.RateLimit.PasswordReset) {
+		return
+	}
+
+	if err := s.services.Auth.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		s.serviceError(c, err, "Failed to request password reset")
+		return
+	}
+	s.success(c, gin.H{"message": "If the address belongs to an account, a reset link has been sent"})
+}
+
+func (s *Server) resetPassword(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	if err := s.services.Auth.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		s.serviceError(c, err, "Failed to reset password")
+		return
+	}
+	s.success(c, gin.H{"password_reset": true})
+}
+
+func (s *Server) verifyEmail(c *gin.Context) {
+	var req model.VerifyEmailRequest
+	if !s.bindJSON(c, &req) {
 		return
 	}
-	inviteCode := hex.EncodeToString(bytes)
 
-	err := s.services.Household.UpdateInviteCode(c.Request.Context(), householdID, inviteCode)
+	user, err := s.services.Auth.VerifyEmail(c.Request.Context(), req.Token)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.APIResponse{
-			Success: false,
-			Error:   "Failed to update invite code",
-		})
+		s.serviceError(c, err, "Failed to verify email")
 		return
 	}
+	s.success(c, user)
+}
 
-	c.JSON(http.StatusOK, model.APIResponse{
-		Success: true,
-		Data: gin.H{
-			"invite_code": inviteCode,
-		},
+func (s *Server) resendVerification(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	if err := s.services.Auth.ResendEmailVerification(c.Request.Context(), actor); err != nil {
+		s.serviceError(c, err, "Failed to send verification email")
+		return
+	}
+	s.success(c, gin.H{"sent": true})
+}
+
+// generateInvite creates a worker invitation with the default expiry. It
+// predates POST /households/invitations, which can set the role, limits and
+// email address.
+func (s *Server) generateInvite(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := s.services.Auth.CreateInvitation(c.Request.Context(), actor, &model.CreateInvitationRequest{})
+	if err != nil {
+		s.serviceError(c, err, "Failed to create invitation")
+		return
+	}
+	s.success(c, gin.H{
+		"invite_code": invitation.Code,
+		"link":        invitation.Link,
+		"expires_at":  invitation.ExpiresAt,
 	})
 }
 
@@ -186,4 +320,11 @@ func (s *Server) healthCheck(c *gin.Context) {
 		"status": "healthy",
 		"service": "choreme",
 	})
-}
\ No newline at end of file
+}
+
+// jwks serves the public keys that verify access tokens. It is empty when
+// tokens are signed with a shared secret.
+func (s *Server) jwks(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, s.jwtManager.JWKS())
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getChores(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	loc, ok := s.location(c, actor)
+	if !ok {
+		return
+	}
+
+	var filters model.ChoreFilters
+	if status := queryString(c, "status"); status != nil {
+		st := model.AssignmentStatus(*status)
+		filters.Status = &st
+	}
+	if priority := queryString(c, "priority"); priority != nil {
+		p := model.Priority(*priority)
+		filters.Priority = &p
+	}
+	filters.Category = queryString(c, "category")
+	if !s.queryInt(c, "created_by", &filters.CreatedBy) ||
+		!s.queryInt(c, "assigned_to", &filters.AssignedTo) ||
+		!s.queryTimeRange(c, loc, "date_from", "date_to", &filters.DateFrom, &filters.DateTo) {
+		return
+	}
+	limit, offset, ok := s.queryPage(c)
+	if !ok {
+		return
+	}
+	filters.Limit, filters.Offset = limit, offset
+
+	chores, err := s.services.Chore.GetChoresByHousehold(c.Request.Context(), actor, filters)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get chores")
+		return
+	}
+
+	s.success(c, chores)
+}
+
+func (s *Server) createChore(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateChoreRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	chore, err := s.services.Chore.CreateChore(c.Request.Context(), actor, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to create chore")
+		return
+	}
+
+	s.created(c, chore)
+}
+
+func (s *Server) getChore(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	chore, err := s.services.Chore.GetChoreByID(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get chore")
+		return
+	}
+
+	s.success(c, chore)
+}
+
+func (s *Server) updateChore(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateChoreRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	chore, err := s.services.Chore.UpdateChore(c.Request.Context(), actor, id, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to update chore")
+		return
+	}
+
+	s.success(c, chore)
+}
+
+func (s *Server) deleteChore(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := s.services.Chore.DeleteChore(c.Request.Context(), actor, id); err != nil {
+		s.serviceError(c, err, "Failed to delete chore")
+		return
+	}
+
+	s.success(c, gin.H{"deleted": true})
+}
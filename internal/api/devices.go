@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/choreme/choreme/internal/auth"
+	"github.com/choreme/choreme/internal/middleware"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getDevice(c *gin.Context) (*model.HouseholdDevice, bool) {
+	device, ok := middleware.GetDevice(c)
+	if !ok {
+		s.error(c, http.StatusUnauthorized, "Device not authenticated")
+		return nil, false
+	}
+	return device, true
+}
+
+func (s *Server) getDevices(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	devices, err := s.services.Household.GetDevices(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get devices")
+		return
+	}
+	s.success(c, devices)
+}
+
+func (s *Server) registerDevice(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.RegisterDeviceRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	registration, err := s.services.Household.RegisterDevice(c.Request.Context(), actor, req.Name)
+	if err != nil {
+		s.serviceError(c, err, "Failed to register device")
+		return
+	}
+	s.created(c, registration)
+}
+
+func (s *Server) revokeDevice(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := s.services.Household.RevokeDevice(c.Request.Context(), actor, id); err != nil {
+		s.serviceError(c, err, "Failed to revoke device")
+		return
+	}
+	s.success(c, gin.H{"revoked": true})
+}
+
+// getDeviceMembers lists the household's members on a household device.
+func (s *Server) getDeviceMembers(c *gin.Context) {
+	device, ok := s.getDevice(c)
+	if !ok {
+		return
+	}
+
+	members, err := s.services.Household.GetDeviceMembers(c.Request.Context(), device)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get members")
+		return
+	}
+	s.success(c, members)
+}
+
+// pinLogin logs a member in with their PIN on a household device. The
+// access token is limited to the routes a worker needs day to day.
+func (s *Server) pinLogin(c *gin.Context) {
+	device, ok := s.getDevice(c)
+	if !ok {
+		return
+	}
+
+	var req model.PINLoginRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	user, err := s.services.Auth.PINLogin(c.Request.Context(), device, req.UserID, req.PIN)
+	if s.accountLocked(c, err) {
+		return
+	}
+	if err != nil {
+		s.serviceError(c, err, "Failed to log in")
+		return
+	}
+
+	token, err := s.jwtManager.GenerateScopedToken(user, auth.ScopePIN, s.config.Auth.PINTokenTTL)
+	if err != nil {
+		s.internalError(c, "Failed to generate token")
+		return
+	}
+	s.success(c, model.PINLoginResponse{
+		Token:     token,
+		ExpiresIn: int(s.config.Auth.PINTokenTTL.Seconds()),
+		User:      *user,
+	})
+}
@@ -1,12 +1,16 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/choreme/choreme/internal/auth"
 	"github.com/choreme/choreme/internal/middleware"
 	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -46,6 +50,21 @@ func (s *Server) getClaims(c *gin.Context) (*auth.Claims, bool) {
 	return claims, true
 }
 
+// getActor builds the service actor from the authenticated request's claims.
+func (s *Server) getActor(c *gin.Context) (service.Actor, bool) {
+	claims, ok := s.getClaims(c)
+	if !ok {
+		return service.Actor{}, false
+	}
+	permissions, _ := middleware.GetPermissions(c)
+	return service.Actor{
+		UserID:      claims.UserID,
+		HouseholdID: claims.HouseholdID,
+		Role:        claims.Role,
+		Permissions: permissions,
+	}, true
+}
+
 func (s *Server) getIDParam(c *gin.Context) (int, bool) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
@@ -70,6 +89,15 @@ func (s *Server) bindJSON(c *gin.Context, obj interface{}) bool {
 	return true
 }
 
+// bindOptionalJSON binds the request body when one was sent, leaving obj at
+// its zero value otherwise.
+func (s *Server) bindOptionalJSON(c *gin.Context, obj interface{}) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	return s.bindJSON(c, obj)
+}
+
 func (s *Server) success(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, model.APIResponse{
 		Success: true,
@@ -105,4 +133,116 @@ func (s *Server) notFound(c *gin.Context, message string) {
 
 func (s *Server) forbidden(c *gin.Context, message string) {
 	s.error(c, http.StatusForbidden, message)
-}
\ No newline at end of file
+}
+
+// serviceError maps service sentinel errors to HTTP responses. Unexpected
+// errors are reported as fallback so internal details don't leak.
+func (s *Server) serviceError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrUnauthorized):
+		s.error(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		s.notFound(c, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		s.forbidden(c, err.Error())
+	case errors.Is(err, service.ErrInvalidInput):
+		s.badRequest(c, err.Error())
+	case errors.Is(err, service.ErrConflict):
+		s.error(c, http.StatusConflict, err.Error())
+	default:
+		s.internalError(c, fallback)
+	}
+}
+
+// queryInt parses an optional integer query parameter into dst.
+func (s *Server) queryInt(c *gin.Context, name string, dst **int) bool {
+	value := c.Query(name)
+	if value == "" {
+		return true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		s.badRequest(c, "Invalid "+name+" parameter")
+		return false
+	}
+	*dst = &n
+	return true
+}
+
+// queryBool parses an optional boolean query parameter into dst.
+func (s *Server) queryBool(c *gin.Context, name string, dst **bool) bool {
+	value := c.Query(name)
+	if value == "" {
+		return true
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		s.badRequest(c, "Invalid "+name+" parameter")
+		return false
+	}
+	*dst = &b
+	return true
+}
+
+// queryTimeRange parses an optional inclusive range of RFC 3339 timestamps
+// or YYYY-MM-DD dates from the fromName and toName query parameters. Dates
+// are days in loc: a from date starts at its midnight and a to date runs
+// through the end of its day. Times without an offset are read in loc too.
+func (s *Server) queryTimeRange(c *gin.Context, loc *time.Location, fromName, toName string, from, to **time.Time) bool {
+	for _, bound := range []struct {
+		name  string
+		dst   **time.Time
+		upper bool
+	}{{fromName, from, false}, {toName, to, true}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		t, dateOnly, err := service.ParseLocalTime(value, loc)
+		if err != nil {
+			s.badRequest(c, "Invalid "+bound.name+" parameter")
+			return false
+		}
+		if dateOnly && bound.upper {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		t = t.UTC()
+		*bound.dst = &t
+	}
+	return true
+}
+
+// location returns the timezone of the actor's household, which dates and
+// times without an offset in requests are read in.
+func (s *Server) location(c *gin.Context, actor service.Actor) (*time.Location, bool) {
+	household, err := s.services.Household.GetSettings(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get household")
+		return nil, false
+	}
+	return household.Location(), true
+}
+
+// queryString returns an optional, trimmed query parameter.
+func queryString(c *gin.Context, name string) *string {
+	value := strings.TrimSpace(c.Query(name))
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// queryPage parses the limit and offset query parameters.
+func (s *Server) queryPage(c *gin.Context) (limit, offset int, ok bool) {
+	var l, o *int
+	if !s.queryInt(c, "limit", &l) || !s.queryInt(c, "offset", &o) {
+		return 0, 0, false
+	}
+	if l != nil {
+		limit = *l
+	}
+	if o != nil {
+		offset = *o
+	}
+	return limit, offset, true
+}
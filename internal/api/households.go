@@ -0,0 +1,41 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getHouseholdSettings(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	household, err := s.services.Household.GetSettings(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get household settings")
+		return
+	}
+
+	s.success(c, household)
+}
+
+func (s *Server) updateHouseholdSettings(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateHouseholdSettingsRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	household, err := s.services.Household.UpdateSettings(c.Request.Context(), actor, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to update household settings")
+		return
+	}
+
+	s.success(c, household)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+	"rsc.io/qr"
+)
+
+// qrQuietZone is the white border, in modules, that scanners need around a
+// QR code.
+const qrQuietZone = 4
+
+func (s *Server) getInvitations(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := s.services.Auth.GetInvitations(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get invitations")
+		return
+	}
+	s.success(c, invitations)
+}
+
+func (s *Server) createInvitation(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateInvitationRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	invitation, err := s.services.Auth.CreateInvitation(c.Request.Context(), actor, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to create invitation")
+		return
+	}
+	s.created(c, invitation)
+}
+
+func (s *Server) revokeInvitation(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := s.services.Auth.RevokeInvitation(c.Request.Context(), actor, id); err != nil {
+		s.serviceError(c, err, "Failed to revoke invitation")
+		return
+	}
+	s.success(c, gin.H{"revoked": true})
+}
+
+// getInvitationQR renders an invitation's link as a QR code, a PNG or, with
+// ?format=svg, an SVG.
+func (s *Server) getInvitationQR(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", "png")
+	if format != "png" && format != "svg" {
+		s.badRequest(c, "format must be png or svg")
+		return
+	}
+
+	invitation, err := s.services.Auth.GetInvitation(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get invitation")
+		return
+	}
+	code, err := qr.Encode(invitation.Link, qr.M)
+	if err != nil {
+		s.internalError(c, "Failed to generate QR code")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	if format == "svg" {
+		c.Data(http.StatusOK, "image/svg+xml", qrSVG(code))
+		return
+	}
+	code.Scale = 8
+	c.Data(http.StatusOK, "image/png", code.PNG())
+}
+
+// qrSVG draws a QR code as an SVG with one path for the dark modules, so it
+// scales to any size.
+func qrSVG(code *qr.Code) []byte {
+	size := code.Size + 2*qrQuietZone
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, size, size)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Black(x, y) {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+qrQuietZone, y+qrQuietZone)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
@@ -0,0 +1,14 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// getJobStatus reports when each background job last ran, how it went and
+// when it runs next.
+func (s *Server) getJobStatus(c *gin.Context) {
+	s.success(c, gin.H{
+		"enabled": s.config.Jobs.Enabled,
+		"jobs":    s.jobs.Statuses(),
+	})
+}
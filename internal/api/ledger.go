@@ -0,0 +1,123 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+func (s *Server) getLedger(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	loc, ok := s.location(c, actor)
+	if !ok {
+		return
+	}
+
+	var filters model.LedgerFilters
+	if ledgerType := queryString(c, "type"); ledgerType != nil {
+		t := model.LedgerType(*ledgerType)
+		filters.Type = &t
+	}
+	var userID *int
+	if !s.queryInt(c, "user_id", &userID) ||
+		!s.queryTimeRange(c, loc, "date_from", "date_to", &filters.DateFrom, &filters.DateTo) {
+		return
+	}
+	limit, offset, ok := s.queryPage(c)
+	if !ok {
+		return
+	}
+	filters.Limit, filters.Offset = limit, offset
+
+	var entries []*model.LedgerEntry
+	var err error
+	if userID != nil {
+		entries, err = s.services.Ledger.GetLedgerEntriesByUser(c.Request.Context(), actor, *userID, filters)
+	} else {
+		entries, err = s.services.Ledger.GetLedgerEntriesByHousehold(c.Request.Context(), actor, filters)
+	}
+	if err != nil {
+		s.serviceError(c, err, "Failed to get ledger entries")
+		return
+	}
+
+	s.success(c, entries)
+}
+
+func (s *Server) adjustLedger(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.LedgerAdjustmentRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+	amount, err := decimal.NewFromString(strings.TrimSpace(req.Amount))
+	if err != nil {
+		s.badRequest(c, "amount must be a decimal number")
+		return
+	}
+
+	entry, err := s.services.Ledger.AdjustBalance(c.Request.Context(), actor, req.UserID, amount, *req.Description)
+	if err != nil {
+		s.serviceError(c, err, "Failed to adjust balance")
+		return
+	}
+
+	s.created(c, entry)
+}
+
+func (s *Server) getBalance(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var userID *int
+	if !s.queryInt(c, "user_id", &userID) {
+		return
+	}
+	if userID == nil {
+		userID = &actor.UserID
+	}
+
+	summary, err := s.services.Ledger.GetBalanceSummary(c.Request.Context(), actor, *userID)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get balance")
+		return
+	}
+	household, err := s.services.Household.GetSettings(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get balance")
+		return
+	}
+
+	s.success(c, gin.H{
+		"user_id":   summary.UserID,
+		"balance":   household.FormatAmount(summary.Balance),
+		"held":      household.FormatAmount(summary.Held),
+		"available": household.FormatAmount(summary.Available),
+	})
+}
+
+func (s *Server) getBalances(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	balances, err := s.services.Ledger.GetAllUserBalances(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get balances")
+		return
+	}
+
+	s.success(c, balances)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getMembers(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	members, err := s.services.User.GetMembers(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get members")
+		return
+	}
+	s.success(c, members)
+}
+
+func (s *Server) getMember(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	member, err := s.services.User.GetMember(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get member")
+		return
+	}
+	s.success(c, member)
+}
+
+func (s *Server) updateMember(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateMemberRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	member, err := s.services.User.UpdateMember(c.Request.Context(), actor, id, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to update member")
+		return
+	}
+	s.success(c, member)
+}
+
+func (s *Server) deactivateMember(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	member, err := s.services.User.DeactivateMember(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to deactivate member")
+		return
+	}
+	s.success(c, member)
+}
+
+func (s *Server) reactivateMember(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	member, err := s.services.User.ReactivateMember(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to reactivate member")
+		return
+	}
+	s.success(c, member)
+}
+
+func (s *Server) removeMember(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := s.services.User.RemoveMember(c.Request.Context(), actor, id); err != nil {
+		s.serviceError(c, err, "Failed to remove member")
+		return
+	}
+	s.success(c, gin.H{"removed": true})
+}
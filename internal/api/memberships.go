@@ -0,0 +1,74 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// getMemberships lists the households the user belongs to.
+func (s *Server) getMemberships(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	memberships, err := s.services.Auth.GetMemberships(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get households")
+		return
+	}
+	s.success(c, memberships)
+}
+
+// acceptInvitation joins another household with the user's account.
+func (s *Server) acceptInvitation(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.AcceptInvitationRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	membership, err := s.services.Auth.AcceptInvitation(c.Request.Context(), actor, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to join household")
+		return
+	}
+	s.created(c, membership)
+}
+
+// switchHousehold makes another of the user's households the current one
+// and returns an access token for it. The user's refresh tokens land in the
+// current household, so they keep working and no new one is issued.
+func (s *Server) switchHousehold(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.SwitchHouseholdRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	user, err := s.services.Auth.SwitchHousehold(c.Request.Context(), actor, req.HouseholdID)
+	if err != nil {
+		s.serviceError(c, err, "Failed to switch household")
+		return
+	}
+	token, err := s.jwtManager.GenerateToken(user)
+	if err != nil {
+		s.internalError(c, "Failed to generate token")
+		return
+	}
+
+	user.PasswordHash = ""
+	s.success(c, model.AuthResponse{
+		Token:     token,
+		ExpiresIn: int(s.jwtManager.TokenTTL().Seconds()),
+		User:      *user,
+	})
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getOIDCProviders(c *gin.Context) {
+	s.success(c, s.services.Auth.OIDCProviders())
+}
+
+// authorizeOIDC starts a login with an OpenID Connect provider. The client
+// sends the user to the returned URL and posts the code and state it gets
+// back to oidcCallback.
+func (s *Server) authorizeOIDC(c *gin.Context) {
+	authorization, err := s.services.Auth.StartOIDCLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		s.serviceError(c, err, "Failed to start login")
+		return
+	}
+	s.success(c, authorization)
+}
+
+// oidcCallback finishes an OpenID Connect login. Like a password login it
+// answers with tokens or with a two-factor challenge.
+func (s *Server) oidcCallback(c *gin.Context) {
+	var req model.OIDCCallbackRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := s.services.Auth.CompleteOIDCLogin(c.Request.Context(), &req)
+	if s.accountLocked(c, err) {
+		return
+	}
+	if err != nil {
+		s.serviceError(c, err, "Failed to log in")
+		return
+	}
+	if result.Challenge != nil {
+		s.success(c, result.Challenge)
+		return
+	}
+
+	tokens, ok := s.issueTokens(c, result.User)
+	if !ok {
+		return
+	}
+	s.success(c, tokens)
+}
+
+// linkOIDC starts linking the current user's account at a provider;
+// linkIdentity finishes it with the code and state.
+func (s *Server) linkOIDC(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	authorization, err := s.services.Auth.StartIdentityLink(c.Request.Context(), actor, c.Param("provider"))
+	if err != nil {
+		s.serviceError(c, err, "Failed to start linking")
+		return
+	}
+	s.success(c, authorization)
+}
+
+func (s *Server) getIdentities(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	identities, err := s.services.Auth.GetIdentities(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get identities")
+		return
+	}
+	s.success(c, identities)
+}
+
+func (s *Server) linkIdentity(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.OIDCCallbackRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	identity, err := s.services.Auth.LinkIdentity(c.Request.Context(), actor, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to link identity")
+		return
+	}
+	s.created(c, identity)
+}
+
+func (s *Server) unlinkIdentity(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := s.services.Auth.UnlinkIdentity(c.Request.Context(), actor, id); err != nil {
+		s.serviceError(c, err, "Failed to unlink identity")
+		return
+	}
+	s.success(c, gin.H{"unlinked": true})
+}
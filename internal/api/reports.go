@@ -0,0 +1,55 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// reportFilters parses the range and grouping shared by reports.
+func (s *Server) reportFilters(c *gin.Context, actor service.Actor) (model.ReportFilters, bool) {
+	var filters model.ReportFilters
+	if groupBy := queryString(c, "group_by"); groupBy != nil {
+		filters.GroupBy = model.Period(*groupBy)
+	}
+	loc, ok := s.location(c, actor)
+	ok = ok && s.queryInt(c, "user_id", &filters.UserID) &&
+		s.queryTimeRange(c, loc, "date_from", "date_to", &filters.DateFrom, &filters.DateTo)
+	return filters, ok
+}
+
+func (s *Server) getChoreReport(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	filters, ok := s.reportFilters(c, actor)
+	if !ok {
+		return
+	}
+
+	report, err := s.services.Report.GetChoreReport(c.Request.Context(), actor, filters)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get chore report")
+		return
+	}
+	s.success(c, report)
+}
+
+func (s *Server) getEarningsReport(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	filters, ok := s.reportFilters(c, actor)
+	if !ok {
+		return
+	}
+
+	report, err := s.services.Report.GetEarningsReport(c.Request.Context(), actor, filters)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get earnings report")
+		return
+	}
+	s.success(c, report)
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getRewards(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var includeInactive *bool
+	if !s.queryBool(c, "include_inactive", &includeInactive) {
+		return
+	}
+
+	rewards, err := s.services.Reward.GetRewardsByHousehold(c.Request.Context(), actor, includeInactive != nil && *includeInactive)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get rewards")
+		return
+	}
+
+	s.success(c, rewards)
+}
+
+func (s *Server) createReward(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateRewardRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	reward, err := s.services.Reward.CreateReward(c.Request.Context(), actor, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to create reward")
+		return
+	}
+
+	s.created(c, reward)
+}
+
+func (s *Server) getReward(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	reward, err := s.services.Reward.GetRewardByID(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get reward")
+		return
+	}
+
+	s.success(c, reward)
+}
+
+func (s *Server) updateReward(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateRewardRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	reward, err := s.services.Reward.UpdateReward(c.Request.Context(), actor, id, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to update reward")
+		return
+	}
+
+	s.success(c, reward)
+}
+
+func (s *Server) deleteReward(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := s.services.Reward.DeleteReward(c.Request.Context(), actor, id); err != nil {
+		s.serviceError(c, err, "Failed to delete reward")
+		return
+	}
+
+	s.success(c, gin.H{"deleted": true})
+}
+
+func (s *Server) redeemReward(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	redemption, err := s.services.Reward.RedeemReward(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to redeem reward")
+		return
+	}
+
+	s.created(c, redemption)
+}
+
+func (s *Server) getRedemptions(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var filters model.RedemptionFilters
+	if status := queryString(c, "status"); status != nil {
+		st := model.RedemptionStatus(*status)
+		filters.Status = &st
+	}
+	if !s.queryInt(c, "reward_id", &filters.RewardID) {
+		return
+	}
+	limit, offset, ok := s.queryPage(c)
+	if !ok {
+		return
+	}
+	filters.Limit, filters.Offset = limit, offset
+
+	redemptions, err := s.services.Reward.GetRedemptions(c.Request.Context(), actor, filters)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get redemptions")
+		return
+	}
+
+	s.success(c, redemptions)
+}
+
+func (s *Server) approveRedemption(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	redemption, err := s.services.Reward.ApproveRedemption(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to approve redemption")
+		return
+	}
+
+	s.success(c, redemption)
+}
+
+func (s *Server) rejectRedemption(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	redemption, err := s.services.Reward.RejectRedemption(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to reject redemption")
+		return
+	}
+
+	s.success(c, redemption)
+}
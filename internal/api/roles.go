@@ -0,0 +1,53 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getRoles(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	matrix, err := s.services.Household.GetRoles(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get roles")
+		return
+	}
+	s.success(c, matrix)
+}
+
+func (s *Server) updateRole(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateRolePermissionsRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	role, err := s.services.Household.UpdateRole(c.Request.Context(), actor, model.Role(c.Param("role")), &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to update role")
+		return
+	}
+	s.success(c, role)
+}
+
+func (s *Server) resetRole(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	role, err := s.services.Household.ResetRole(c.Request.Context(), actor, model.Role(c.Param("role")))
+	if err != nil {
+		s.serviceError(c, err, "Failed to reset role")
+		return
+	}
+	s.success(c, role)
+}
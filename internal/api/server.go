@@ -1,11 +1,16 @@
 package api
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/choreme/choreme/internal/auth"
 	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/jobs"
 	"github.com/choreme/choreme/internal/middleware"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/ratelimit"
 	"github.com/choreme/choreme/internal/service"
 	"github.com/choreme/choreme/internal/store"
 	"github.com/choreme/choreme/internal/web"
@@ -17,26 +22,192 @@ type Server struct {
 	store      store.Store
 	jwtManager *auth.JWTManager
 	services   *service.Services
+	jobs       *jobs.Runner
+	limiter    ratelimit.Limiter
 	router     *gin.Engine
 }
 
-func NewServer(cfg *config.Config, store store.Store) *Server {
-	jwtManager := auth.NewJWTManager(cfg.JWT.Secret)
-	services := service.New(store)
+func NewServer(cfg *config.Config, store store.Store) (*Server, error) {
+	keys, err := auth.LoadKeySet(cfg.JWT, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	jwtManager := auth.NewJWTManager(keys, cfg.JWT.AccessTokenTTL)
+	services := service.New(store, cfg)
 
 	server := &Server{
 		config:     cfg,
 		store:      store,
 		jwtManager: jwtManager,
 		services:   services,
+		jobs:       jobs.NewRunner(),
+	}
+	if cfg.RateLimit.Enabled {
+		server.limiter = ratelimit.New(cfg.RateLimit, store)
 	}
 
+	server.setupJobs()
 	server.setupRoutes()
-	return server
+	server.announceSetup()
+	return server, nil
+}
+
+// announceSetup logs how to register the first account while the instance
+// has no system administrator yet.
+func (s *Server) announceSetup() {
+	status, err := s.services.Auth.SetupStatus(context.Background())
+	if err != nil {
+		log.Printf("Failed to check setup status: %v", err)
+		return
+	}
+	if !status.SetupRequired {
+		return
+	}
+	if s.config.Auth.SetupToken != "" {
+		log.Println("No system administrator yet: register the first account with AUTH_SETUP_TOKEN as setup_token")
+		return
+	}
+	log.Printf("No system administrator yet: register the first account with setup_token %s", s.services.Auth.SetupToken())
+}
+
+func (s *Server) setupJobs() {
+	s.jobs.Add(jobs.Job{
+		Name:     "schedule",
+		Interval: s.config.Jobs.ScheduleInterval,
+		Run: func(ctx context.Context, now time.Time) (interface{}, error) {
+			return s.services.Schedule.GenerateAssignments(ctx, now)
+		},
+	})
+	s.jobs.Add(jobs.Job{
+		Name:     "sweep",
+		Interval: s.config.Jobs.SweepInterval,
+		Run: func(ctx context.Context, now time.Time) (interface{}, error) {
+			return s.services.Assignment.SweepOverdue(ctx, now)
+		},
+	})
+	s.jobs.Add(jobs.Job{
+		Name:     "token_cleanup",
+		Interval: s.config.Jobs.TokenCleanupInterval,
+		Run: func(ctx context.Context, now time.Time) (interface{}, error) {
+			return s.services.Auth.CleanupTokens(ctx, now)
+		},
+	})
+	if s.limiter != nil {
+		s.jobs.Add(jobs.Job{
+			Name:     "rate_limit_cleanup",
+			Interval: s.config.Jobs.RateLimitCleanupInterval,
+			Run: func(ctx context.Context, now time.Time) (interface{}, error) {
+				removed, err := s.limiter.Cleanup(ctx, now.Add(-s.config.RateLimit.MaxPeriod()))
+				return gin.H{"buckets_removed": removed}, err
+			},
+		})
+	}
+}
+
+// rateLimit throttles a route per client IP, or does nothing when rate
+// limiting is disabled.
+func (s *Server) rateLimit(name string, rate config.Rate) gin.HandlerFunc {
+	if s.limiter == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.RateLimit(s.limiter, name, rate)
+}
+
+// StartJobs starts the background jobs; they stop when ctx is cancelled.
+func (s *Server) StartJobs(ctx context.Context) {
+	s.jobs.Start(ctx)
+}
+
+// pinRoutes are the routes a PIN login's limited token may use: a worker's
+// day-to-day chores, rewards and points.
+var pinRoutes = []string{
+	"GET /api/v1/users/me",
+	"GET /api/v1/households/settings",
+	"GET /api/v1/chores",
+	"GET /api/v1/chores/:id",
+	"GET /api/v1/assignments",
+	"GET /api/v1/assignments/:id",
+	"PATCH /api/v1/assignments/:id/progress",
+	"PATCH /api/v1/assignments/:id/complete",
+	"GET /api/v1/assignments/:id/proof",
+	"POST /api/v1/upload/photo",
+	"GET /api/v1/rewards",
+	"GET /api/v1/rewards/:id",
+	"POST /api/v1/rewards/:id/redeem",
+	"GET /api/v1/redemptions",
+	"GET /api/v1/ledger",
+	"GET /api/v1/ledger/balance",
+}
+
+// apiTokenRoutes are the routes personal access tokens may use, with the
+// scope each needs. Account and security settings, such as the tokens
+// themselves, stay out of reach. Listing invitations needs households:write
+// too, since their codes let people join.
+var apiTokenRoutes = map[string]string{
+	"GET /api/v1/users/me":                           "users:read",
+	"GET /api/v1/users":                              "users:read",
+	"GET /api/v1/households/settings":                "households:read",
+	"PATCH /api/v1/households/settings":              "households:write",
+	"POST /api/v1/households/invite":                 "households:write",
+	"GET /api/v1/households/invitations":             "households:write",
+	"POST /api/v1/households/invitations":            "households:write",
+	"DELETE /api/v1/households/invitations/:id":      "households:write",
+	"GET /api/v1/households/invitations/:id/qr":      "households:write",
+	"GET /api/v1/households/roles":                   "households:read",
+	"GET /api/v1/households/members":                 "households:read",
+	"GET /api/v1/households/members/:id":             "households:read",
+	"PATCH /api/v1/households/members/:id":           "households:write",
+	"POST /api/v1/households/members/:id/deactivate": "households:write",
+	"POST /api/v1/households/members/:id/reactivate": "households:write",
+	"DELETE /api/v1/households/members/:id":          "households:write",
+	"GET /api/v1/chores":                             "chores:read",
+	"GET /api/v1/chores/:id":                         "chores:read",
+	"POST /api/v1/chores":                            "chores:write",
+	"PUT /api/v1/chores/:id":                         "chores:write",
+	"DELETE /api/v1/chores/:id":                      "chores:write",
+	"GET /api/v1/assignments":                        "assignments:read",
+	"GET /api/v1/assignments/:id":                    "assignments:read",
+	"PATCH /api/v1/assignments/:id/progress":         "assignments:write",
+	"PATCH /api/v1/assignments/:id/complete":         "assignments:write",
+	"PATCH /api/v1/assignments/:id/approve":          "assignments:write",
+	"PATCH /api/v1/assignments/:id/reject":           "assignments:write",
+	"GET /api/v1/assignments/:id/proof":              "assignments:read",
+	"POST /api/v1/upload/photo":                      "assignments:write",
+	"GET /api/v1/rewards":                            "rewards:read",
+	"GET /api/v1/rewards/:id":                        "rewards:read",
+	"POST /api/v1/rewards":                           "rewards:write",
+	"PUT /api/v1/rewards/:id":                        "rewards:write",
+	"DELETE /api/v1/rewards/:id":                     "rewards:write",
+	"POST /api/v1/rewards/:id/redeem":                "rewards:write",
+	"GET /api/v1/redemptions":                        "redemptions:read",
+	"PATCH /api/v1/redemptions/:id/approve":          "redemptions:write",
+	"PATCH /api/v1/redemptions/:id/reject":           "redemptions:write",
+	"GET /api/v1/ledger":                             "ledger:read",
+	"GET /api/v1/ledger/balance":                     "ledger:read",
+	"GET /api/v1/ledger/balances":                    "ledger:read",
+	"POST /api/v1/ledger/adjust":                     "ledger:write",
+	"GET /api/v1/audit":                              "audit:read",
+	"GET /api/v1/reports/chores":                     "reports:read",
+	"GET /api/v1/reports/earnings":                   "reports:read",
+}
+
+// impersonationRoutes are the routes an impersonation token may use: those
+// open to personal access tokens, which leave the user's account and
+// security settings alone.
+func impersonationRoutes() []string {
+	routes := make([]string, 0, len(apiTokenRoutes))
+	for route := range apiTokenRoutes {
+		routes = append(routes, route)
+	}
+	return routes
 }
 
 func (s *Server) setupRoutes() {
 	s.router = gin.Default()
+	if err := s.router.SetTrustedProxies(s.config.Server.TrustedProxies); err != nil {
+		log.Printf("Invalid TRUSTED_PROXIES, trusting none: %v", err)
+		s.router.SetTrustedProxies(nil)
+	}
 
 	// Global middleware
 	s.router.Use(middleware.CORSMiddleware())
@@ -49,29 +220,111 @@ func (s *Server) setupRoutes() {
 	// Health check
 	s.router.GET("/health", s.healthCheck)
 
+	// Public keys for services that verify access tokens
+	s.router.GET("/.well-known/jwks.json", s.jwks)
+
+	// PIN logins only get the routes in pinRoutes
+	pinScope := middleware.RestrictScope(auth.ScopePIN, pinRoutes...)
+	impersonationScope := middleware.RestrictScope(auth.ScopeImpersonation, impersonationRoutes()...)
+
 	// API v1 routes
 	v1 := s.router.Group("/api/v1")
 	{
 		// Public routes (no authentication required)
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", s.register)
-			auth.POST("/login", s.login)
+			auth.GET("/setup", s.getSetupStatus)
+			auth.POST("/register", s.rateLimit("register", s.config.RateLimit.RegisterIP), s.register)
+			auth.POST("/login", s.rateLimit("login", s.config.RateLimit.LoginIP), s.login)
+			auth.POST("/login/2fa", s.rateLimit("login", s.config.RateLimit.LoginIP), s.loginTwoFactor)
+			auth.POST("/login/2fa/setup", s.rateLimit("login", s.config.RateLimit.LoginIP), s.loginTwoFactorSetup)
+			auth.POST("/refresh", s.refresh)
+			auth.POST("/logout", s.logout)
+			auth.POST("/forgot-password", s.rateLimit("forgot_password", s.config.RateLimit.PasswordReset), s.forgotPassword)
+			auth.POST("/reset-password", s.resetPassword)
+			auth.POST("/verify-email", s.verifyEmail)
+
+			// OpenID Connect login
+			auth.GET("/oidc/providers", s.getOIDCProviders)
+			auth.POST("/oidc/:provider/authorize", s.rateLimit("login", s.config.RateLimit.LoginIP), s.authorizeOIDC)
+			auth.POST("/oidc/callback", s.rateLimit("login", s.config.RateLimit.LoginIP), s.oidcCallback)
 		}
 
 		households := v1.Group("/households")
 		{
-			households.POST("/join", s.joinHousehold)
+			// Throttled so invite codes can't be guessed
+			households.POST("/join", s.rateLimit("join", s.config.RateLimit.JoinIP), s.joinHousehold)
+		}
+
+		// Household devices authenticate with their device token
+		device := v1.Group("/device")
+		device.Use(middleware.DeviceMiddleware(s.services.Household))
+		{
+			device.GET("/members", s.getDeviceMembers)
+			device.POST("/login", s.rateLimit("pin_login", s.config.RateLimit.PINLogin), s.pinLogin)
 		}
 
 		// Protected routes (authentication required)
 		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware(s.jwtManager))
+		protected.Use(middleware.AuthMiddleware(s.jwtManager, s.services.Auth, s.services.Auth))
+		protected.Use(pinScope)
+		protected.Use(impersonationScope, s.flagImpersonation)
+		protected.Use(middleware.PermissionMiddleware(s.services.Household))
+		protected.Use(middleware.RequireAPITokenScopes(apiTokenRoutes))
 		{
+			protected.POST("/auth/logout-all", s.logoutAll)
+			protected.POST("/auth/resend-verification", s.resendVerification)
+			protected.POST("/auth/oidc/:provider/link", s.linkOIDC)
+			protected.POST("/auth/switch-household", s.switchHousehold)
+
+			// Two-factor authentication
+			twoFactorRoutes := protected.Group("/auth/2fa")
+			{
+				twoFactorRoutes.GET("", s.getTwoFactorStatus)
+				twoFactorRoutes.POST("/setup", s.setupTwoFactor)
+				twoFactorRoutes.POST("/enable", s.enableTwoFactor)
+				twoFactorRoutes.POST("/disable", s.disableTwoFactor)
+				twoFactorRoutes.POST("/recovery-codes", s.regenerateRecoveryCodes)
+			}
+
 			// Household management
 			householdRoutes := protected.Group("/households")
 			{
-				householdRoutes.POST("/invite", middleware.RequireAdminOrManager(), s.generateInvite)
+				householdRoutes.POST("/invite", middleware.RequirePermission(model.PermHouseholdInvite), s.generateInvite)
+				householdRoutes.GET("/invitations", middleware.RequirePermission(model.PermHouseholdInvite), s.getInvitations)
+				householdRoutes.POST("/invitations", middleware.RequirePermission(model.PermHouseholdInvite), s.createInvitation)
+				householdRoutes.DELETE("/invitations/:id", middleware.RequirePermission(model.PermHouseholdInvite), s.revokeInvitation)
+				householdRoutes.GET("/invitations/:id/qr", middleware.RequirePermission(model.PermHouseholdInvite), s.getInvitationQR)
+				householdRoutes.GET("/settings", s.getHouseholdSettings)
+				householdRoutes.PATCH("/settings", middleware.RequirePermission(model.PermHouseholdSettings), s.updateHouseholdSettings)
+				householdRoutes.GET("/devices", middleware.RequirePermission(model.PermDevicesManage), s.getDevices)
+				householdRoutes.POST("/devices", middleware.RequirePermission(model.PermDevicesManage), s.registerDevice)
+				householdRoutes.DELETE("/devices/:id", middleware.RequirePermission(model.PermDevicesManage), s.revokeDevice)
+				householdRoutes.GET("/roles", s.getRoles)
+				householdRoutes.PUT("/roles/:role", middleware.RequirePermission(model.PermRolesManage), s.updateRole)
+				householdRoutes.DELETE("/roles/:role", middleware.RequirePermission(model.PermRolesManage), s.resetRole)
+				householdRoutes.GET("/members", middleware.RequirePermission(model.PermMembersView), s.getMembers)
+				householdRoutes.GET("/members/:id", middleware.RequirePermission(model.PermMembersView), s.getMember)
+				householdRoutes.PATCH("/members/:id", middleware.RequirePermission(model.PermMembersManage), s.updateMember)
+				householdRoutes.POST("/members/:id/deactivate", middleware.RequirePermission(model.PermMembersManage), s.deactivateMember)
+				householdRoutes.POST("/members/:id/reactivate", middleware.RequirePermission(model.PermMembersManage), s.reactivateMember)
+				householdRoutes.DELETE("/members/:id", middleware.RequirePermission(model.PermMembersManage), s.removeMember)
+			}
+
+			// Instance administration
+			adminRoutes := protected.Group("/admin")
+			adminRoutes.Use(middleware.RequireSystemAdmin())
+			{
+				adminRoutes.GET("/households", s.getAllHouseholds)
+				adminRoutes.POST("/households", s.createHousehold)
+				adminRoutes.GET("/households/:id/members", s.getHouseholdMembers)
+				adminRoutes.POST("/households/:id/invitations", s.createAdminInvitation)
+				adminRoutes.POST("/households/:id/suspend", s.suspendHousehold)
+				adminRoutes.POST("/households/:id/unsuspend", s.unsuspendHousehold)
+				adminRoutes.POST("/users/:id/impersonate", s.impersonateUser)
+				adminRoutes.POST("/users/:id/reset-password", s.adminResetPassword)
+				adminRoutes.GET("/audit", s.getInstanceAuditLogs)
+				adminRoutes.GET("/jobs/status", s.getJobStatus)
 			}
 
 			// User management
@@ -79,17 +332,28 @@ func (s *Server) setupRoutes() {
 			{
 				userRoutes.GET("/me", s.getCurrentUser)
 				userRoutes.PUT("/me", s.updateCurrentUser)
-				userRoutes.GET("", middleware.RequireAdminOrManager(), s.getUsers)
+				userRoutes.GET("/me/identities", s.getIdentities)
+				userRoutes.POST("/me/identities", s.linkIdentity)
+				userRoutes.DELETE("/me/identities/:id", s.unlinkIdentity)
+				userRoutes.GET("/me/tokens", s.getAPITokens)
+				userRoutes.POST("/me/tokens", s.createAPIToken)
+				userRoutes.DELETE("/me/tokens/:id", s.deleteAPIToken)
+				userRoutes.GET("/me/households", s.getMemberships)
+				userRoutes.POST("/me/households", s.acceptInvitation)
+				userRoutes.GET("", middleware.RequirePermission(model.PermMembersView), s.getUsers)
+				userRoutes.POST("/profiles", middleware.RequirePermission(model.PermMembersManage), s.createProfile)
+				userRoutes.PUT("/:id/pin", middleware.RequirePermission(model.PermMembersManage), s.setUserPIN)
+				userRoutes.DELETE("/:id/pin", middleware.RequirePermission(model.PermMembersManage), s.removeUserPIN)
 			}
 
 			// Chore management
 			choreRoutes := protected.Group("/chores")
 			{
 				choreRoutes.GET("", s.getChores)
-				choreRoutes.POST("", middleware.RequireAdminOrManager(), s.createChore)
+				choreRoutes.POST("", middleware.RequirePermission(model.PermChoresCreate), s.createChore)
 				choreRoutes.GET("/:id", s.getChore)
-				choreRoutes.PUT("/:id", middleware.RequireAdminOrManager(), s.updateChore)
-				choreRoutes.DELETE("/:id", middleware.RequireAdminOrManager(), s.deleteChore)
+				choreRoutes.PUT("/:id", middleware.RequirePermission(model.PermChoresUpdate), s.updateChore)
+				choreRoutes.DELETE("/:id", middleware.RequirePermission(model.PermChoresDelete), s.deleteChore)
 			}
 
 			// Assignment management
@@ -99,18 +363,25 @@ func (s *Server) setupRoutes() {
 				assignmentRoutes.GET("/:id", s.getAssignment)
 				assignmentRoutes.PATCH("/:id/progress", s.updateProgress)
 				assignmentRoutes.PATCH("/:id/complete", s.completeChore)
-				assignmentRoutes.PATCH("/:id/approve", middleware.RequireAdminOrManager(), s.approveChore)
-				assignmentRoutes.PATCH("/:id/reject", middleware.RequireAdminOrManager(), s.rejectChore)
+				assignmentRoutes.PATCH("/:id/approve", middleware.RequirePermission(model.PermAssignmentsApprove), s.approveChore)
+				assignmentRoutes.PATCH("/:id/reject", middleware.RequirePermission(model.PermAssignmentsApprove), s.rejectChore)
+				assignmentRoutes.GET("/:id/proof", s.getProofImage)
+			}
+
+			// Proof photo uploads
+			uploadRoutes := protected.Group("/upload")
+			{
+				uploadRoutes.POST("/photo", s.uploadPhoto)
 			}
 
 			// Reward management
 			rewardRoutes := protected.Group("/rewards")
 			{
 				rewardRoutes.GET("", s.getRewards)
-				rewardRoutes.POST("", middleware.RequireAdminOrManager(), s.createReward)
+				rewardRoutes.POST("", middleware.RequirePermission(model.PermRewardsCreate), s.createReward)
 				rewardRoutes.GET("/:id", s.getReward)
-				rewardRoutes.PUT("/:id", middleware.RequireAdminOrManager(), s.updateReward)
-				rewardRoutes.DELETE("/:id", middleware.RequireAdminOrManager(), s.deleteReward)
+				rewardRoutes.PUT("/:id", middleware.RequirePermission(model.PermRewardsUpdate), s.updateReward)
+				rewardRoutes.DELETE("/:id", middleware.RequirePermission(model.PermRewardsDelete), s.deleteReward)
 				rewardRoutes.POST("/:id/redeem", s.redeemReward)
 			}
 
@@ -118,29 +389,30 @@ func (s *Server) setupRoutes() {
 			redemptionRoutes := protected.Group("/redemptions")
 			{
 				redemptionRoutes.GET("", s.getRedemptions)
-				redemptionRoutes.PATCH("/:id/approve", middleware.RequireAdminOrManager(), s.approveRedemption)
-				redemptionRoutes.PATCH("/:id/reject", middleware.RequireAdminOrManager(), s.rejectRedemption)
+				redemptionRoutes.PATCH("/:id/approve", middleware.RequirePermission(model.PermRedemptionsApprove), s.approveRedemption)
+				redemptionRoutes.PATCH("/:id/reject", middleware.RequirePermission(model.PermRedemptionsApprove), s.rejectRedemption)
 			}
 
 			// Ledger management
 			ledgerRoutes := protected.Group("/ledger")
 			{
 				ledgerRoutes.GET("", s.getLedger)
-				ledgerRoutes.POST("/adjust", middleware.RequireAdminOrManager(), s.adjustLedger)
+				ledgerRoutes.POST("/adjust", middleware.RequirePermission(model.PermLedgerAdjust), s.adjustLedger)
 				ledgerRoutes.GET("/balance", s.getBalance)
+				ledgerRoutes.GET("/balances", s.getBalances)
 			}
 
 			// Audit logs
 			auditRoutes := protected.Group("/audit")
 			{
-				auditRoutes.GET("", middleware.RequireAdminOrManager(), s.getAuditLogs)
+				auditRoutes.GET("", middleware.RequirePermission(model.PermAuditRead), s.getAuditLogs)
 			}
 
 			// Reports
 			reportRoutes := protected.Group("/reports")
 			{
-				reportRoutes.GET("/chores", middleware.RequireAdminOrManager(), s.getChoreReport)
-				reportRoutes.GET("/earnings", middleware.RequireAdminOrManager(), s.getEarningsReport)
+				reportRoutes.GET("/chores", middleware.RequirePermission(model.PermReportsRead), s.getChoreReport)
+				reportRoutes.GET("/earnings", middleware.RequirePermission(model.PermReportsRead), s.getEarningsReport)
 			}
 		}
 	}
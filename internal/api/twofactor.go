@@ -0,0 +1,133 @@
+package api
+
+import (
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// loginTwoFactor finishes a login that answered with a challenge, given a
+// TOTP or recovery code. Users who enrolled during the login get their
+// recovery codes with the tokens.
+func (s *Server) loginTwoFactor(c *gin.Context) {
+	var req model.TwoFactorLoginRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	user, recoveryCodes, err := s.services.Auth.CompleteTwoFactorLogin(c.Request.Context(), req.ChallengeToken, req.Code)
+	if s.accountLocked(c, err) {
+		return
+	}
+	if err != nil {
+		s.serviceError(c, err, "Failed to log in")
+		return
+	}
+
+	tokens, ok := s.issueTokens(c, user)
+	if !ok {
+		return
+	}
+	tokens.RecoveryCodes = recoveryCodes
+	s.success(c, tokens)
+}
+
+// loginTwoFactorSetup starts enrollment for a login whose challenge says
+// two-factor authentication has to be set up first.
+func (s *Server) loginTwoFactorSetup(c *gin.Context) {
+	var req model.TwoFactorChallengeRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	setup, err := s.services.Auth.SetupTwoFactorForChallenge(c.Request.Context(), req.ChallengeToken)
+	if err != nil {
+		s.serviceError(c, err, "Failed to set up two-factor authentication")
+		return
+	}
+	s.success(c, setup)
+}
+
+func (s *Server) getTwoFactorStatus(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	status, err := s.services.Auth.GetTwoFactorStatus(c.Request.Context(), actor)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get two-factor status")
+		return
+	}
+	s.success(c, status)
+}
+
+func (s *Server) setupTwoFactor(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	var req model.TwoFactorSetupRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	setup, err := s.services.Auth.SetupTwoFactor(c.Request.Context(), actor, req.Password)
+	if err != nil {
+		s.serviceError(c, err, "Failed to set up two-factor authentication")
+		return
+	}
+	s.success(c, setup)
+}
+
+func (s *Server) enableTwoFactor(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	var req model.TwoFactorCodeRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	recoveryCodes, err := s.services.Auth.EnableTwoFactor(c.Request.Context(), actor, req.Code)
+	if err != nil {
+		s.serviceError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+	s.success(c, gin.H{"recovery_codes": recoveryCodes})
+}
+
+func (s *Server) disableTwoFactor(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	var req model.TwoFactorDisableRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	if err := s.services.Auth.DisableTwoFactor(c.Request.Context(), actor, req.Password, req.Code); err != nil {
+		s.serviceError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+	s.success(c, gin.H{"enabled": false})
+}
+
+func (s *Server) regenerateRecoveryCodes(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	var req model.TwoFactorCodeRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	recoveryCodes, err := s.services.Auth.RegenerateRecoveryCodes(c.Request.Context(), actor, req.Code)
+	if err != nil {
+		s.serviceError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+	s.success(c, gin.H{"recovery_codes": recoveryCodes})
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is the room a photo upload's form gets on top of the
+// image size limit for its boundaries, headers and other fields.
+const multipartOverhead = 64 << 10
+
+// uploadPhoto attaches a proof photo to an assignment before it is
+// completed. It takes a multipart form with the image as photo and the
+// assignment as assignment_id, and returns where the processed photo can be
+// fetched.
+func (s *Server) uploadPhoto(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	maxBytes := int64(s.config.Image.MaxSizeMB) << 20
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+multipartOverhead)
+	form, err := c.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			s.error(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("Photo must be at most %d MB", s.config.Image.MaxSizeMB))
+			return
+		}
+		s.badRequest(c, "Expected a multipart form with a photo")
+		return
+	}
+
+	assignmentID, err := strconv.Atoi(c.PostForm("assignment_id"))
+	if err != nil {
+		s.badRequest(c, "Invalid assignment_id")
+		return
+	}
+	files := form.File["photo"]
+	if len(files) != 1 {
+		s.badRequest(c, "Expected one photo")
+		return
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		s.badRequest(c, "Failed to read photo")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		s.badRequest(c, "Failed to read photo")
+		return
+	}
+
+	proof, err := s.services.Assignment.AttachProofImage(c.Request.Context(), actor, assignmentID, data)
+	if err != nil {
+		s.serviceError(c, err, "Failed to attach photo")
+		return
+	}
+	proof.URL = fmt.Sprintf("/api/v1/assignments/%d/proof", assignmentID)
+	s.created(c, proof)
+}
+
+// getProofImage serves an assignment's proof photo.
+func (s *Server) getProofImage(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	data, contentType, err := s.services.Assignment.GetProofImage(c.Request.Context(), actor, id)
+	if err != nil {
+		s.serviceError(c, err, "Failed to get proof image")
+		return
+	}
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, contentType, data)
+}
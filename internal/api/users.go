@@ -1,6 +1,9 @@
 package api
 
 import (
+	"strings"
+
+	"github.com/choreme/choreme/internal/model"
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,8 +12,12 @@ func (s *Server) getCurrentUser(c *gin.Context) {
 	if !ok {
 		return
 	}
+	householdID, ok := s.getHouseholdID(c)
+	if !ok {
+		return
+	}
 
-	user, err := s.services.User.GetUserByID(c.Request.Context(), userID)
+	user, err := s.services.User.GetUserInHousehold(c.Request.Context(), householdID, userID)
 	if err != nil {
 		s.notFound(c, "User not found")
 		return
@@ -26,6 +33,10 @@ func (s *Server) updateCurrentUser(c *gin.Context) {
 	if !ok {
 		return
 	}
+	householdID, ok := s.getHouseholdID(c)
+	if !ok {
+		return
+	}
 
 	var req struct {
 		Name                  string `json:"name" binding:"required"`
@@ -39,15 +50,19 @@ func (s *Server) updateCurrentUser(c *gin.Context) {
 	}
 
 	// Get current user
-	user, err := s.services.User.GetUserByID(c.Request.Context(), userID)
+	user, err := s.services.User.GetUserInHousehold(c.Request.Context(), householdID, userID)
 	if err != nil {
 		s.notFound(c, "User not found")
 		return
 	}
 
-	// Update fields
+	// Update fields; a new email address has to be verified again
+	emailChanged := !strings.EqualFold(user.Email, req.Email)
 	user.Name = req.Name
 	user.Email = req.Email
+	if emailChanged {
+		user.EmailVerifiedAt = nil
+	}
 	user.NotificationPrefEmail = req.NotificationPrefEmail
 	user.NotificationPrefPush = req.NotificationPrefPush
 
@@ -55,6 +70,9 @@ func (s *Server) updateCurrentUser(c *gin.Context) {
 		s.internalError(c, "Failed to update user")
 		return
 	}
+	if emailChanged {
+		s.services.Auth.SendEmailVerification(c.Request.Context(), user)
+	}
 
 	// Don't return password hash
 	user.PasswordHash = ""
@@ -79,4 +97,63 @@ func (s *Server) getUsers(c *gin.Context) {
 	}
 
 	s.success(c, users)
-}
\ No newline at end of file
+}
+// createProfile adds an email-less profile that logs in with a PIN on
+// household devices.
+func (s *Server) createProfile(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateProfileRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	user, err := s.services.User.CreateProfile(c.Request.Context(), actor, &req)
+	if err != nil {
+		s.serviceError(c, err, "Failed to create profile")
+		return
+	}
+	s.created(c, user)
+}
+
+func (s *Server) setUserPIN(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.SetPINRequest
+	if !s.bindJSON(c, &req) {
+		return
+	}
+
+	if err := s.services.User.SetPIN(c.Request.Context(), actor, id, req.PIN); err != nil {
+		s.serviceError(c, err, "Failed to set PIN")
+		return
+	}
+	s.success(c, gin.H{"pin_set": true})
+}
+
+func (s *Server) removeUserPIN(c *gin.Context) {
+	actor, ok := s.getActor(c)
+	if !ok {
+		return
+	}
+	id, ok := s.getIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := s.services.User.RemovePIN(c.Request.Context(), actor, id); err != nil {
+		s.serviceError(c, err, "Failed to remove PIN")
+		return
+	}
+	s.success(c, gin.H{"pin_removed": true})
+}
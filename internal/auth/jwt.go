@@ -9,41 +9,98 @@ import (
 )
 
 type Claims struct {
-	UserID      int         `json:"user_id"`
-	HouseholdID int         `json:"household_id"`
-	Role        model.Role  `json:"role"`
-	Email       string      `json:"email"`
+	UserID      int        `json:"user_id"`
+	HouseholdID int        `json:"household_id"`
+	Role        model.Role `json:"role"`
+	Email       string     `json:"email"`
+	// SessionEpoch is the user's session epoch when the token was issued.
+	SessionEpoch int `json:"epoch"`
+	// Scope limits what the token may be used for; empty means full access.
+	Scope string `json:"scope,omitempty"`
+	// Scopes are the scopes of a personal access token. They are never
+	// part of a JWT.
+	Scopes []string `json:"-"`
+	// ImpersonatorID is the system administrator acting as the user with an
+	// impersonation token.
+	ImpersonatorID int `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ScopePIN marks tokens from a PIN login on a household device, which only
+// reach the routes a worker needs day to day.
+const ScopePIN = "pin"
+
+// ScopeImpersonation marks tokens a system administrator acts as another
+// user with, which stay away from the user's account and security settings.
+const ScopeImpersonation = "impersonation"
+
+// signingMethods are the algorithms access tokens may be signed with.
+var signingMethods = []string{"HS256", "EdDSA", "RS256"}
+
 type JWTManager struct {
-	secretKey string
-	issuer    string
+	keys     *KeySet
+	issuer   string
+	tokenTTL time.Duration
 }
 
-func NewJWTManager(secretKey string) *JWTManager {
+// NewJWTManager signs access tokens with the current key of keys that expire
+// after tokenTTL. Access tokens can't be revoked, so keep it short and renew
+// them with refresh tokens.
+func NewJWTManager(keys *KeySet, tokenTTL time.Duration) *JWTManager {
 	return &JWTManager{
-		secretKey: secretKey,
-		issuer:    "choreme",
+		keys:     keys,
+		issuer:   "choreme",
+		tokenTTL: tokenTTL,
 	}
 }
 
+// JWKS returns the public keys that verify access tokens.
+func (j *JWTManager) JWKS() JWKS {
+	return j.keys.JWKS(time.Now())
+}
+
+// TokenTTL returns the lifetime of the access tokens it signs.
+func (j *JWTManager) TokenTTL() time.Duration {
+	return j.tokenTTL
+}
+
 func (j *JWTManager) GenerateToken(user *model.User) (string, error) {
+	return j.GenerateScopedToken(user, "", j.tokenTTL)
+}
+
+// GenerateScopedToken signs an access token limited to scope that expires
+// after ttl.
+func (j *JWTManager) GenerateScopedToken(user *model.User, scope string, ttl time.Duration) (string, error) {
+	return j.generate(user, scope, 0, ttl)
+}
+
+// GenerateImpersonationToken signs an access token for a system
+// administrator to act as user, flagged with their ID.
+func (j *JWTManager) GenerateImpersonationToken(user *model.User, impersonatorID int) (string, error) {
+	return j.generate(user, ScopeImpersonation, impersonatorID, j.tokenTTL)
+}
+
+func (j *JWTManager) generate(user *model.User, scope string, impersonatorID int, ttl time.Duration) (string, error) {
 	claims := &Claims{
-		UserID:      user.ID,
-		HouseholdID: user.HouseholdID,
-		Role:        user.Role,
-		Email:       user.Email,
+		UserID:         user.ID,
+		HouseholdID:    user.HouseholdID,
+		Role:           user.Role,
+		Email:          user.Email,
+		SessionEpoch:   user.SessionEpoch,
+		Scope:          scope,
+		ImpersonatorID: impersonatorID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    j.issuer,
 			Subject:   fmt.Sprintf("%d", user.ID),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	key := j.keys.Current()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signKey)
 }
 
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
@@ -51,11 +108,11 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 		tokenString,
 		&Claims{},
 		func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(j.secretKey), nil
+			kid, _ := token.Header["kid"].(string)
+			return j.keys.verifyKey(kid, token.Method.Alg(), time.Now())
 		},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(j.issuer),
 	)
 
 	if err != nil {
@@ -69,22 +126,3 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
-
-func (j *JWTManager) RefreshToken(tokenString string) (string, error) {
-	claims, err := j.ValidateToken(tokenString)
-	if err != nil {
-		return "", err
-	}
-
-	// Check if token is close to expiry (within 1 hour)
-	if time.Until(claims.ExpiresAt.Time) > time.Hour {
-		return "", fmt.Errorf("token doesn't need refresh yet")
-	}
-
-	// Create new token with updated expiry
-	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(24 * time.Hour))
-	claims.IssuedAt = jwt.NewNumericDate(time.Now())
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
-}
\ No newline at end of file
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/choreme/choreme/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA key accepted for signing.
+const minRSABits = 2048
+
+// Key is a key that signs or verifies access tokens. Asymmetric keys are
+// published in the JWKS document so other services can verify tokens.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	// RetiresAt is when a previous key stops verifying tokens; zero for
+	// the current key.
+	RetiresAt time.Time
+}
+
+// KeySet holds the key that signs new access tokens and the previous keys
+// that still verify older ones.
+type KeySet struct {
+	// keys starts with the current key, followed by previous keys in the
+	// order they were configured.
+	keys []*Key
+}
+
+// LoadKeySet builds the key set the configuration asks for. New tokens are
+// signed with the private key in KeyFile, using EdDSA or RS256 by its type,
+// or with HS256 and Secret when no key file is set. Previous keys verify
+// tokens until the grace period after now has passed, which covers tokens
+// signed just before a rotation.
+func LoadKeySet(cfg config.JWTConfig, now time.Time) (*KeySet, error) {
+	current := hmacKey(cfg.Secret)
+	if cfg.KeyFile != "" {
+		var err error
+		current, err = loadKeyFile(cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	set := &KeySet{keys: []*Key{current}}
+
+	retiresAt := now.Add(cfg.KeyGracePeriod)
+	for _, file := range cfg.PreviousKeyFiles {
+		key, err := loadKeyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		set.addPrevious(key, retiresAt)
+	}
+	for _, secret := range cfg.PreviousSecrets {
+		set.addPrevious(hmacKey(secret), retiresAt)
+	}
+	return set, nil
+}
+
+func (s *KeySet) addPrevious(key *Key, retiresAt time.Time) {
+	for _, k := range s.keys {
+		if k.ID == key.ID {
+			return
+		}
+	}
+	key.RetiresAt = retiresAt
+	s.keys = append(s.keys, key)
+}
+
+// Current returns the key that signs new tokens.
+func (s *KeySet) Current() *Key {
+	return s.keys[0]
+}
+
+// verifyKey returns the key that verifies a token with the given key ID and
+// algorithm. Tokens signed before key IDs were used have none; they verify
+// with the first key of their algorithm, which is the secret they were
+// signed with unless several secrets were rotated out at once.
+func (s *KeySet) verifyKey(kid, alg string, now time.Time) (interface{}, error) {
+	var key *Key
+	for _, k := range s.keys {
+		if k.ID == kid || (kid == "" && k.Method.Alg() == alg) {
+			key = k
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if key.Method.Alg() != alg {
+		return nil, fmt.Errorf("unexpected signing method: %v", alg)
+	}
+	if !key.RetiresAt.IsZero() && now.After(key.RetiresAt) {
+		return nil, fmt.Errorf("signing key %q has been retired", kid)
+	}
+	return key.verifyKey, nil
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set document.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys that verify tokens at now. HMAC keys are
+// secret and never published.
+func (s *KeySet) JWKS(now time.Time) JWKS {
+	doc := JWKS{Keys: []JWK{}}
+	for _, key := range s.keys {
+		if !key.RetiresAt.IsZero() && now.After(key.RetiresAt) {
+			continue
+		}
+		if jwk, ok := publicJWK(key.verifyKey); ok {
+			jwk.Kid = key.ID
+			jwk.Use = "sig"
+			jwk.Alg = key.Method.Alg()
+			doc.Keys = append(doc.Keys, jwk)
+		}
+	}
+	return doc
+}
+
+func hmacKey(secret string) *Key {
+	// Hash the secret into its key ID so the ID gives nothing away.
+	sum := sha256.Sum256([]byte("choreme-hs256:" + secret))
+	return &Key{
+		ID:        "hs-" + base64.RawURLEncoding.EncodeToString(sum[:9]),
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+}
+
+// loadKeyFile reads a PEM private key: Ed25519 in PKCS #8 signs with EdDSA,
+// RSA in PKCS #8 or PKCS #1 with RS256.
+func loadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("JWT key %s is not PEM encoded", path)
+	}
+
+	var private crypto.PrivateKey
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("JWT key %s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT key %s: %w", path, err)
+	}
+
+	key := &Key{signKey: private}
+	switch private := private.(type) {
+	case ed25519.PrivateKey:
+		key.Method = jwt.SigningMethodEdDSA
+		key.verifyKey = private.Public()
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("JWT key %s: RSA keys need at least %d bits", path, minRSABits)
+		}
+		key.Method = jwt.SigningMethodRS256
+		key.verifyKey = &private.PublicKey
+	default:
+		return nil, fmt.Errorf("JWT key %s: want an Ed25519 or RSA key", path)
+	}
+
+	jwk, _ := publicJWK(key.verifyKey)
+	key.ID, err = thumbprint(jwk)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func publicJWK(key interface{}) (JWK, bool) {
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(key)}, true
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, true
+	}
+	return JWK{}, false
+}
+
+// thumbprint computes the RFC 7638 thumbprint of a public key, which serves
+// as its key ID.
+func thumbprint(jwk JWK) (string, error) {
+	var members interface{}
+	switch jwk.Kty {
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	default:
+		return "", errors.New("unsupported key type")
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -2,22 +2,34 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v10"
 )
 
 type Config struct {
-	Server   ServerConfig   `envPrefix:""`
-	Database DatabaseConfig `envPrefix:"DB_"`
-	JWT      JWTConfig      `envPrefix:"JWT_"`
-	Image    ImageConfig    `envPrefix:""`
-	SMTP     SMTPConfig     `envPrefix:"SMTP_"`
+	Server    ServerConfig    `envPrefix:""`
+	Database  DatabaseConfig  `envPrefix:"DB_"`
+	JWT       JWTConfig       `envPrefix:"JWT_"`
+	Image     ImageConfig     `envPrefix:""`
+	SMTP      SMTPConfig      `envPrefix:"SMTP_"`
+	Jobs      JobsConfig      `envPrefix:"JOBS_"`
+	Auth      AuthConfig      `envPrefix:"AUTH_"`
+	RateLimit RateLimitConfig `envPrefix:"RATE_LIMIT_"`
+	OIDC      OIDCConfig      `envPrefix:"OIDC_"`
 }
 
 type ServerConfig struct {
 	Port    string `env:"PORT" envDefault:"8080"`
 	Host    string `env:"HOST" envDefault:"localhost"`
 	GinMode string `env:"GIN_MODE" envDefault:"debug"`
+	// BaseURL is where users reach the web UI, for links in emails.
+	BaseURL string `env:"BASE_URL" envDefault:"http://localhost:8080"`
+	// TrustedProxies lists the proxies whose X-Forwarded-For header is
+	// believed when working out a client's IP. None are trusted by default.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:","`
 }
 
 type DatabaseConfig struct {
@@ -30,8 +42,23 @@ type DatabaseConfig struct {
 	SSLMode  string `env:"SSL_MODE" envDefault:"disable"`
 }
 
+// DefaultJWTSecret is the placeholder JWT_SECRET. Release builds refuse to
+// sign with it.
+const DefaultJWTSecret = "your-secret-key"
+
 type JWTConfig struct {
-	Secret string `env:"SECRET" envDefault:"your-secret-key"`
+	Secret          string        `env:"SECRET" envDefault:"your-secret-key"`
+	AccessTokenTTL  time.Duration `env:"ACCESS_TOKEN_TTL" envDefault:"15m"`
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+	SessionCacheTTL time.Duration `env:"SESSION_CACHE_TTL" envDefault:"10s"`
+	// KeyFile is a PEM private key that signs access tokens instead of
+	// Secret: Ed25519 signs with EdDSA, RSA with RS256.
+	KeyFile string `env:"KEY_FILE"`
+	// PreviousKeyFiles and PreviousSecrets are keys rotated out of use.
+	// They still verify tokens for KeyGracePeriod after startup.
+	PreviousKeyFiles []string      `env:"PREVIOUS_KEY_FILES" envSeparator:","`
+	PreviousSecrets  []string      `env:"PREVIOUS_SECRETS" envSeparator:","`
+	KeyGracePeriod   time.Duration `env:"KEY_GRACE_PERIOD" envDefault:"1h"`
 }
 
 type ImageConfig struct {
@@ -46,6 +73,161 @@ type SMTPConfig struct {
 	Password  string `env:"PASS"`
 	FromEmail string `env:"FROM_EMAIL"`
 	FromName  string `env:"FROM_NAME" envDefault:"ChoreMe"`
+	// OutboxDir receives mail as .eml files when Host is empty; without it
+	// mail is only logged.
+	OutboxDir string `env:"OUTBOX_DIR"`
+}
+
+// JobsConfig controls the background jobs run by the server.
+type JobsConfig struct {
+	Enabled              bool          `env:"ENABLED" envDefault:"true"`
+	ScheduleInterval     time.Duration `env:"SCHEDULE_INTERVAL" envDefault:"1h"`
+	SweepInterval        time.Duration `env:"SWEEP_INTERVAL" envDefault:"15m"`
+	TokenCleanupInterval time.Duration `env:"TOKEN_CLEANUP_INTERVAL" envDefault:"24h"`
+	// RateLimitCleanupInterval is how often idle rate limit buckets are
+	// dropped.
+	RateLimitCleanupInterval time.Duration `env:"RATE_LIMIT_CLEANUP_INTERVAL" envDefault:"10m"`
+}
+
+// AuthConfig controls account lockout, PIN logins and invitations. After
+// LockoutThreshold consecutive failed logins an account is locked for
+// LockoutDuration, doubling with every further failure up to
+// LockoutMaxDuration.
+type AuthConfig struct {
+	LockoutThreshold   int           `env:"LOCKOUT_THRESHOLD" envDefault:"5"`
+	LockoutDuration    time.Duration `env:"LOCKOUT_DURATION" envDefault:"1m"`
+	LockoutMaxDuration time.Duration `env:"LOCKOUT_MAX_DURATION" envDefault:"1h"`
+	// PINTokenTTL is how long a PIN login on a household device lasts.
+	PINTokenTTL time.Duration `env:"PIN_TOKEN_TTL" envDefault:"1h"`
+	// InviteTTL is how long invitations last unless they say otherwise.
+	InviteTTL time.Duration `env:"INVITE_TTL" envDefault:"168h"`
+	// OpenRegistration lets anyone register a new household. When it is off
+	// only a system administrator can create households.
+	OpenRegistration bool `env:"OPEN_REGISTRATION" envDefault:"true"`
+	// SetupToken must be given to register the first account, which becomes
+	// the system administrator. When empty a random one is logged at startup.
+	SetupToken string `env:"SETUP_TOKEN"`
+}
+
+// RateLimitConfig controls throttling of the unauthenticated endpoints.
+type RateLimitConfig struct {
+	Enabled bool `env:"ENABLED" envDefault:"true"`
+	// Backend is "memory" to keep limits per process or "store" to share
+	// them between instances through the database.
+	Backend       string `env:"BACKEND" envDefault:"memory"`
+	LoginIP       Rate   `env:"LOGIN_IP" envDefault:"20/1m"`
+	LoginEmail    Rate   `env:"LOGIN_EMAIL" envDefault:"5/1m"`
+	RegisterIP    Rate   `env:"REGISTER_IP" envDefault:"10/1h"`
+	JoinIP        Rate   `env:"JOIN_IP" envDefault:"10/1h"`
+	PasswordReset Rate   `env:"PASSWORD_RESET" envDefault:"5/1h"`
+	PINLogin      Rate   `env:"PIN_LOGIN" envDefault:"10/1m"`
+}
+
+// MaxPeriod returns the longest period of the configured rates. Buckets idle
+// for that long have refilled completely.
+func (c RateLimitConfig) MaxPeriod() time.Duration {
+	var max time.Duration
+	for _, rate := range []Rate{c.LoginIP, c.LoginEmail, c.RegisterIP, c.JoinIP, c.PasswordReset, c.PINLogin} {
+		if rate.Period > max {
+			max = rate.Period
+		}
+	}
+	return max
+}
+
+// OIDCConfig lists the OpenID Connect providers users can log in with. Each
+// provider named in OIDC_PROVIDERS is configured by OIDC_<NAME>_* variables,
+// such as OIDC_GOOGLE_ISSUER for "google".
+type OIDCConfig struct {
+	ProviderNames []string `env:"PROVIDERS" envSeparator:","`
+	// RedirectURL is where providers send users back to. The web UI there
+	// posts the code and state to the API. Defaults to
+	// BASE_URL/auth/oidc/callback.
+	RedirectURL string `env:"REDIRECT_URL"`
+	// StateTTL is how long a user has to log in at the provider.
+	StateTTL  time.Duration        `env:"STATE_TTL" envDefault:"10m"`
+	Providers []OIDCProviderConfig `env:"-"`
+}
+
+// OIDCProviderConfig configures one OpenID Connect provider. Its endpoints
+// and keys are discovered from the issuer.
+type OIDCProviderConfig struct {
+	Name         string   `env:"-"`
+	DisplayName  string   `env:"DISPLAY_NAME"`
+	Issuer       string   `env:"ISSUER"`
+	ClientID     string   `env:"CLIENT_ID"`
+	ClientSecret string   `env:"CLIENT_SECRET"`
+	Scopes       []string `env:"SCOPES" envSeparator:"," envDefault:"openid,email,profile"`
+	// LinkByEmail links the first login with an identity to the user with
+	// the same email address, provided the provider says it is verified.
+	// Only enable it for providers trusted to verify addresses.
+	LinkByEmail bool `env:"LINK_BY_EMAIL"`
+}
+
+// loadProviders reads the configuration of each named provider.
+func (c *OIDCConfig) loadProviders() error {
+	c.Providers = nil
+	for _, name := range c.ProviderNames {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		for _, r := range name {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+				return fmt.Errorf("invalid OIDC provider name %q", name)
+			}
+		}
+
+		provider := OIDCProviderConfig{Name: name}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		if err := env.ParseWithOptions(&provider, env.Options{Prefix: prefix}); err != nil {
+			return fmt.Errorf("failed to parse OIDC provider %q: %w", name, err)
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			return fmt.Errorf("OIDC provider %q needs %sISSUER and %sCLIENT_ID", name, prefix, prefix)
+		}
+		if provider.DisplayName == "" {
+			provider.DisplayName = name
+		}
+		c.Providers = append(c.Providers, provider)
+	}
+	return nil
+}
+
+// Rate allows Count events per Period, written as "count/period" such as
+// "10/1m". A zero Count means unlimited.
+type Rate struct {
+	Count  int
+	Period time.Duration
+}
+
+func (r *Rate) UnmarshalText(text []byte) error {
+	value := strings.TrimSpace(string(text))
+	if value == "0" || value == "" {
+		*r = Rate{}
+		return nil
+	}
+	count, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return fmt.Errorf("invalid rate %q: want count/period", value)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid rate %q: bad count", value)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid rate %q: bad period", value)
+	}
+	*r = Rate{Count: n, Period: d}
+	return nil
+}
+
+func (r Rate) String() string {
+	if r.Count == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d/%s", r.Count, r.Period)
 }
 
 func Load() (*Config, error) {
@@ -53,19 +235,43 @@ func Load() (*Config, error) {
 	if err := env.Parse(cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	if b := cfg.RateLimit.Backend; b != "memory" && b != "store" {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_BACKEND %q: want memory or store", b)
+	}
+	if cfg.JWT.KeyFile == "" {
+		if cfg.JWT.Secret == "" {
+			return nil, fmt.Errorf("JWT_SECRET or JWT_KEY_FILE must be set")
+		}
+		if cfg.Server.GinMode == "release" && cfg.JWT.Secret == DefaultJWTSecret {
+			return nil, fmt.Errorf("refusing to run in release mode with the default JWT_SECRET: set JWT_SECRET or JWT_KEY_FILE")
+		}
+	}
+	if err := cfg.OIDC.loadProviders(); err != nil {
+		return nil, err
+	}
+	if cfg.OIDC.RedirectURL == "" {
+		cfg.OIDC.RedirectURL = strings.TrimRight(cfg.Server.BaseURL, "/") + "/auth/oidc/callback"
+	}
 	return cfg, nil
 }
 
 func (c *DatabaseConfig) ConnectionString() string {
 	switch c.Type {
+	// Sessions run in UTC, which timestamps are stored in, so database-side
+	// defaults and conversions agree with the values the stores write.
 	case "postgres":
-		return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+		return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s&timezone=UTC",
 			c.User, c.Password, c.Host, c.Port, c.Name, c.SSLMode)
 	case "mysql":
-		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
+		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&loc=UTC&time_zone=%%27%%2B00%%3A00%%27",
 			c.User, c.Password, c.Host, c.Port, c.Name)
 	case "sqlite":
-		return c.Name
+		// Take the write lock at BEGIN so read-then-write transactions
+		// serialize instead of failing with SQLITE_BUSY on upgrade.
+		if strings.Contains(c.Name, "?") {
+			return c.Name + "&_txlock=immediate&_loc=UTC"
+		}
+		return c.Name + "?_txlock=immediate&_loc=UTC"
 	default:
 		return ""
 	}
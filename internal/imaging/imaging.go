@@ -0,0 +1,204 @@
+// Package imaging normalizes uploaded photos before they are stored.
+//
+// Process sniffs the real format from the data rather than trusting a file
+// name or declared type, decodes the image, turns it upright according to
+// its EXIF orientation, shrinks it to fit a maximum dimension and encodes it
+// again. Re-encoding drops every piece of metadata, EXIF and GPS included.
+// JPEG photos stay JPEG; PNG and GIF images become PNG, which keeps their
+// transparency. Only the standard library's decoders are used, so JPEG, PNG
+// and GIF are accepted.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	// ErrNotImage is returned for data that isn't a supported image.
+	ErrNotImage = errors.New("not a JPEG, PNG or GIF image")
+	// ErrTooLarge is returned for images over the size or pixel limits.
+	ErrTooLarge = errors.New("image too large")
+)
+
+// MaxPixels caps the pixels an image may decode to, so a small file can't
+// expand into gigabytes of memory.
+const MaxPixels = 50_000_000
+
+// jpegQuality is the quality photos are encoded with.
+const jpegQuality = 85
+
+// Options limit the images Process accepts and produces.
+type Options struct {
+	// MaxBytes caps both the upload and the processed image
+	MaxBytes int
+	// MaxDimension is the longest side images are shrunk to fit
+	MaxDimension int
+}
+
+// Image is a processed image.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Process validates and normalizes an uploaded image.
+func Process(data []byte, opts Options) (*Image, error) {
+	if opts.MaxBytes > 0 && len(data) > opts.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	var decode func([]byte) (image.Image, error)
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg":
+		decode = func(data []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(data)) }
+	case "image/png":
+		decode = func(data []byte) (image.Image, error) { return png.Decode(bytes.NewReader(data)) }
+	case "image/gif":
+		decode = func(data []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(data)) }
+	default:
+		return nil, ErrNotImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrNotImage
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+	decoded, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+
+	img := toRGBA(decoded)
+	if contentType == "image/jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+	img = fit(img, opts.MaxDimension)
+
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		contentType = "image/png"
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	if opts.MaxBytes > 0 && buf.Len() > opts.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	bounds := img.Bounds()
+	return &Image{
+		Data:        buf.Bytes(),
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+	}, nil
+}
+
+// toRGBA copies img into a premultiplied RGBA image at the origin.
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// fit shrinks img so neither side exceeds max, keeping its aspect ratio.
+// Each pixel of the result averages the source pixels it covers. Images
+// that fit already, or a max of 0, are returned unchanged.
+func fit(img *image.RGBA, max int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if max <= 0 || (w <= max && h <= max) {
+		return img
+	}
+	dw, dh := max, h*max/w
+	if h > w {
+		dw, dh = w*max/h, max
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, (y+1)*h/dh
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, (x+1)*w/dw
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := img.Pix[sy*img.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+			p := dst.Pix[y*dst.Stride+x*4 : y*dst.Stride+x*4+4]
+			p[0], p[1], p[2], p[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// orient turns img upright for its EXIF orientation, 1 to 8. Other values
+// leave it as it is.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // upside down and mirrored
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // turned left, so rotate clockwise
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // turned right, so rotate counterclockwise
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], img.Pix[sy*img.Stride+sx*4:sy*img.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
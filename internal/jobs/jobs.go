@@ -0,0 +1,137 @@
+// Package jobs runs periodic background work inside the server process.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a named unit of periodic work. Run returns a summary of what it did,
+// which is reported in the job's status.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, now time.Time) (interface{}, error)
+}
+
+// Status reports how a job's runs have gone so far.
+type Status struct {
+	Name         string      `json:"name"`
+	Interval     string      `json:"interval"`
+	Running      bool        `json:"running"`
+	LastRun      *time.Time  `json:"last_run,omitempty"`
+	LastDuration string      `json:"last_duration,omitempty"`
+	LastError    *string     `json:"last_error,omitempty"`
+	LastResult   interface{} `json:"last_result,omitempty"`
+	NextRun      *time.Time  `json:"next_run,omitempty"`
+	Runs         int         `json:"runs"`
+	Failures     int         `json:"failures"`
+
+	interval time.Duration
+}
+
+// Runner runs each of its jobs once at start and then on the job's interval
+// until the context is cancelled.
+type Runner struct {
+	jobs []Job
+
+	mu       sync.Mutex
+	statuses map[string]*Status
+}
+
+func NewRunner() *Runner {
+	return &Runner{
+		statuses: make(map[string]*Status),
+	}
+}
+
+// Add registers a job. Jobs must be added before Start.
+func (r *Runner) Add(job Job) {
+	r.jobs = append(r.jobs, job)
+	r.statuses[job.Name] = &Status{
+		Name:     job.Name,
+		Interval: job.Interval.String(),
+		interval: job.Interval,
+	}
+}
+
+func (r *Runner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		go r.loop(ctx, job)
+	}
+}
+
+// Statuses returns a snapshot of every job's status, in the order the jobs
+// were added. Jobs that haven't been started have no last or next run.
+func (r *Runner) Statuses() []Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make([]Status, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		statuses = append(statuses, *r.statuses[job.Name])
+	}
+	return statuses
+}
+
+func (r *Runner) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.run(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) run(ctx context.Context, job Job) {
+	start := time.Now()
+	r.update(job.Name, func(status *Status) {
+		status.Running = true
+		status.LastRun = &start
+	})
+
+	result, err := r.call(ctx, job, start)
+	duration := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		log.Printf("Job %s failed after %s: %v", job.Name, duration, err)
+	}
+
+	r.update(job.Name, func(status *Status) {
+		next := start.Add(status.interval)
+		status.Running = false
+		status.LastDuration = duration.String()
+		status.LastResult = result
+		status.LastError = nil
+		status.NextRun = &next
+		status.Runs++
+		if err != nil {
+			message := err.Error()
+			status.LastError = &message
+			status.Failures++
+		}
+	})
+}
+
+// call runs the job, turning a panic into an error.
+func (r *Runner) call(ctx context.Context, job Job, now time.Time) (result interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return job.Run(ctx, now)
+}
+
+func (r *Runner) update(name string, fn func(*Status)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(r.statuses[name])
+}
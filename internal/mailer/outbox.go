@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Outbox keeps mail instead of sending it: each message is written to dir as
+// an .eml file, or logged when dir is empty.
+type Outbox struct {
+	dir  string
+	from mail.Address
+}
+
+func NewOutbox(dir string, from mail.Address) *Outbox {
+	return &Outbox{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (m *Outbox) Send(ctx context.Context, msg *Message) error {
+	if m.dir == "" {
+		log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+
+	now := time.Now()
+	data, err := format(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create outbox: %w", err)
+	}
+	name := fmt.Sprintf("%s-%09d.eml", now.UTC().Format("20060102T150405"), now.Nanosecond())
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o644)
+}
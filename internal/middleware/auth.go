@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/choreme/choreme/internal/auth"
 	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,7 +19,23 @@ const (
 	BearerPrefix        = "Bearer "
 )
 
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+// SessionValidator checks that a token's session epoch is still current for
+// its user.
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, userID, epoch int) (bool, error)
+}
+
+// APITokenAuthenticator looks up the claims a personal access token
+// authenticates with.
+type APITokenAuthenticator interface {
+	AuthenticateAPIToken(ctx context.Context, token string) (*auth.Claims, error)
+}
+
+// AuthMiddleware authenticates requests by their bearer token, a JWT or a
+// personal access token. Besides a JWT's signature and expiry, its session
+// epoch must still be the user's, so role and membership changes apply to
+// tokens already issued.
+func AuthMiddleware(jwtManager *auth.JWTManager, sessions SessionValidator, apiTokens APITokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader(AuthorizationHeader)
 		if authHeader == "" {
@@ -38,6 +57,29 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, BearerPrefix)
+		if auth.IsAPIToken(tokenString) {
+			claims, err := apiTokens.AuthenticateAPIToken(c.Request.Context(), tokenString)
+			if errors.Is(err, service.ErrUnauthorized) {
+				c.JSON(http.StatusUnauthorized, model.APIResponse{
+					Success: false,
+					Error:   err.Error(),
+				})
+				c.Abort()
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, model.APIResponse{
+					Success: false,
+					Error:   "Failed to validate API token",
+				})
+				c.Abort()
+				return
+			}
+			c.Set(ContextClaimsKey, claims)
+			c.Next()
+			return
+		}
+
 		claims, err := jwtManager.ValidateToken(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, model.APIResponse{
@@ -48,6 +90,24 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		valid, err := sessions.ValidateSession(c.Request.Context(), claims.UserID, claims.SessionEpoch)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, model.APIResponse{
+				Success: false,
+				Error:   "Failed to validate session",
+			})
+			c.Abort()
+			return
+		}
+		if !valid {
+			c.JSON(http.StatusUnauthorized, model.APIResponse{
+				Success: false,
+				Error:   "Session expired",
+			})
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set(ContextClaimsKey, claims)
 		c.Next()
@@ -84,10 +144,6 @@ func RequireRole(allowedRoles ...model.Role) gin.HandlerFunc {
 	}
 }
 
-func RequireAdminOrManager() gin.HandlerFunc {
-	return RequireRole(model.RoleSystemAdmin, model.RoleAdmin, model.RoleManager)
-}
-
 func RequireSystemAdmin() gin.HandlerFunc {
 	return RequireRole(model.RoleSystemAdmin)
 }
@@ -114,4 +170,60 @@ func GetHouseholdID(c *gin.Context) (int, bool) {
 		return 0, false
 	}
 	return claims.HouseholdID, true
-}
\ No newline at end of file
+}
+
+// RestrictScope limits tokens carrying scope to the given routes, each
+// written as the method and route pattern, such as "GET /api/v1/chores/:id".
+// Tokens without the scope are unaffected.
+func RestrictScope(scope string, routes ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		allowed[route] = true
+	}
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok || claims.Scope != scope || allowed[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, model.APIResponse{
+			Success: false,
+			Error:   "Not available to this token",
+		})
+		c.Abort()
+	}
+}
+
+// RequireAPITokenScopes enforces the scopes of personal access tokens.
+// Routes maps each route, written as the method and route pattern, to the
+// scope it needs; routes missing from it can't be used with a personal
+// access token. Other tokens are unaffected.
+func RequireAPITokenScopes(routes map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok || claims.Scope != auth.ScopeAPIToken {
+			c.Next()
+			return
+		}
+
+		scope, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.JSON(http.StatusForbidden, model.APIResponse{
+				Success: false,
+				Error:   "Not available to API tokens",
+			})
+			c.Abort()
+			return
+		}
+		if !auth.HasScope(claims.Scopes, scope) {
+			c.JSON(http.StatusForbidden, model.APIResponse{
+				Success: false,
+				Error:   "API token lacks the " + scope + " scope",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const ContextDeviceKey = "device"
+
+// DeviceAuthenticator looks up the household device a device token belongs
+// to.
+type DeviceAuthenticator interface {
+	AuthenticateDevice(ctx context.Context, token string) (*model.HouseholdDevice, error)
+}
+
+// DeviceMiddleware authenticates requests from a household device by its
+// bearer device token.
+func DeviceMiddleware(devices DeviceAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader(AuthorizationHeader)
+		if !strings.HasPrefix(authHeader, BearerPrefix) {
+			c.JSON(http.StatusUnauthorized, model.APIResponse{
+				Success: false,
+				Error:   "Device token required",
+			})
+			c.Abort()
+			return
+		}
+
+		device, err := devices.AuthenticateDevice(c.Request.Context(), strings.TrimPrefix(authHeader, BearerPrefix))
+		if errors.Is(err, service.ErrUnauthorized) {
+			c.JSON(http.StatusUnauthorized, model.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, model.APIResponse{
+				Success: false,
+				Error:   "Failed to authenticate device",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextDeviceKey, device)
+		c.Next()
+	}
+}
+
+func GetDevice(c *gin.Context) (*model.HouseholdDevice, bool) {
+	device, exists := c.Get(ContextDeviceKey)
+	if !exists {
+		return nil, false
+	}
+	return device.(*model.HouseholdDevice), true
+}
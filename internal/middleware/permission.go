@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/choreme/choreme/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+const ContextPermissionsKey = "permissions"
+
+// PermissionResolver looks up what a role may do in a household.
+type PermissionResolver interface {
+	Permissions(ctx context.Context, householdID int, role model.Role) (model.PermissionSet, error)
+}
+
+// PermissionMiddleware resolves the authenticated user's permissions for
+// RequirePermission and the handlers. It runs after AuthMiddleware.
+func PermissionMiddleware(resolver PermissionResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		permissions, err := resolver.Permissions(c.Request.Context(), claims.HouseholdID, claims.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, model.APIResponse{
+				Success: false,
+				Error:   "Failed to load permissions",
+			})
+			c.Abort()
+			return
+		}
+		c.Set(ContextPermissionsKey, permissions)
+		c.Next()
+	}
+}
+
+// RequirePermission only lets users whose role has the permission through.
+func RequirePermission(permission model.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permissions, ok := GetPermissions(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, model.APIResponse{
+				Success: false,
+				Error:   "Authentication required",
+			})
+			c.Abort()
+			return
+		}
+
+		if !permissions[permission] {
+			c.JSON(http.StatusForbidden, model.APIResponse{
+				Success: false,
+				Error:   "Insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func GetPermissions(c *gin.Context) (model.PermissionSet, bool) {
+	permissions, exists := c.Get(ContextPermissionsKey)
+	if !exists {
+		return nil, false
+	}
+	return permissions.(model.PermissionSet), true
+}
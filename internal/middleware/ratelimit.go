@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit throttles a route per client IP. Name separates the buckets of
+// different routes.
+func RateLimit(limiter ratelimit.Limiter, name string, rate config.Rate) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Throttle(c, limiter, name+":ip:"+c.ClientIP(), rate) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// Throttle takes a token for key and reports whether the request may go
+// on. Refused requests get a 429 response with a Retry-After header. A
+// failing limiter is logged and lets the request through.
+func Throttle(c *gin.Context, limiter ratelimit.Limiter, key string, rate config.Rate) bool {
+	if limiter == nil {
+		return true
+	}
+	allowed, retryAfter, err := limiter.Allow(c.Request.Context(), key, rate)
+	if err != nil {
+		log.Printf("Rate limiter failed for %s: %v", key, err)
+		return true
+	}
+	if allowed {
+		return true
+	}
+
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, model.APIResponse{
+		Success: false,
+		Error:   "Too many requests, please try again later",
+	})
+	c.Abort()
+	return false
+}
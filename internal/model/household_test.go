@@ -0,0 +1,91 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoundsAcrossDaylightSaving(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	household := NewHousehold("Test", time.Now())
+	household.Timezone = "America/New_York"
+
+	tests := []struct {
+		name       string
+		period     Period
+		t          time.Time
+		start, end time.Time
+		length     time.Duration
+	}{
+		{
+			name:   "spring forward day",
+			period: PeriodDay,
+			t:      time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC),
+			start:  time.Date(2026, 3, 8, 0, 0, 0, 0, newYork),
+			end:    time.Date(2026, 3, 9, 0, 0, 0, 0, newYork),
+			length: 23 * time.Hour,
+		},
+		{
+			name:   "fall back day",
+			period: PeriodDay,
+			t:      time.Date(2026, 11, 1, 23, 30, 0, 0, newYork),
+			start:  time.Date(2026, 11, 1, 0, 0, 0, 0, newYork),
+			end:    time.Date(2026, 11, 2, 0, 0, 0, 0, newYork),
+			length: 25 * time.Hour,
+		},
+		{
+			name:   "late UTC evening is the next local day",
+			period: PeriodDay,
+			t:      time.Date(2026, 11, 2, 3, 0, 0, 0, time.UTC),
+			start:  time.Date(2026, 11, 1, 0, 0, 0, 0, newYork),
+			end:    time.Date(2026, 11, 2, 0, 0, 0, 0, newYork),
+			length: 25 * time.Hour,
+		},
+		{
+			name:   "spring forward week",
+			period: PeriodWeek,
+			t:      time.Date(2026, 3, 8, 12, 0, 0, 0, newYork),
+			start:  time.Date(2026, 3, 2, 0, 0, 0, 0, newYork),
+			end:    time.Date(2026, 3, 9, 0, 0, 0, 0, newYork),
+			length: 7*24*time.Hour - time.Hour,
+		},
+		{
+			name:   "fall back week",
+			period: PeriodWeek,
+			t:      time.Date(2026, 10, 27, 12, 0, 0, 0, newYork),
+			start:  time.Date(2026, 10, 26, 0, 0, 0, 0, newYork),
+			end:    time.Date(2026, 11, 2, 0, 0, 0, 0, newYork),
+			length: 7*24*time.Hour + time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := household.Bounds(tt.period, tt.t)
+			if !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("Bounds(%s, %v) = %v, %v, want %v, %v", tt.period, tt.t, start, end, tt.start, tt.end)
+			}
+			if got := end.Sub(start); got != tt.length {
+				t.Errorf("period is %v long, want %v", got, tt.length)
+			}
+		})
+	}
+}
+
+func TestBoundsWeekStart(t *testing.T) {
+	household := NewHousehold("Test", time.Now())
+	household.Timezone = "Europe/Berlin"
+	household.WeekStart = time.Sunday
+	berlin := household.Location()
+
+	// Berlin springs forward on Sunday, March 29, 2026
+	start, end := household.Bounds(PeriodWeek, time.Date(2026, 3, 29, 12, 0, 0, 0, berlin))
+	if want := time.Date(2026, 3, 29, 0, 0, 0, 0, berlin); !start.Equal(want) {
+		t.Errorf("week starts %v, want %v", start, want)
+	}
+	if want := time.Date(2026, 4, 5, 0, 0, 0, 0, berlin); !end.Equal(want) {
+		t.Errorf("week ends %v, want %v", end, want)
+	}
+}
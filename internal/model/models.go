@@ -16,6 +16,49 @@ const (
 	RoleObserver    Role = "observer"
 )
 
+// Permission is an action a role may be allowed to take in its household.
+type Permission string
+
+const (
+	// PermHouseholdView allows reading every member's chores, assignments,
+	// ledger and redemptions; without it members only see their own.
+	PermHouseholdView     Permission = "household.view"
+	PermHouseholdSettings Permission = "household.settings"
+	PermHouseholdInvite   Permission = "household.invite"
+	PermMembersView       Permission = "members.view"
+	PermMembersManage     Permission = "members.manage"
+	PermDevicesManage     Permission = "devices.manage"
+	PermChoresCreate      Permission = "chores.create"
+	PermChoresUpdate      Permission = "chores.update"
+	PermChoresDelete      Permission = "chores.delete"
+	// PermAssignmentsWork allows working on other members' assignments.
+	PermAssignmentsWork    Permission = "assignments.work"
+	PermAssignmentsApprove Permission = "assignments.approve"
+	PermRewardsCreate      Permission = "rewards.create"
+	PermRewardsUpdate      Permission = "rewards.update"
+	PermRewardsDelete      Permission = "rewards.delete"
+	PermRewardsRedeem      Permission = "rewards.redeem"
+	PermRedemptionsApprove Permission = "redemptions.approve"
+	PermLedgerAdjust       Permission = "ledger.adjust"
+	PermAuditRead          Permission = "audit.read"
+	PermReportsRead        Permission = "reports.read"
+	PermRolesManage        Permission = "roles.manage"
+)
+
+// Permissions lists every permission.
+var Permissions = []Permission{
+	PermHouseholdView, PermHouseholdSettings, PermHouseholdInvite,
+	PermMembersView, PermMembersManage, PermDevicesManage,
+	PermChoresCreate, PermChoresUpdate, PermChoresDelete,
+	PermAssignmentsWork, PermAssignmentsApprove,
+	PermRewardsCreate, PermRewardsUpdate, PermRewardsDelete, PermRewardsRedeem,
+	PermRedemptionsApprove, PermLedgerAdjust,
+	PermAuditRead, PermReportsRead, PermRolesManage,
+}
+
+// PermissionSet is the set of permissions a role has.
+type PermissionSet map[Permission]bool
+
 type Priority string
 
 const (
@@ -33,6 +76,7 @@ const (
 	StatusApproved   AssignmentStatus = "approved"
 	StatusRejected   AssignmentStatus = "rejected"
 	StatusLate       AssignmentStatus = "late"
+	StatusExpired    AssignmentStatus = "expired"
 )
 
 type LedgerType string
@@ -51,24 +95,198 @@ const (
 	RedemptionStatusRejected RedemptionStatus = "rejected"
 )
 
+// DefaultScheduleDaysAhead is how far ahead recurring chores are generated
+// for new households.
+const DefaultScheduleDaysAhead = 30
+
+// Defaults for the settings of new households.
+const (
+	DefaultCurrency      = "USD"
+	DefaultDecimalPlaces = 2
+	DefaultTimezone      = "UTC"
+	DefaultWeekStart     = time.Monday
+	DefaultLocale        = "en-US"
+)
+
+// MaxDecimalPlaces is the precision values are stored with.
+const MaxDecimalPlaces = 2
+
 type Household struct {
-	ID         int       `json:"id" db:"id"`
-	Name       string    `json:"name" db:"name"`
-	InviteCode *string   `json:"invite_code,omitempty" db:"invite_code"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// SuspendedAt is set while a system administrator has suspended the
+	// household; its members can't use it until the suspension is lifted
+	SuspendedAt *time.Time `json:"suspended_at,omitempty" db:"suspended_at"`
+
+	// Settings
+	AllowNegativeBalance bool `json:"allow_negative_balance" db:"allow_negative_balance"`
+	// ScheduleDaysAhead is how many days ahead recurring chores are generated
+	ScheduleDaysAhead int `json:"schedule_days_ahead" db:"schedule_days_ahead"`
+	// RequireTwoFactor makes admins and managers enroll in two-factor
+	// authentication before they can log in
+	RequireTwoFactor bool `json:"require_two_factor" db:"require_two_factor"`
+	// Currency is the ISO 4217 code values are in, unless PointsLabel is
+	// set and the household counts points under that label instead
+	Currency    string  `json:"currency" db:"currency"`
+	PointsLabel *string `json:"points_label,omitempty" db:"points_label"`
+	// DecimalPlaces is how many places values are kept to
+	DecimalPlaces int `json:"decimal_places" db:"decimal_places"`
+	// Timezone is the IANA timezone the household's days are in
+	Timezone string `json:"timezone" db:"timezone"`
+	// WeekStart is the weekday weeks start on, 0 for Sunday
+	WeekStart time.Weekday `json:"week_start" db:"week_start"`
+	// Locale is the BCP 47 language tag clients format values and dates with
+	Locale string `json:"locale" db:"locale"`
+}
+
+// NewHousehold returns a household with the default settings.
+func NewHousehold(name string, now time.Time) *Household {
+	return &Household{
+		Name:              name,
+		CreatedAt:         now,
+		ScheduleDaysAhead: DefaultScheduleDaysAhead,
+		Currency:          DefaultCurrency,
+		DecimalPlaces:     DefaultDecimalPlaces,
+		Timezone:          DefaultTimezone,
+		WeekStart:         DefaultWeekStart,
+		Locale:            DefaultLocale,
+	}
+}
+
+// UsesPoints reports whether the household counts points rather than money.
+func (h *Household) UsesPoints() bool {
+	return h.PointsLabel != nil
+}
+
+// Location returns the household's timezone, or UTC when it can't be loaded.
+func (h *Household) Location() *time.Location {
+	if loc, err := time.LoadLocation(h.Timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// StartOfDay returns the start of the household's day containing t.
+func (h *Household) StartOfDay(t time.Time) time.Time {
+	t = t.In(h.Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartOfWeek returns the start of the household's week containing t.
+func (h *Household) StartOfWeek(t time.Time) time.Time {
+	day := h.StartOfDay(t)
+	back := (int(day.Weekday()) - int(h.WeekStart) + 7) % 7
+	return day.AddDate(0, 0, -back)
 }
 
+// StartOfMonth returns the start of the household's month containing t.
+func (h *Household) StartOfMonth(t time.Time) time.Time {
+	day := h.StartOfDay(t)
+	return day.AddDate(0, 0, 1-day.Day())
+}
+
+// Period is a span of a household's calendar.
+type Period string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+)
+
+// Valid reports whether p is a known period.
+func (p Period) Valid() bool {
+	switch p {
+	case PeriodDay, PeriodWeek, PeriodMonth:
+		return true
+	}
+	return false
+}
+
+// Bounds returns the start of the household's period containing t and the
+// start of the one after it. Days are calendar days of the household's
+// timezone, so they can be 23 or 25 hours long.
+func (h *Household) Bounds(period Period, t time.Time) (start, end time.Time) {
+	switch period {
+	case PeriodWeek:
+		start = h.StartOfWeek(t)
+		return start, start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		start = h.StartOfMonth(t)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		start = h.StartOfDay(t)
+		return start, start.AddDate(0, 0, 1)
+	}
+}
+
+// Round rounds an amount to the household's decimal places.
+func (h *Household) Round(amount decimal.Decimal) decimal.Decimal {
+	return amount.Round(int32(h.DecimalPlaces))
+}
+
+// FormatAmount formats an amount with the household's decimal places.
+func (h *Household) FormatAmount(amount decimal.Decimal) string {
+	return amount.StringFixed(int32(h.DecimalPlaces))
+}
+
+// User is an account as a member of one household: HouseholdID, Role,
+// DeactivatedAt and RemovedAt are those of that membership. Loaded on its
+// own, a user is a member of the household they are currently in.
 type User struct {
 	ID                     int       `json:"id" db:"id"`
 	HouseholdID           int       `json:"household_id" db:"household_id"`
 	Name                  string    `json:"name" db:"name"`
-	Email                 string    `json:"email" db:"email"`
+	Email                 string    `json:"email,omitempty" db:"email"` // empty for profiles
 	PasswordHash          string    `json:"-" db:"password_hash"`
 	Role                  Role      `json:"role" db:"role"`
 	NotificationPrefEmail bool      `json:"notification_pref_email" db:"notification_pref_email"`
 	NotificationPrefPush  bool      `json:"notification_pref_push" db:"notification_pref_push"`
 	CreatedAt             time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt             time.Time `json:"updated_at" db:"updated_at"`
+	// SessionEpoch is bumped when the user's access changes; tokens issued
+	// for an older epoch are rejected.
+	SessionEpoch    int        `json:"-" db:"session_epoch"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	// FailedLogins counts consecutive failed logins; past a threshold they
+	// lock the account until LockedUntil.
+	FailedLogins int        `json:"-" db:"failed_logins"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+	// TOTPSecret is set on enrollment; two-factor authentication is on once
+	// TOTPEnabledAt is set. TOTPLastStep is the last time step a code was
+	// accepted for.
+	TOTPSecret    *string    `json:"-" db:"totp_secret"`
+	TOTPEnabledAt *time.Time `json:"two_factor_enabled_at,omitempty" db:"totp_enabled_at"`
+	TOTPLastStep  int64      `json:"-" db:"totp_last_step"`
+	// PINHash is the hash of the PIN the user logs in with on a household
+	// device. Profiles of young children have a PIN instead of an email
+	// address and password.
+	PINHash *string `json:"-" db:"pin_hash"`
+	// DeactivatedAt is set while an admin has suspended the member, who
+	// can't use the household until reactivated. RemovedAt is set once the
+	// member left the household; the membership stays for the history that
+	// refers to it.
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
+	RemovedAt     *time.Time `json:"removed_at,omitempty" db:"removed_at"`
+}
+
+// IsProfile reports whether the user is an email-less profile that can only
+// log in with a PIN.
+func (u *User) IsProfile() bool {
+	return u.Email == ""
+}
+
+// TwoFactorEnabled reports whether the user has to give a TOTP code or a
+// recovery code after their password.
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// Active reports whether the user is neither deactivated nor removed, and so
+// can log in and be assigned chores.
+func (u *User) Active() bool {
+	return u.DeactivatedAt == nil && u.RemovedAt == nil
 }
 
 type Chore struct {
@@ -87,6 +305,14 @@ type Chore struct {
 	CreatedBy       int             `json:"created_by" db:"created_by"`
 	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+
+	// ScheduleStart is the first occurrence of a recurring chore's frequency
+	// and ScheduledUntil the latest occurrence generated so far
+	ScheduleStart  *time.Time `json:"schedule_start,omitempty" db:"schedule_start"`
+	ScheduledUntil *time.Time `json:"scheduled_until,omitempty" db:"scheduled_until"`
+
+	// Joined fields
+	Assignments []*Assignment `json:"assignments,omitempty"`
 }
 
 type Assignment struct {
@@ -97,6 +323,9 @@ type Assignment struct {
 	PercentComplete decimal.Decimal   `json:"percent_complete" db:"percent_complete"`
 	Status          AssignmentStatus  `json:"status" db:"status"`
 	ProofImage      []byte            `json:"-" db:"proof_image"`
+	// ProofImageType is the content type ProofImage was processed into. It
+	// is nil for images stored before uploads were processed.
+	ProofImageType  *string           `json:"-" db:"proof_image_type"`
 	ApprovalNotes   *string           `json:"approval_notes,omitempty" db:"approval_notes"`
 	CompletedAt     *time.Time        `json:"completed_at,omitempty" db:"completed_at"`
 	ApprovedAt      *time.Time        `json:"approved_at,omitempty" db:"approved_at"`
@@ -123,6 +352,9 @@ type Redemption struct {
 	RewardID   int              `json:"reward_id" db:"reward_id"`
 	UserID     int              `json:"user_id" db:"user_id"`
 	Status     RedemptionStatus `json:"status" db:"status"`
+	// Cost is the reward's cost when redeemed; it is held against the
+	// user's balance while the redemption is pending.
+	Cost       decimal.Decimal  `json:"cost" db:"cost"`
 	RedeemedAt time.Time        `json:"redeemed_at" db:"redeemed_at"`
 	ApprovedAt *time.Time       `json:"approved_at,omitempty" db:"approved_at"`
 
@@ -133,6 +365,7 @@ type Redemption struct {
 
 type LedgerEntry struct {
 	ID                 int             `json:"id" db:"id"`
+	HouseholdID        int             `json:"household_id" db:"household_id"`
 	UserID             int             `json:"user_id" db:"user_id"`
 	Type               LedgerType      `json:"type" db:"type"`
 	Amount             decimal.Decimal `json:"amount" db:"amount"`
@@ -150,7 +383,7 @@ type LedgerEntry struct {
 type AuditLog struct {
 	ID          int                    `json:"id" db:"id"`
 	HouseholdID int                    `json:"household_id" db:"household_id"`
-	UserID      int                    `json:"user_id" db:"user_id"`
+	UserID      *int                   `json:"user_id,omitempty" db:"user_id"` // nil for background jobs
 	Action      string                 `json:"action" db:"action"`
 	Details     map[string]interface{} `json:"details,omitempty" db:"details"`
 	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
@@ -159,10 +392,172 @@ type AuditLog struct {
 	User *User `json:"user,omitempty"`
 }
 
+// RefreshToken is a server-side refresh token. The token itself is only
+// returned to the client; the store keeps its hash.
+type RefreshToken struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	FamilyID  string     `json:"family_id" db:"family_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	// TokenPurposeTwoFactorChallenge tokens are handed out after the
+	// password of a login and exchanged for a session with the second factor.
+	TokenPurposeTwoFactorChallenge TokenPurpose = "two_factor_challenge"
+)
+
+// UserToken is a single-use token mailed to a user. Email is the address it
+// was sent to, which a verification token verifies.
+type UserToken struct {
+	ID        int          `json:"id" db:"id"`
+	UserID    int          `json:"user_id" db:"user_id"`
+	Purpose   TokenPurpose `json:"purpose" db:"purpose"`
+	TokenHash string       `json:"-" db:"token_hash"`
+	Email     string       `json:"email" db:"email"`
+	ExpiresAt time.Time    `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time   `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+}
+
+// RateLimitBucket is the persisted state of a token bucket: Tokens were
+// available at UpdatedAt.
+type RateLimitBucket struct {
+	Key       string    `json:"key" db:"bucket_key"`
+	Tokens    float64   `json:"tokens" db:"tokens"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RecoveryCode is a single-use code that stands in for a TOTP code.
+type RecoveryCode struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	CodeHash  string     `json:"-" db:"code_hash"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// UserIdentity links a user to their account at an external OpenID Connect
+// provider, identified by the provider's subject.
+type UserIdentity struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      int        `json:"user_id" db:"user_id"`
+	Provider    string     `json:"provider" db:"provider"`
+	Subject     string     `json:"subject" db:"subject"`
+	Email       *string    `json:"email,omitempty" db:"email"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// OIDCAuthRequest is a pending OpenID Connect authorization request. A
+// UserID marks a request to link an identity rather than to log in.
+type OIDCAuthRequest struct {
+	ID           int       `json:"id" db:"id"`
+	StateHash    string    `json:"-" db:"state_hash"`
+	Provider     string    `json:"provider" db:"provider"`
+	CodeVerifier string    `json:"-" db:"code_verifier"`
+	Nonce        string    `json:"-" db:"nonce"`
+	UserID       *int      `json:"user_id,omitempty" db:"user_id"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// APIToken is a personal access token a user created for scripts and
+// integrations. It acts as the user, limited to its scopes. Only a hash of
+// the token is stored.
+type APIToken struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      int        `json:"user_id" db:"user_id"`
+	HouseholdID int        `json:"household_id" db:"household_id"`
+	Name        string     `json:"name" db:"name"`
+	TokenPrefix string     `json:"token_prefix" db:"token_prefix"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	Scopes      []string   `json:"scopes" db:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// RolePermission grants or revokes one permission of a role in a
+// household, overriding the role's default.
+type RolePermission struct {
+	HouseholdID int        `json:"household_id" db:"household_id"`
+	Role        Role       `json:"role" db:"role"`
+	Permission  Permission `json:"permission" db:"permission"`
+	Granted     bool       `json:"granted" db:"granted"`
+}
+
+// HouseholdDevice is a shared device, such as a family tablet, on which
+// household members log in with their PIN. Only a hash of its token is
+// stored.
+type HouseholdDevice struct {
+	ID          int        `json:"id" db:"id"`
+	HouseholdID int        `json:"household_id" db:"household_id"`
+	Name        string     `json:"name" db:"name"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	CreatedBy   *int       `json:"created_by,omitempty" db:"created_by"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Invitation lets people join a household with its code, taking the
+// invitation's role. Invitations bound to an email address only work for
+// that address.
+type Invitation struct {
+	ID          int        `json:"id" db:"id"`
+	HouseholdID int        `json:"household_id" db:"household_id"`
+	Code        string     `json:"code" db:"code"`
+	Role        Role       `json:"role" db:"role"`
+	Email       *string    `json:"email,omitempty" db:"email"`
+	MaxUses     *int       `json:"max_uses,omitempty" db:"max_uses"`
+	Uses        int        `json:"uses" db:"uses"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedBy   *int       `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	// Link opens the join form with the code filled in
+	Link string `json:"link"`
+}
+
+// Usable reports whether the invitation can still be used to join at now.
+func (i *Invitation) Usable(now time.Time) bool {
+	if i.RevokedAt != nil || (i.ExpiresAt != nil && !now.Before(*i.ExpiresAt)) {
+		return false
+	}
+	return i.MaxUses == nil || i.Uses < *i.MaxUses
+}
+
+// Membership is one of the households a user belongs to.
+type Membership struct {
+	HouseholdID   int        `json:"household_id" db:"household_id"`
+	UserID        int        `json:"user_id" db:"user_id"`
+	HouseholdName string     `json:"household_name" db:"household_name"`
+	Role          Role       `json:"role" db:"role"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	// HouseholdSuspendedAt is set while the household is suspended
+	HouseholdSuspendedAt *time.Time `json:"household_suspended_at,omitempty" db:"household_suspended_at"`
+	// Current marks the household the user's logins land in
+	Current bool `json:"current"`
+}
+
 // DTOs for API requests/responses
 
+// CreateHouseholdRequest is a system administrator opening a household. The
+// household starts without members and with an admin invitation, mailed to
+// AdminEmail when it is given.
 type CreateHouseholdRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name       string  `json:"name" binding:"required"`
+	AdminEmail *string `json:"admin_email" binding:"omitempty,email"`
 }
 
 type RegisterRequest struct {
@@ -170,6 +565,52 @@ type RegisterRequest struct {
 	Name          string `json:"name" binding:"required"`
 	Email         string `json:"email" binding:"required,email"`
 	Password      string `json:"password" binding:"required,min=6"`
+	// SetupToken makes the first account on the instance its system
+	// administrator; registering is refused without it until then
+	SetupToken string `json:"setup_token"`
+}
+
+// SetupStatus tells clients whether the instance still needs its first
+// system administrator and whether anyone may register a household.
+type SetupStatus struct {
+	SetupRequired    bool `json:"setup_required"`
+	RegistrationOpen bool `json:"registration_open"`
+}
+
+// HouseholdSummary is a household with its size, as system administrators
+// see it.
+type HouseholdSummary struct {
+	Household
+	MemberCount int `json:"member_count" db:"member_count"`
+	ChoreCount  int `json:"chore_count" db:"chore_count"`
+}
+
+// ImpersonateRequest picks the household to act in when impersonating; by
+// default it is the user's current one.
+type ImpersonateRequest struct {
+	HouseholdID *int `json:"household_id"`
+}
+
+// ImpersonationResponse is an access token to act as another user with. It
+// can't be refreshed.
+type ImpersonationResponse struct {
+	Token          string `json:"token"`
+	ExpiresIn      int    `json:"expires_in"`
+	User           User   `json:"user"`
+	ImpersonatorID int    `json:"impersonator_id"`
+}
+
+// PasswordResetLink is a password reset issued by a system administrator,
+// for passing on when the user can't receive email.
+type PasswordResetLink struct {
+	Link      string    `json:"link"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreatedHousehold is a new household with the invitation for its admin.
+type CreatedHousehold struct {
+	Household  *Household  `json:"household"`
+	Invitation *Invitation `json:"invitation"`
 }
 
 type LoginRequest struct {
@@ -185,8 +626,201 @@ type JoinHouseholdRequest struct {
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in"` // access token lifetime in seconds
+	User         User   `json:"user"`
+	// RecoveryCodes are returned once, when a login enrolled the user in
+	// two-factor authentication
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// TwoFactorChallengeResponse is returned by login instead of tokens when a
+// second factor is needed. With SetupRequired the user has to enroll first.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	SetupRequired     bool   `json:"two_factor_setup_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code is a TOTP code or a recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+type TwoFactorSetupRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// TwoFactorSetup is a new TOTP secret to add to an authenticator app, both
+// plain and as an otpauth:// URI for QR codes.
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+	Required          bool       `json:"required"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+// CreateProfileRequest creates an email-less worker profile that logs in
+// with a PIN on household devices.
+type CreateProfileRequest struct {
+	Name string `json:"name" binding:"required"`
+	PIN  string `json:"pin" binding:"required"`
+}
+
+// UpdateMemberRequest changes a household member's name or role; fields
+// left out stay as they are.
+type UpdateMemberRequest struct {
+	Name *string `json:"name"`
+	Role *Role   `json:"role"`
+}
+
+// CreateInvitationRequest creates an invitation. The role defaults to
+// worker and the expiry to INVITE_TTL; invitations bound to an email address
+// are mailed there and work once.
+type CreateInvitationRequest struct {
+	Role          Role    `json:"role"`
+	Email         *string `json:"email" binding:"omitempty,email"`
+	MaxUses       *int    `json:"max_uses"`
+	ExpiresInDays *int    `json:"expires_in_days"`
+}
+
+// AcceptInvitationRequest joins another household with an existing account.
+type AcceptInvitationRequest struct {
+	InviteCode string `json:"invite_code" binding:"required"`
+}
+
+type SwitchHouseholdRequest struct {
+	HouseholdID int `json:"household_id" binding:"required"`
+}
+
+type SetPINRequest struct {
+	PIN string `json:"pin" binding:"required"`
+}
+
+type RegisterDeviceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// DeviceRegistration is a newly registered device with its token, which is
+// shown only once.
+type DeviceRegistration struct {
+	Device *HouseholdDevice `json:"device"`
+	Token  string           `json:"token"`
+}
+
+// DeviceMember is how a household device lists a member to pick from.
+type DeviceMember struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Role   Role   `json:"role"`
+	HasPIN bool   `json:"has_pin"`
+}
+
+type PINLoginRequest struct {
+	UserID int    `json:"user_id" binding:"required"`
+	PIN    string `json:"pin" binding:"required"`
+}
+
+// PINLoginResponse carries a limited access token for a PIN login. There is
+// no refresh token; the member logs in with their PIN again.
+type PINLoginResponse struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expires_in"` // seconds
+	User      User   `json:"user"`
+}
+
+// OIDCProvider is a configured OpenID Connect provider users can log in
+// with.
+type OIDCProvider struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OIDCAuthorization starts an OpenID Connect login: the client sends the
+// user to AuthorizationURL and, once redirected back, posts the code and
+// state.
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+	ExpiresIn        int    `json:"expires_in"` // seconds
+}
+
+type OIDCCallbackRequest struct {
+	State string `json:"state" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
+
+// CreateAPITokenRequest creates a personal access token. Without
+// ExpiresInDays the token doesn't expire.
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays *int     `json:"expires_in_days"`
+}
+
+// CreatedAPIToken is a new personal access token with its secret, which is
+// shown only once.
+type CreatedAPIToken struct {
+	APIToken *APIToken `json:"api_token"`
+	Token    string    `json:"token"`
+}
+
+// RolePermissions is one role's row of a household's permission matrix.
+// Customized is set when the household changed the role's defaults.
+type RolePermissions struct {
+	Role        Role         `json:"role"`
+	Permissions []Permission `json:"permissions"`
+	Editable    bool         `json:"editable"`
+	Customized  bool         `json:"customized"`
+}
+
+// PermissionMatrix lists every permission and what each role has.
+type PermissionMatrix struct {
+	Permissions []Permission      `json:"permissions"`
+	Roles       []RolePermissions `json:"roles"`
+}
+
+// UpdateRolePermissionsRequest replaces a role's permissions.
+type UpdateRolePermissionsRequest struct {
+	Permissions []Permission `json:"permissions" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
 }
 
 type CreateChoreRequest struct {
@@ -204,6 +838,21 @@ type CreateChoreRequest struct {
 	DueDate         string   `json:"due_date" binding:"required"`
 }
 
+// UpdateChoreRequest is a partial update; nil fields are left unchanged and
+// empty strings clear the optional text fields.
+type UpdateChoreRequest struct {
+	Title          *string   `json:"title"`
+	Description    *string   `json:"description"`
+	Value          *string   `json:"value"`
+	Frequency      *string   `json:"frequency"`
+	Category       *string   `json:"category"`
+	Priority       *Priority `json:"priority"`
+	AutoApprove    *bool     `json:"auto_approve"`
+	ProofRequired  *bool     `json:"proof_required"`
+	LatePenaltyPct *string   `json:"late_penalty_pct"`
+	ExpireDays     *int      `json:"expire_days"`
+}
+
 type UpdateProgressRequest struct {
 	PercentComplete string `json:"percent_complete" binding:"required"`
 }
@@ -213,8 +862,20 @@ type CompleteChoreRequest struct {
 	ProofImage      *string `json:"proof_image,omitempty"`
 }
 
+// ProofImage describes the proof photo attached to an assignment.
+type ProofImage struct {
+	AssignmentID int    `json:"assignment_id"`
+	URL          string `json:"url"`
+	ContentType  string `json:"content_type"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Size         int    `json:"size"`
+}
+
 type ApprovalRequest struct {
 	ApprovalNotes *string `json:"approval_notes"`
+	// Amount overrides the computed payout when approving
+	Amount *string `json:"amount"`
 }
 
 type CreateRewardRequest struct {
@@ -223,6 +884,29 @@ type CreateRewardRequest struct {
 	Cost        string  `json:"cost" binding:"required"`
 }
 
+// UpdateRewardRequest is a partial update; nil fields are left unchanged.
+type UpdateRewardRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Cost        *string `json:"cost"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// UpdateHouseholdSettingsRequest is a partial update of household settings.
+type UpdateHouseholdSettingsRequest struct {
+	AllowNegativeBalance *bool   `json:"allow_negative_balance"`
+	ScheduleDaysAhead    *int    `json:"schedule_days_ahead"`
+	RequireTwoFactor     *bool   `json:"require_two_factor"`
+	Currency             *string `json:"currency"`
+	// PointsLabel switches the household to points; an empty label
+	// switches it back to its currency
+	PointsLabel   *string `json:"points_label"`
+	DecimalPlaces *int    `json:"decimal_places"`
+	Timezone      *string `json:"timezone"`
+	WeekStart     *int    `json:"week_start"`
+	Locale        *string `json:"locale"`
+}
+
 type LedgerAdjustmentRequest struct {
 	UserID      int     `json:"user_id" binding:"required"`
 	Amount      string  `json:"amount" binding:"required"`
@@ -234,6 +918,15 @@ type UserBalance struct {
 	Balance decimal.Decimal `json:"balance"`
 }
 
+// BalanceSummary splits a balance into the amount held by pending
+// redemptions and what is still available to spend.
+type BalanceSummary struct {
+	UserID    int             `json:"user_id"`
+	Balance   decimal.Decimal `json:"balance"`
+	Held      decimal.Decimal `json:"held"`
+	Available decimal.Decimal `json:"available"`
+}
+
 // Response helpers
 type APIResponse struct {
 	Success bool        `json:"success"`
@@ -250,11 +943,15 @@ type PaginatedResponse struct {
 }
 
 // Filter types for queries
+// ChoreFilters narrows chore listings. Status, AssignedTo and the date range
+// match chores that have at least one assignment with that status, assignee
+// or due date.
 type ChoreFilters struct {
 	Status     *AssignmentStatus
 	Category   *string
 	Priority   *Priority
 	CreatedBy  *int
+	AssignedTo *int
 	DateFrom   *time.Time
 	DateTo     *time.Time
 	Limit      int
@@ -266,6 +963,9 @@ type AssignmentFilters struct {
 	ChoreID    *int
 	DueBefore  *time.Time
 	DueAfter   *time.Time
+	// DueWithin narrows DueAfter and DueBefore to the household's current
+	// day, week or month; services resolve it before reaching the store
+	DueWithin  *Period
 	Completed  *bool
 	Approved   *bool
 	Limit      int
@@ -280,11 +980,66 @@ type LedgerFilters struct {
 	Offset     int
 }
 
+type RedemptionFilters struct {
+	Status   *RedemptionStatus
+	RewardID *int
+	Limit    int
+	Offset   int
+}
+
 type AuditFilters struct {
+	// HouseholdID narrows instance-wide listings to one household
+	HouseholdID *int
 	Action     *string
 	UserID     *int
 	DateFrom   *time.Time
 	DateTo     *time.Time
 	Limit      int
 	Offset     int
+}
+
+// ReportFilters selects the range of a report and the periods it is
+// bucketed by.
+type ReportFilters struct {
+	GroupBy  Period
+	DateFrom *time.Time
+	DateTo   *time.Time
+	UserID   *int
+}
+
+// ChoreReport counts assignments by the household period they were due in.
+type ChoreReport struct {
+	GroupBy  Period               `json:"group_by"`
+	Timezone string               `json:"timezone"`
+	Buckets  []*ChoreReportBucket `json:"buckets"`
+}
+
+type ChoreReportBucket struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Completed counts assignments completed or approved, Missed those
+	// that expired and Open the rest
+	Completed int `json:"completed"`
+	Missed    int `json:"missed"`
+	Open      int `json:"open"`
+}
+
+// EarningsReport sums members' ledger entries by household period.
+type EarningsReport struct {
+	GroupBy     Period                  `json:"group_by"`
+	Timezone    string                  `json:"timezone"`
+	Currency    string                  `json:"currency"`
+	PointsLabel *string                 `json:"points_label,omitempty"`
+	Buckets     []*EarningsReportBucket `json:"buckets"`
+}
+
+// EarningsReportBucket is one member's entries in one period. Spent is
+// positive, Adjusted signed.
+type EarningsReportBucket struct {
+	Start    time.Time       `json:"start"`
+	End      time.Time       `json:"end"`
+	UserID   int             `json:"user_id"`
+	Earned   decimal.Decimal `json:"earned"`
+	Spent    decimal.Decimal `json:"spent"`
+	Adjusted decimal.Decimal `json:"adjusted"`
 }
\ No newline at end of file
@@ -0,0 +1,204 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/oidc/oidctest"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "choreme"
+	testClientSecret = "client-secret"
+	testRedirectURL  = "http://localhost:8080/auth/oidc/callback"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Issuer) {
+	issuer := oidctest.NewIssuer(t, testClientID)
+	provider := New(config.OIDCProviderConfig{
+		Name:         "test",
+		Issuer:       issuer.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		Scopes:       []string{"openid", "email"},
+	}, testRedirectURL)
+	return provider, issuer
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// The example of RFC 7636, appendix B
+	got := CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("CodeChallenge = %q, want %q", got, want)
+	}
+}
+
+func TestAuthenticateWithPKCE(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+	ctx := context.Background()
+	const verifier = "a-verifier-long-enough-for-the-provider-0123456789"
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authURL)
+	if got := u.Query().Get("code_challenge"); got != CodeChallenge(verifier) {
+		t.Errorf("code_challenge = %q, want %q", got, CodeChallenge(verifier))
+	}
+
+	code := issuer.Authorize(authURL, issuer.Claims("user-1", ""))
+	claims, err := provider.Authenticate(ctx, code, verifier, "nonce")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if claims.Subject != "user-1" {
+		t.Errorf("subject = %q, want user-1", claims.Subject)
+	}
+
+	// The provider refuses a code redeemed with another verifier
+	code = issuer.Authorize(authURL, issuer.Claims("user-1", ""))
+	if _, err := provider.Authenticate(ctx, code, "another-verifier", "nonce"); err == nil {
+		t.Error("Authenticate succeeded with the wrong verifier")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+	ctx := context.Background()
+
+	hmac := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = "key-1"
+		raw, err := token.SignedString([]byte(testClientSecret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+	none := func(claims jwt.MapClaims) string {
+		raw, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	tests := []struct {
+		name   string
+		modify func(jwt.MapClaims)
+		sign   func(jwt.MapClaims) string
+		valid  bool
+	}{
+		{name: "valid", valid: true},
+		{name: "wrong issuer", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "wrong audience", modify: func(c jwt.MapClaims) { c["aud"] = "another-client" }},
+		{name: "expired", modify: func(c jwt.MapClaims) {
+			c["iat"] = time.Now().Add(-2 * time.Hour).Unix()
+			c["exp"] = time.Now().Add(-time.Hour).Unix()
+		}},
+		{name: "no expiry", modify: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "wrong nonce", modify: func(c jwt.MapClaims) { c["nonce"] = "another-nonce" }},
+		{name: "no subject", modify: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{name: "HS256 with the client secret", sign: hmac},
+		{name: "alg none", sign: none},
+		{name: "several audiences without azp", modify: func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "another-client"}
+		}},
+		{name: "several audiences, azp another party", modify: func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "another-client"}
+			c["azp"] = "another-client"
+		}},
+		{name: "several audiences, azp this client", valid: true, modify: func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "another-client"}
+			c["azp"] = testClientID
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.Claims("user-1", "nonce")
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			sign := issuer.Sign
+			if tt.sign != nil {
+				sign = tt.sign
+			}
+
+			_, err := provider.Verify(ctx, sign(claims), "nonce")
+			if tt.valid && err != nil {
+				t.Errorf("Verify failed: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+	ctx := context.Background()
+	verify := func() error {
+		_, err := provider.Verify(ctx, issuer.Sign(issuer.Claims("user-1", "nonce")), "nonce")
+		return err
+	}
+	// backdate makes the keys old enough to be fetched again
+	backdate := func() {
+		provider.mu.Lock()
+		provider.keys.fetchedAt = provider.keys.fetchedAt.Add(-keyRefreshInterval)
+		provider.mu.Unlock()
+	}
+
+	if err := verify(); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if err := verify(); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if got := issuer.KeyFetches(); got != 1 {
+		t.Fatalf("keys fetched %d times, want once", got)
+	}
+
+	// Right after a fetch an unknown key is refused without fetching again
+	issuer.RotateKey()
+	if err := verify(); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify = %v, want ErrInvalidToken", err)
+	}
+	if got := issuer.KeyFetches(); got != 1 {
+		t.Errorf("keys fetched %d times within the refresh interval, want once", got)
+	}
+
+	// Once the interval has passed the rotated key is fetched
+	backdate()
+	if err := verify(); err != nil {
+		t.Fatalf("Verify after rotation failed: %v", err)
+	}
+	if got := issuer.KeyFetches(); got != 2 {
+		t.Errorf("keys fetched %d times, want twice", got)
+	}
+
+	// Tokens signed with keys the provider never published fetch the keys
+	// at most once per interval
+	raw := issuer.SignWithKeyID(issuer.Claims("user-1", "nonce"), "unknown")
+	for n := 0; n < 3; n++ {
+		if _, err := provider.Verify(ctx, raw, "nonce"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify = %v, want ErrInvalidToken", err)
+		}
+	}
+	if got := issuer.KeyFetches(); got != 2 {
+		t.Errorf("keys fetched %d times, want twice", got)
+	}
+	backdate()
+	if _, err := provider.Verify(ctx, raw, "nonce"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify = %v, want ErrInvalidToken", err)
+	}
+	if got := issuer.KeyFetches(); got != 3 {
+		t.Errorf("keys fetched %d times, want three times", got)
+	}
+}
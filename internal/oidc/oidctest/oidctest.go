@@ -0,0 +1,215 @@
+// Package oidctest runs a fake OpenID Connect provider for tests. It serves
+// discovery, a JWKS document and a token endpoint that checks PKCE, and
+// signs ID tokens with an RSA key it can rotate.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuer is a fake provider. Its URL is the issuer identifier.
+type Issuer struct {
+	*httptest.Server
+	ClientID string
+
+	t          *testing.T
+	mu         sync.Mutex
+	key        *rsa.PrivateKey
+	kid        string
+	keys       int
+	keyFetches int
+	codes      map[string]authorization
+}
+
+// authorization is a code the token endpoint can redeem.
+type authorization struct {
+	challenge   string
+	redirectURI string
+	claims      jwt.MapClaims
+}
+
+// NewIssuer starts a provider for clientID. It is closed when the test
+// ends.
+func NewIssuer(t *testing.T, clientID string) *Issuer {
+	t.Helper()
+	i := &Issuer{
+		ClientID: clientID,
+		t:        t,
+		codes:    make(map[string]authorization),
+	}
+	i.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", i.discovery)
+	mux.HandleFunc("/jwks", i.jwks)
+	mux.HandleFunc("/token", i.token)
+	i.Server = httptest.NewServer(mux)
+	t.Cleanup(i.Close)
+	return i
+}
+
+// RotateKey replaces the signing key with a new one under a new key ID.
+// The JWKS document only lists the new key from then on.
+func (i *Issuer) RotateKey() {
+	i.t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		i.t.Fatalf("failed to generate key: %v", err)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.keys++
+	i.key, i.kid = key, fmt.Sprintf("key-%d", i.keys)
+}
+
+// KeyFetches counts the requests for the JWKS document so far.
+func (i *Issuer) KeyFetches() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.keyFetches
+}
+
+// Claims returns valid ID token claims for subject and nonce, issued now
+// for an hour.
+func (i *Issuer) Claims(subject, nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   i.URL,
+		"aud":   i.ClientID,
+		"sub":   subject,
+		"nonce": nonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+}
+
+// Sign signs claims with the current key.
+func (i *Issuer) Sign(claims jwt.MapClaims) string {
+	i.t.Helper()
+	i.mu.Lock()
+	kid := i.kid
+	i.mu.Unlock()
+	return i.SignWithKeyID(claims, kid)
+}
+
+// SignWithKeyID signs claims with the current key but names kid as the
+// key in the token's header.
+func (i *Issuer) SignWithKeyID(claims jwt.MapClaims, kid string) string {
+	i.t.Helper()
+	i.mu.Lock()
+	key := i.key
+	i.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(key)
+	if err != nil {
+		i.t.Fatalf("failed to sign ID token: %v", err)
+	}
+	return raw
+}
+
+// Authorize plays the user logging in at authURL, a URL from
+// Provider.AuthCodeURL, and returns the code the provider would send them
+// back with. The ID token for the code gets claims with the request's
+// nonce.
+func (i *Issuer) Authorize(authURL string, claims jwt.MapClaims) string {
+	i.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		i.t.Fatalf("invalid authorization URL: %v", err)
+	}
+	query := u.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != i.ClientID {
+		i.t.Fatalf("authorization URL %s is not a code request for %s", authURL, i.ClientID)
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		i.t.Fatalf("authorization URL %s has no S256 code challenge", authURL)
+	}
+	claims["nonce"] = query.Get("nonce")
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	code := base64.RawURLEncoding.EncodeToString(b)
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.codes[code] = authorization{
+		challenge:   query.Get("code_challenge"),
+		redirectURI: query.Get("redirect_uri"),
+		claims:      claims,
+	}
+	return code
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 i.URL,
+		"authorization_endpoint": i.URL + "/authorize",
+		"token_endpoint":         i.URL + "/token",
+		"jwks_uri":               i.URL + "/jwks",
+	})
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	i.keyFetches++
+	key, kid := i.key, i.kid
+	i.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": kid,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+}
+
+// token redeems a code once, given the verifier whose S256 challenge the
+// authorization request carried.
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	i.mu.Lock()
+	code := r.PostForm.Get("code")
+	authz, ok := i.codes[code]
+	delete(i.codes, code)
+	i.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("client_id") != i.ClientID || r.PostForm.Get("redirect_uri") != authz.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != authz.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"token_type": "Bearer",
+		"id_token":   i.Sign(authz.claims),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/choreme/choreme/internal/config"
+)
+
+// Memory keeps buckets in process memory. Limits apply per instance.
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket)}
+}
+
+func (m *Memory) Allow(ctx context.Context, key string, rate config.Rate) (bool, time.Duration, error) {
+	if rate.Count <= 0 {
+		return true, 0, nil
+	}
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Count), updated: now}
+		m.buckets[key] = b
+	}
+	tokens, allowed, wait := take(b.tokens, b.updated, now, rate)
+	if allowed {
+		b.tokens, b.updated = tokens, now
+	}
+	return allowed, wait, nil
+}
+
+func (m *Memory) Cleanup(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var removed int64
+	for key, b := range m.buckets {
+		if b.updated.Before(before) {
+			delete(m.buckets, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,47 @@
+// Package ratelimit throttles requests with token buckets. Every key, such
+// as a client IP or an email address, has a bucket holding up to Count
+// tokens that refill evenly over Period; each allowed request takes one.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/store"
+)
+
+// Limiter decides whether a request may proceed.
+type Limiter interface {
+	// Allow takes a token from key's bucket. When the bucket is empty the
+	// request is refused and retryAfter tells when a token is due.
+	Allow(ctx context.Context, key string, rate config.Rate) (allowed bool, retryAfter time.Duration, err error)
+	// Cleanup forgets buckets last used before the given time and returns
+	// how many it removed. A bucket idle for its whole period is full, so
+	// forgetting it changes nothing.
+	Cleanup(ctx context.Context, before time.Time) (int64, error)
+}
+
+// New returns the limiter selected by cfg.Backend: the store-backed one
+// when limits must hold across instances, the in-memory one otherwise.
+func New(cfg config.RateLimitConfig, st store.Store) Limiter {
+	if cfg.Backend == "store" {
+		return NewStore(st)
+	}
+	return NewMemory()
+}
+
+// take refills a bucket holding tokens since last and takes one token from
+// it. It returns the tokens left, whether one was taken and, if not, how
+// long until one is.
+func take(tokens float64, last, now time.Time, rate config.Rate) (float64, bool, time.Duration) {
+	perSecond := float64(rate.Count) / rate.Period.Seconds()
+	if elapsed := now.Sub(last).Seconds(); elapsed > 0 {
+		tokens = math.Min(float64(rate.Count), tokens+elapsed*perSecond)
+	}
+	if tokens >= 1 {
+		return tokens - 1, true, 0
+	}
+	return tokens, false, time.Duration((1 - tokens) / perSecond * float64(time.Second))
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+// Store keeps buckets in the database so every instance sharing it
+// enforces the same limits.
+type Store struct {
+	store store.Store
+}
+
+func NewStore(st store.Store) *Store {
+	return &Store{store: st}
+}
+
+func (l *Store) Allow(ctx context.Context, key string, rate config.Rate) (bool, time.Duration, error) {
+	if rate.Count <= 0 {
+		return true, 0, nil
+	}
+	now := time.Now()
+
+	var allowed bool
+	var wait time.Duration
+	err := l.store.WithTx(ctx, func(tx store.Store) error {
+		b, err := tx.GetRateLimitBucketForUpdate(ctx, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Two instances creating the same bucket at once may both take
+			// a token from a full bucket; the upsert keeps either result.
+			b = &model.RateLimitBucket{Key: key, Tokens: float64(rate.Count), UpdatedAt: now}
+		} else if err != nil {
+			return fmt.Errorf("failed to get rate limit bucket: %w", err)
+		}
+
+		var tokens float64
+		tokens, allowed, wait = take(b.Tokens, b.UpdatedAt, now, rate)
+		if !allowed {
+			return nil
+		}
+		b.Tokens, b.UpdatedAt = tokens, now
+		return tx.SaveRateLimitBucket(ctx, b)
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return allowed, wait, nil
+}
+
+func (l *Store) Cleanup(ctx context.Context, before time.Time) (int64, error) {
+	return l.store.DeleteRateLimitBucketsBefore(ctx, before)
+}
@@ -0,0 +1,173 @@
+// Package recurrence parses chore frequencies into recurrence rules.
+//
+// A frequency is one of:
+//
+//	once                  (or empty) a one-time chore
+//	daily
+//	weekly                on the weekday of the first occurrence
+//	weekly:mon,wed,fri    on the listed weekdays
+//	monthly               on the day of month of the first occurrence,
+//	                      falling back to the last day of shorter months
+//	every 3 days          also every_3_days, every 2 weeks, every 6 months
+//	RRULE:FREQ=...        an RFC 5545 RRULE; the RRULE: prefix is optional.
+//	                      FREQ must be DAILY or longer, and BYHOUR,
+//	                      BYMINUTE and BYSECOND are not supported
+//
+// The first occurrence, and so the time of day of every occurrence, comes
+// from the start time passed to Parse rather than from the frequency string.
+// Occurrences are in the start's location and keep its wall-clock time of
+// day across daylight saving changes.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+var ErrInvalidFrequency = errors.New("invalid frequency")
+
+var everyPattern = regexp.MustCompile(`^every[ _]+(\d+)[ _]+(day|week|month)s?$`)
+
+var weekdays = map[string]rrule.Weekday{
+	"mo": rrule.MO, "mon": rrule.MO, "monday": rrule.MO,
+	"tu": rrule.TU, "tue": rrule.TU, "tuesday": rrule.TU,
+	"we": rrule.WE, "wed": rrule.WE, "wednesday": rrule.WE,
+	"th": rrule.TH, "thu": rrule.TH, "thursday": rrule.TH,
+	"fr": rrule.FR, "fri": rrule.FR, "friday": rrule.FR,
+	"sa": rrule.SA, "sat": rrule.SA, "saturday": rrule.SA,
+	"su": rrule.SU, "sun": rrule.SU, "sunday": rrule.SU,
+}
+
+// Rule generates the occurrences of a recurring chore.
+type Rule struct {
+	rule *rrule.RRule
+}
+
+// Parse builds the rule for frequency with its first occurrence at start.
+// It returns a nil rule for one-time chores.
+func Parse(frequency string, start time.Time) (*Rule, error) {
+	option, err := parseOption(frequency)
+	if err != nil || option == nil {
+		return nil, err
+	}
+
+	option.Dtstart = start.Truncate(time.Second)
+	if option.Freq == rrule.MONTHLY && len(option.Bymonthday) == 0 && len(option.Byweekday) == 0 &&
+		len(option.Byyearday) == 0 && len(option.Bysetpos) == 0 {
+		// Keep the start's day of month, or the month's last day when the
+		// month is shorter
+		if day := option.Dtstart.Day(); day > 28 {
+			for d := 28; d <= day; d++ {
+				option.Bymonthday = append(option.Bymonthday, d)
+			}
+			option.Bysetpos = []int{-1}
+		}
+	}
+
+	rule, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFrequency, err)
+	}
+	return &Rule{rule: rule}, nil
+}
+
+// Validate reports whether frequency can be parsed.
+func Validate(frequency string) error {
+	_, err := parseOption(frequency)
+	return err
+}
+
+// Between returns the occurrences after after and up to and including
+// before, in order. A positive limit stops it after that many occurrences.
+func (r *Rule) Between(after, before time.Time, limit int) []time.Time {
+	if !before.After(after) {
+		return nil
+	}
+	var occurrences []time.Time
+	next := r.rule.Iterator()
+	for {
+		t, ok := next()
+		if !ok || t.After(before) {
+			return occurrences
+		}
+		if !t.After(after) {
+			continue
+		}
+		occurrences = append(occurrences, t)
+		if limit > 0 && len(occurrences) == limit {
+			return occurrences
+		}
+	}
+}
+
+// After returns the first occurrence after t, or the zero time when the
+// rule has ended.
+func (r *Rule) After(t time.Time) time.Time {
+	return r.rule.After(t, false)
+}
+
+func parseOption(frequency string) (*rrule.ROption, error) {
+	f := strings.ToLower(strings.TrimSpace(frequency))
+	switch f {
+	case "", "once", "one-time", "none":
+		return nil, nil
+	case "daily":
+		return &rrule.ROption{Freq: rrule.DAILY}, nil
+	case "weekly":
+		return &rrule.ROption{Freq: rrule.WEEKLY}, nil
+	case "monthly":
+		return &rrule.ROption{Freq: rrule.MONTHLY}, nil
+	}
+
+	if days, ok := strings.CutPrefix(f, "weekly:"); ok {
+		option := &rrule.ROption{Freq: rrule.WEEKLY}
+		for _, name := range strings.Split(days, ",") {
+			day, ok := weekdays[strings.TrimSpace(name)]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown weekday %q", ErrInvalidFrequency, strings.TrimSpace(name))
+			}
+			option.Byweekday = append(option.Byweekday, day)
+		}
+		return option, nil
+	}
+
+	if m := everyPattern.FindStringSubmatch(f); m != nil {
+		interval, err := strconv.Atoi(m[1])
+		if err != nil || interval < 1 {
+			return nil, fmt.Errorf("%w: interval must be at least 1", ErrInvalidFrequency)
+		}
+		option := &rrule.ROption{Interval: interval}
+		switch m[2] {
+		case "day":
+			option.Freq = rrule.DAILY
+		case "week":
+			option.Freq = rrule.WEEKLY
+		case "month":
+			option.Freq = rrule.MONTHLY
+		}
+		return option, nil
+	}
+
+	if strings.HasPrefix(f, "rrule:") || strings.HasPrefix(f, "freq=") {
+		option, err := rrule.StrToROption(strings.ToUpper(strings.TrimSpace(frequency)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFrequency, err)
+		}
+		// Chores recur at most daily, at the time of day of their start
+		if option.Freq > rrule.DAILY {
+			return nil, fmt.Errorf("%w: FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY", ErrInvalidFrequency)
+		}
+		if len(option.Byhour) > 0 || len(option.Byminute) > 0 || len(option.Bysecond) > 0 {
+			return nil, fmt.Errorf("%w: BYHOUR, BYMINUTE and BYSECOND are not supported", ErrInvalidFrequency)
+		}
+		return option, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrInvalidFrequency, frequency)
+}
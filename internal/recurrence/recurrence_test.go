@@ -0,0 +1,58 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateRejectsSubDailyRules(t *testing.T) {
+	for _, frequency := range []string{
+		"RRULE:FREQ=SECONDLY",
+		"RRULE:FREQ=MINUTELY;INTERVAL=5",
+		"FREQ=HOURLY",
+		"RRULE:FREQ=DAILY;BYHOUR=8,20",
+		"RRULE:FREQ=DAILY;BYMINUTE=0,30",
+		"RRULE:FREQ=WEEKLY;BYSECOND=1",
+	} {
+		if err := Validate(frequency); !errors.Is(err, ErrInvalidFrequency) {
+			t.Errorf("Validate(%q) = %v, want ErrInvalidFrequency", frequency, err)
+		}
+	}
+	for _, frequency := range []string{
+		"RRULE:FREQ=DAILY",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+		"FREQ=MONTHLY;BYMONTHDAY=1,15",
+		"RRULE:FREQ=YEARLY",
+	} {
+		if err := Validate(frequency); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", frequency, err)
+		}
+	}
+}
+
+func TestBetweenLimit(t *testing.T) {
+	start := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	rule, err := Parse("daily", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := rule.Between(start, start.AddDate(0, 0, 10), 0)
+	if len(all) != 10 {
+		t.Fatalf("got %d occurrences, want 10", len(all))
+	}
+	if !all[0].Equal(start.AddDate(0, 0, 1)) {
+		t.Errorf("first occurrence = %v, want the day after the start", all[0])
+	}
+
+	limited := rule.Between(start, start.AddDate(0, 0, 10), 3)
+	if len(limited) != 3 {
+		t.Fatalf("got %d occurrences, want 3", len(limited))
+	}
+	for i := range limited {
+		if !limited[i].Equal(all[i]) {
+			t.Errorf("occurrence %d = %v, want %v", i, limited[i], all[i])
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/choreme/choreme/internal/mailer"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+// AdminService runs the instance for system administrators: the households
+// on it, their suspension, the instance-wide audit log and support for
+// users. Callers make sure the actor is a system administrator; the methods
+// check it again.
+type AdminService struct {
+	store    store.Store
+	audit    *AuditService
+	sessions *SessionCache
+	auth     *AuthService
+}
+
+func NewAdminService(store store.Store, audit *AuditService, sessions *SessionCache, auth *AuthService) *AdminService {
+	return &AdminService{
+		store:    store,
+		audit:    audit,
+		sessions: sessions,
+		auth:     auth,
+	}
+}
+
+// GetHouseholds lists every household on the instance with its member and
+// chore counts.
+func (s *AdminService) GetHouseholds(ctx context.Context, actor Actor) ([]*model.HouseholdSummary, error) {
+	if actor.Role != model.RoleSystemAdmin {
+		return nil, ErrForbidden
+	}
+	households, err := s.store.GetHouseholds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get households: %w", err)
+	}
+	return households, nil
+}
+
+// GetHouseholdMembers lists the members of any household.
+func (s *AdminService) GetHouseholdMembers(ctx context.Context, actor Actor, householdID int) ([]*model.User, error) {
+	if actor.Role != model.RoleSystemAdmin {
+		return nil, ErrForbidden
+	}
+	if _, err := s.store.GetHouseholdByID(ctx, householdID); err != nil {
+		return nil, notFound(err, "household")
+	}
+	users, err := s.store.GetUsersByHousehold(ctx, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %w", err)
+	}
+	return users, nil
+}
+
+// CreateHousehold opens a household without members, along with an
+// invitation for its admin.
+func (s *AdminService) CreateHousehold(ctx context.Context, actor Actor, req *model.CreateHouseholdRequest) (*model.CreatedHousehold, error) {
+	if actor.Role != model.RoleSystemAdmin {
+		return nil, ErrForbidden
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, invalidf("name can't be empty")
+	}
+
+	household := model.NewHousehold(name, time.Now())
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateHousehold(ctx, household); err != nil {
+			return fmt.Errorf("failed to create household: %w", err)
+		}
+		return s.audit.Record(ctx, tx, household.ID, actor.UserID, "household_created", map[string]interface{}{
+			"name": household.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.CreateAdminInvitation(ctx, actor, household.ID, req.AdminEmail)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreatedHousehold{Household: household, Invitation: invitation}, nil
+}
+
+// CreateAdminInvitation invites one person to a household as its admin,
+// mailing the invitation when an email address is given.
+func (s *AdminService) CreateAdminInvitation(ctx context.Context, actor Actor, householdID int, email *string) (*model.Invitation, error) {
+	if actor.Role != model.RoleSystemAdmin {
+		return nil, ErrForbidden
+	}
+	if _, err := s.store.GetHouseholdByID(ctx, householdID); err != nil {
+		return nil, notFound(err, "household")
+	}
+	// The system administrator invites as if they ran the household
+	inviter := Actor{
+		UserID:      actor.UserID,
+		HouseholdID: householdID,
+		Role:        model.RoleSystemAdmin,
+		Permissions: allPermissions(),
+	}
+	one := 1
+	return s.auth.CreateInvitation(ctx, inviter, &model.CreateInvitationRequest{
+		Role:    model.RoleAdmin,
+		Email:   email,
+		MaxUses: &one,
+	})
+}
+
+// SuspendHousehold stops a household's members from using it: they are
+// logged out, and logins land in their other households. Nothing is deleted.
+func (s *AdminService) SuspendHousehold(ctx context.Context, actor Actor, householdID int) (*model.Household, error) {
+	if actor.Role != model.RoleSystemAdmin {
+		return nil, ErrForbidden
+	}
+	if householdID == actor.HouseholdID {
+		return nil, invalidf("you can't suspend your own household")
+	}
+
+	var household *model.Household
+	var members []*model.User
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		household, err = tx.GetHouseholdByID(ctx, householdID)
+		if err != nil {
+			return notFound(err, "household")
+		}
+		if household.SuspendedAt != nil {
+			return fmt.Errorf("%w: household is already suspended", ErrConflict)
+		}
+
+		now := time.Now()
+		household.SuspendedAt = &now
+		if err := tx.SuspendHousehold(ctx, household.ID, household.SuspendedAt); err != nil {
+			return fmt.Errorf("failed to suspend household: %w", err)
+		}
+		members, err = tx.GetUsersByHousehold(ctx, household.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get members: %w", err)
+		}
+		for _, member := range members {
+			if err := tx.BumpSessionEpoch(ctx, member.ID); err != nil {
+				return fmt.Errorf("failed to end sessions: %w", err)
+			}
+		}
+		return s.audit.Record(ctx, tx, household.ID, actor.UserID, "household_suspended", map[string]interface{}{
+			"household_id": household.ID,
+			"name":         household.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		s.sessions.Invalidate(member.ID)
+	}
+	return household, nil
+}
+
+// UnsuspendHousehold lifts a household's suspension.
+func (s *AdminService) UnsuspendHousehold(ctx context.Context, actor Actor, householdID int) (*model.Household, error) {
+	if actor.Role != model.RoleSystemAdmin {
+		return nil, ErrForbidden
+	}
+
+	var household *model.Household
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		household, err = tx.GetHouseholdByID(ctx, householdID)
+		if err != nil {
+			return notFound(err, "household")
+		}
+		if household.SuspendedAt == nil {
+			return fmt.Errorf("%w: household is not suspended", ErrConflict)
+		}
+
+		household.SuspendedAt = nil
+		if err := tx.SuspendHousehold(ctx, household.ID, nil); err != nil {
+			return fmt.Errorf("failed to unsuspend household: %w", err)
+		}
+		return s.audit.Record(ctx, tx, household.ID, actor.UserID, "household_unsuspended", map[string]interface{}{
+			"household_id": household.ID,
+			"name":         household.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// GetAuditLogs returns the audit log of the whole instance, or of one
+// household when the filters name it.
+func (s *AdminService) GetAuditLogs(ctx context.Context, actor Actor, filters model.AuditFilters) ([]*model.AuditLog, error) {
+	if actor.Role != model.RoleSystemAdmin {
+		return nil, ErrForbidden
+	}
+	filters.Limit, filters.Offset = normalizePage(filters.Limit, filters.Offset)
+	logs, err := s.store.GetAuditLogs(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+	return logs, nil
+}
+
+// Impersonate returns the user to act as, as a member of householdID or by
+// default of their current household. System administrators can't be
+// impersonated, whatever their role in the household. It is audited in both
+// the user's and the actor's household.
+func (s *AdminService) Impersonate(ctx context.Context, actor Actor, userID int, householdID *int) (*model.User, error) {
+	if actor.Role != model.RoleSystemAdmin {
+		return nil, ErrForbidden
+	}
+	if userID == actor.UserID {
+		return nil, invalidf("you can't impersonate yourself")
+	}
+
+	var user *model.User
+	var err error
+	if householdID != nil {
+		user, err = s.store.GetMember(ctx, *householdID, userID)
+	} else {
+		user, err = s.store.GetUserByID(ctx, userID)
+	}
+	if err != nil {
+		return nil, notFound(err, "user")
+	}
+	if user.RemovedAt != nil {
+		return nil, fmt.Errorf("user %w", ErrNotFound)
+	}
+	systemAdmin, err := isSystemAdmin(ctx, s.store, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if systemAdmin {
+		return nil, fmt.Errorf("%w: system administrators can't be impersonated", ErrForbidden)
+	}
+	if !user.Active() {
+		return nil, fmt.Errorf("%w: the user is deactivated", ErrConflict)
+	}
+	if err := checkHousehold(ctx, s.store, user.HouseholdID); err != nil {
+		if errors.Is(err, ErrHouseholdSuspended) {
+			return nil, fmt.Errorf("%w: the household is suspended", ErrConflict)
+		}
+		return nil, err
+	}
+
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "user_impersonated", map[string]interface{}{
+			"user_id":         user.ID,
+			"impersonated_by": actor.UserID,
+		}); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "impersonation_started", map[string]interface{}{
+			"user_id":      user.ID,
+			"household_id": user.HouseholdID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// isSystemAdmin reports whether the user is a system administrator of the
+// instance, which they are through their membership of some household.
+func isSystemAdmin(ctx context.Context, st store.Store, userID int) (bool, error) {
+	memberships, err := st.GetMemberships(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get memberships: %w", err)
+	}
+	for _, membership := range memberships {
+		if membership.Role == model.RoleSystemAdmin {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ResetPassword mails a user a password reset link and returns it too, for
+// passing on when the user can't receive email. The password only changes
+// once the link is used.
+func (s *AdminService) ResetPassword(ctx context.Context, actor Actor, userID int) (*model.PasswordResetLink, error) {
+	if actor.Role != model.RoleSystemAdmin {
+		return nil, ErrForbidden
+	}
+	user, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, "user")
+	}
+	if user.Email == "" {
+		return nil, invalidf("the user has no email address to log in with")
+	}
+
+	var raw string
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		raw, err = s.auth.issueUserToken(ctx, tx, user, model.TokenPurposePasswordReset, passwordResetTTL)
+		if err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, user.HouseholdID, actor.UserID, "password_reset_issued", map[string]interface{}{
+			"user_id": user.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reset := &model.PasswordResetLink{
+		Link:      s.auth.link("/reset-password", raw),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+	s.auth.send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your ChoreMe password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"An administrator of ChoreMe started a password reset for your account. To choose a new password, open:\n\n"+
+			"%s\n\n"+
+			"The link works once and expires in an hour.\n",
+			user.Name, reset.Link),
+	})
+	return reset, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/choreme/choreme/internal/model"
+)
+
+func TestImpersonateRefusesSystemAdmins(t *testing.T) {
+	st := newTestStore(t)
+	s := NewAdminService(st, NewAuditService(st), NewSessionCache(time.Minute), newPINTestService(t, st))
+	household, members := newTestHousehold(t, st)
+	ctx := context.Background()
+
+	// Another system administrator, who is a worker in the first household
+	other := model.NewHousehold("Other", time.Now())
+	if err := st.CreateHousehold(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	systemAdmin := addMember(t, st, other, "other", model.RoleSystemAdmin)
+	err := st.SaveMembership(ctx, &model.Membership{
+		HouseholdID: household.ID,
+		UserID:      systemAdmin.ID,
+		Role:        model.RoleWorker,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actor := actorFor(members[model.RoleSystemAdmin])
+
+	tests := []struct {
+		name        string
+		householdID *int
+	}{
+		{"in their current household", nil},
+		{"as system administrator", &other.ID},
+		{"as worker", &household.ID},
+	}
+	for _, tt := range tests {
+		if _, err := s.Impersonate(ctx, actor, systemAdmin.ID, tt.householdID); !errors.Is(err, ErrForbidden) {
+			t.Errorf("impersonating a system administrator %s = %v, want ErrForbidden", tt.name, err)
+		}
+	}
+
+	user, err := s.Impersonate(ctx, actor, members[model.RoleWorker].ID, &household.ID)
+	if err != nil {
+		t.Fatalf("impersonating a worker failed: %v", err)
+	}
+	if user.ID != members[model.RoleWorker].ID {
+		t.Errorf("impersonating user %d, want %d", user.ID, members[model.RoleWorker].ID)
+	}
+}
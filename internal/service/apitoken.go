@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/choreme/choreme/internal/auth"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+const (
+	maxAPITokenDays = 3650
+	// apiTokenPrefixLength is how much of a token is kept in the clear so
+	// users can recognise it.
+	apiTokenPrefixLength = len(auth.APITokenPrefix) + 6
+	// apiTokenTouchInterval limits how often a token's last use is written.
+	apiTokenTouchInterval = time.Minute
+)
+
+// CreateAPIToken creates a personal access token for the actor. The token
+// acts as the actor, limited to its scopes, until it expires or is deleted.
+func (s *AuthService) CreateAPIToken(ctx context.Context, actor Actor, req *model.CreateAPITokenRequest) (*model.CreatedAPIToken, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, invalidf("name must be 1 to 100 characters")
+	}
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		days := *req.ExpiresInDays
+		if days < 1 || days > maxAPITokenDays {
+			return nil, invalidf("expires_in_days must be between 1 and %d", maxAPITokenDays)
+		}
+		t := now.AddDate(0, 0, days)
+		expiresAt = &t
+	}
+
+	raw, err := auth.GenerateAPIToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token := &model.APIToken{
+		UserID:      actor.UserID,
+		HouseholdID: actor.HouseholdID,
+		Name:        name,
+		TokenPrefix: raw[:apiTokenPrefixLength],
+		TokenHash:   auth.HashToken(raw),
+		Scopes:      scopes,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   now,
+	}
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateAPIToken(ctx, token); err != nil {
+			return fmt.Errorf("failed to create API token: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "api_token_created", map[string]interface{}{
+			"token_id": token.ID,
+			"name":     token.Name,
+			"scopes":   token.Scopes,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreatedAPIToken{APIToken: token, Token: raw}, nil
+}
+
+// GetAPITokens lists the actor's personal access tokens.
+func (s *AuthService) GetAPITokens(ctx context.Context, actor Actor) ([]*model.APIToken, error) {
+	tokens, err := s.store.GetAPITokens(ctx, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteAPIToken revokes one of the actor's personal access tokens.
+func (s *AuthService) DeleteAPIToken(ctx context.Context, actor Actor, id int) error {
+	return s.store.WithTx(ctx, func(tx store.Store) error {
+		token, err := tx.GetAPITokenByID(ctx, id)
+		if err != nil {
+			return notFound(err, "API token")
+		}
+		if token.UserID != actor.UserID {
+			return fmt.Errorf("API token %w", ErrNotFound)
+		}
+		if err := tx.DeleteAPIToken(ctx, token.ID); err != nil {
+			return fmt.Errorf("failed to delete API token: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "api_token_deleted", map[string]interface{}{
+			"token_id": token.ID,
+			"name":     token.Name,
+		})
+	})
+}
+
+// AuthenticateAPIToken returns the claims a personal access token
+// authenticates with. They carry the user's current role in the household
+// the token was created in, so changes to it apply at once.
+func (s *AuthService) AuthenticateAPIToken(ctx context.Context, raw string) (*auth.Claims, error) {
+	token, err := s.store.GetAPITokenByHash(ctx, auth.HashToken(raw))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: invalid API token", ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+	now := time.Now()
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return nil, fmt.Errorf("%w: API token expired", ErrUnauthorized)
+	}
+
+	user, err := s.store.GetMember(ctx, token.HouseholdID, token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.Active() {
+		return nil, ErrAccountDeactivated
+	}
+	if err := checkHousehold(ctx, s.store, token.HouseholdID); err != nil {
+		return nil, err
+	}
+	required, err := s.twoFactorRequired(ctx, s.store, user)
+	if err != nil {
+		return nil, err
+	}
+	if required && !user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorSetupRequired
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval {
+		if err := s.store.TouchAPIToken(ctx, token.ID, now); err != nil {
+			return nil, fmt.Errorf("failed to update API token: %w", err)
+		}
+	}
+
+	return &auth.Claims{
+		UserID:       user.ID,
+		HouseholdID:  user.HouseholdID,
+		Role:         user.Role,
+		Email:        user.Email,
+		SessionEpoch: user.SessionEpoch,
+		Scope:        auth.ScopeAPIToken,
+		Scopes:       token.Scopes,
+	}, nil
+}
+
+// normalizeScopes checks requested scopes and drops duplicates.
+func normalizeScopes(requested []string) ([]string, error) {
+	seen := make(map[string]bool, len(requested))
+	var scopes []string
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if !auth.ValidAPITokenScope(scope) {
+			return nil, invalidf("unknown scope %q", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, invalidf("at least one scope is required")
+	}
+	return scopes, nil
+}
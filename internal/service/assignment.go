@@ -2,52 +2,620 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/choreme/choreme/internal/imaging"
 	"github.com/choreme/choreme/internal/model"
 	"github.com/choreme/choreme/internal/store"
+	"github.com/shopspring/decimal"
 )
 
+var (
+	ErrProofRequired  = fmt.Errorf("%w: a proof image is required for this chore", ErrInvalidInput)
+	ErrReasonRequired = fmt.Errorf("%w: a reason is required to reject a chore", ErrInvalidInput)
+)
+
+// TransitionError reports an assignment status change the lifecycle doesn't
+// allow. It matches ErrConflict with errors.Is.
+type TransitionError struct {
+	From model.AssignmentStatus
+	To   model.AssignmentStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot move assignment from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// assignmentTransitions is the assignment lifecycle:
+//
+//	pending → in_progress → completed → approved | rejected
+//
+// Open assignments (pending, in_progress) become late once past due, and late
+// ones can still be worked on and completed. Progress updates keep
+// in_progress and late in place, and a rejected chore can be reworked.
+// Open and late assignments expire once past their chore's expiry. Approved
+// and expired are terminal.
+var assignmentTransitions = map[model.AssignmentStatus][]model.AssignmentStatus{
+	model.StatusPending:    {model.StatusInProgress, model.StatusCompleted, model.StatusLate, model.StatusExpired},
+	model.StatusInProgress: {model.StatusInProgress, model.StatusCompleted, model.StatusLate, model.StatusExpired},
+	model.StatusLate:       {model.StatusLate, model.StatusCompleted, model.StatusExpired},
+	model.StatusCompleted:  {model.StatusApproved, model.StatusRejected},
+	model.StatusRejected:   {model.StatusInProgress, model.StatusCompleted},
+	model.StatusApproved:   {},
+	model.StatusExpired:    {},
+}
+
+// CheckTransition returns a *TransitionError unless the lifecycle allows
+// moving from one status to the other.
+func CheckTransition(from, to model.AssignmentStatus) error {
+	for _, allowed := range assignmentTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &TransitionError{From: from, To: to}
+}
+
 type AssignmentService struct {
-	store store.Store
-	audit *AuditService
+	store  store.Store
+	audit  *AuditService
+	ledger *LedgerService
+	images imaging.Options
 }
 
-func NewAssignmentService(store store.Store, audit *AuditService) *AssignmentService {
+// NewAssignmentService processes proof images within the limits of images.
+func NewAssignmentService(store store.Store, audit *AuditService, ledger *LedgerService, images imaging.Options) *AssignmentService {
 	return &AssignmentService{
-		store: store,
-		audit: audit,
+		store:  store,
+		audit:  audit,
+		ledger: ledger,
+		images: images,
+	}
+}
+
+// GetAssignmentByID returns an assignment from the actor's household.
+// Workers may only see their own assignments.
+func (s *AssignmentService) GetAssignmentByID(ctx context.Context, actor Actor, id int) (*model.Assignment, error) {
+	assignment, err := s.store.GetAssignmentByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "assignment")
+	}
+	if !canView(actor, assignment) {
+		return nil, fmt.Errorf("assignment %w", ErrNotFound)
+	}
+	return assignment, nil
+}
+
+// GetAssignmentsByUser lists one household member's assignments. Workers may
+// only list their own.
+func (s *AssignmentService) GetAssignmentsByUser(ctx context.Context, actor Actor, userID int, filters model.AssignmentFilters) ([]*model.Assignment, error) {
+	if userID != actor.UserID {
+		if !actor.Can(model.PermHouseholdView) {
+			return nil, ErrForbidden
+		}
+		if _, err := s.store.GetMember(ctx, actor.HouseholdID, userID); err != nil {
+			return nil, fmt.Errorf("user %w", ErrNotFound)
+		}
+	}
+	filters.Limit, filters.Offset = normalizePage(filters.Limit, filters.Offset)
+	if err := s.resolveDueWithin(ctx, actor, &filters); err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.store.GetAssignmentsByUser(ctx, actor.HouseholdID, userID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	if assignments == nil {
+		assignments = []*model.Assignment{}
+	}
+	return assignments, nil
+}
+
+// GetAssignmentsByHousehold lists every assignment in the actor's household,
+// falling back to the actor's own assignments for workers.
+func (s *AssignmentService) GetAssignmentsByHousehold(ctx context.Context, actor Actor, filters model.AssignmentFilters) ([]*model.Assignment, error) {
+	if !actor.Can(model.PermHouseholdView) {
+		return s.GetAssignmentsByUser(ctx, actor, actor.UserID, filters)
+	}
+	filters.Limit, filters.Offset = normalizePage(filters.Limit, filters.Offset)
+	if err := s.resolveDueWithin(ctx, actor, &filters); err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.store.GetAssignmentsByHousehold(ctx, actor.HouseholdID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	if assignments == nil {
+		assignments = []*model.Assignment{}
+	}
+	return assignments, nil
+}
+
+// resolveDueWithin narrows the due date filters to the household's current
+// day, week or month when filters.DueWithin is set.
+func (s *AssignmentService) resolveDueWithin(ctx context.Context, actor Actor, filters *model.AssignmentFilters) error {
+	if filters.DueWithin == nil {
+		return nil
+	}
+	if !filters.DueWithin.Valid() {
+		return invalidf("due_within must be day, week or month")
+	}
+	household, err := getHousehold(ctx, s.store, actor.HouseholdID)
+	if err != nil {
+		return err
+	}
+
+	start, end := household.Bounds(*filters.DueWithin, time.Now())
+	start, last := start.UTC(), end.UTC().Add(-time.Nanosecond)
+	if filters.DueAfter == nil || filters.DueAfter.Before(start) {
+		filters.DueAfter = &start
+	}
+	if filters.DueBefore == nil || filters.DueBefore.After(last) {
+		filters.DueBefore = &last
+	}
+	filters.DueWithin = nil
+	return nil
+}
+
+// UpdateProgress records partial completion. The first update starts a
+// pending or rejected assignment; late assignments stay late.
+func (s *AssignmentService) UpdateProgress(ctx context.Context, actor Actor, assignmentID int, percentComplete string) (*model.Assignment, error) {
+	percent, err := parsePercent("percent_complete", percentComplete)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignment *model.Assignment
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		assignment, err = s.loadForWork(ctx, tx, actor, assignmentID)
+		if err != nil {
+			return err
+		}
+
+		to := model.StatusInProgress
+		if assignment.Status == model.StatusLate {
+			to = model.StatusLate
+		}
+		previous := assignment.PercentComplete
+		assignment.PercentComplete = percent
+
+		return s.transition(ctx, tx, actor, assignment, to, map[string]interface{}{
+			"previous_percent": previous.StringFixed(2),
+			"percent_complete": percent.StringFixed(2),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// CompleteChore marks an assignment completed, enforcing the chore's proof
+// requirement. Chores set to auto-approve are approved in the same
+// transaction.
+func (s *AssignmentService) CompleteChore(ctx context.Context, actor Actor, assignmentID int, req *model.CompleteChoreRequest) (*model.Assignment, error) {
+	percent, err := parsePercent("percent_complete", req.PercentComplete)
+	if err != nil {
+		return nil, err
+	}
+	if percent.IsZero() {
+		return nil, invalidf("percent_complete must be greater than 0")
+	}
+
+	var proofImage *imaging.Image
+	if req.ProofImage != nil && *req.ProofImage != "" {
+		proofImage, err = s.decodeProofImage(*req.ProofImage)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var assignment *model.Assignment
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		assignment, err = s.loadForWork(ctx, tx, actor, assignmentID)
+		if err != nil {
+			return err
+		}
+		if err := CheckTransition(assignment.Status, model.StatusCompleted); err != nil {
+			return err
+		}
+		if proofImage != nil {
+			assignment.ProofImage, assignment.ProofImageType = proofImage.Data, &proofImage.ContentType
+		}
+		if assignment.Chore.ProofRequired && len(assignment.ProofImage) == 0 {
+			return ErrProofRequired
+		}
+
+		now := time.Now()
+		assignment.PercentComplete = percent
+		assignment.CompletedAt = &now
+		if err := s.transition(ctx, tx, actor, assignment, model.StatusCompleted, map[string]interface{}{
+			"percent_complete": percent.StringFixed(2),
+			"late":             now.After(assignment.DueDate),
+			"has_proof":        len(assignment.ProofImage) > 0,
+		}); err != nil {
+			return err
+		}
+
+		if assignment.Chore.AutoApprove {
+			return s.approve(ctx, tx, actor, assignment, nil, nil, true)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// ApproveChore approves a completed assignment.
+// A manager may override the computed payout with req.Amount.
+func (s *AssignmentService) ApproveChore(ctx context.Context, actor Actor, assignmentID int, req *model.ApprovalRequest) (*model.Assignment, error) {
+	if !actor.Can(model.PermAssignmentsApprove) {
+		return nil, ErrForbidden
+	}
+	var override *decimal.Decimal
+	if req.Amount != nil {
+		household, err := getHousehold(ctx, s.store, actor.HouseholdID)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := parseAmount("amount", *req.Amount, household.DecimalPlaces)
+		if err != nil {
+			return nil, err
+		}
+		override = &amount
+	}
+
+	var assignment *model.Assignment
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		assignment, err = s.loadForReview(ctx, tx, actor, assignmentID)
+		if err != nil {
+			return err
+		}
+		return s.approve(ctx, tx, actor, assignment, optionalString(req.ApprovalNotes), override, false)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return assignment, nil
 }
 
-// Placeholder implementations - to be completed
-func (s *AssignmentService) CreateAssignment(ctx context.Context, assignment *model.Assignment) error {
-	return nil // TODO: Implement
+// RejectChore rejects a completed assignment; the notes must give a reason.
+func (s *AssignmentService) RejectChore(ctx context.Context, actor Actor, assignmentID int, req *model.ApprovalRequest) (*model.Assignment, error) {
+	if !actor.Can(model.PermAssignmentsApprove) {
+		return nil, ErrForbidden
+	}
+	reason := optionalString(req.ApprovalNotes)
+	if reason == nil {
+		return nil, ErrReasonRequired
+	}
+
+	var assignment *model.Assignment
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		assignment, err = s.loadForReview(ctx, tx, actor, assignmentID)
+		if err != nil {
+			return err
+		}
+
+		assignment.ApprovalNotes = reason
+		assignment.ApprovedAt = nil
+		return s.transition(ctx, tx, actor, assignment, model.StatusRejected, map[string]interface{}{
+			"reason": *reason,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
 }
 
-func (s *AssignmentService) GetAssignmentByID(ctx context.Context, id int) (*model.Assignment, error) {
-	return nil, nil // TODO: Implement
+// approve moves a completed assignment to approved and credits the payout
+// to the assignee in the same transaction. auto marks approvals applied
+// because the chore is set to auto-approve; override replaces the computed
+// payout.
+func (s *AssignmentService) approve(ctx context.Context, tx store.Store, actor Actor, assignment *model.Assignment, notes *string, override *decimal.Decimal, auto bool) error {
+	household, err := getHousehold(ctx, tx, assignment.Chore.HouseholdID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	assignment.ApprovalNotes = notes
+	assignment.ApprovedAt = &now
+
+	payout, penalty := Payout(household, assignment)
+	details := map[string]interface{}{
+		"auto_approved": auto,
+		"payout":        household.FormatAmount(payout),
+		"late_penalty":  household.FormatAmount(penalty),
+	}
+	if override != nil {
+		details["computed_payout"] = household.FormatAmount(payout)
+		payout = *override
+		details["payout"] = household.FormatAmount(payout)
+	}
+	if notes != nil {
+		details["approval_notes"] = *notes
+	}
+	if err := s.transition(ctx, tx, actor, assignment, model.StatusApproved, details); err != nil {
+		return err
+	}
+
+	description := "Chore: " + assignment.Chore.Title
+	return s.ledger.CreateLedgerEntry(ctx, tx, &model.LedgerEntry{
+		HouseholdID:       assignment.Chore.HouseholdID,
+		UserID:            assignment.AssignedTo,
+		ChoreAssignmentID: &assignment.ID,
+		Type:              model.LedgerTypeEarn,
+		Amount:            payout,
+		Description:       &description,
+		CreatedAt:         now,
+	})
 }
 
-func (s *AssignmentService) GetAssignmentsByUser(ctx context.Context, userID int, filters model.AssignmentFilters) ([]*model.Assignment, error) {
-	return nil, nil // TODO: Implement
+// Payout computes what an assignment earns: the chore value scaled by the
+// percentage completed, less the chore's late penalty when it was completed
+// after its due date. Both results are rounded to the household's decimal
+// places.
+func Payout(household *model.Household, assignment *model.Assignment) (payout, penalty decimal.Decimal) {
+	hundred := decimal.NewFromInt(100)
+	payout = assignment.Chore.Value.Mul(assignment.PercentComplete).Div(hundred)
+	if assignment.CompletedAt != nil && assignment.CompletedAt.After(assignment.DueDate) {
+		penalty = household.Round(payout.Mul(assignment.Chore.LatePenaltyPct).Div(hundred))
+	}
+	return household.Round(payout).Sub(penalty), penalty
 }
 
-func (s *AssignmentService) GetAssignmentsByHousehold(ctx context.Context, householdID int, filters model.AssignmentFilters) ([]*model.Assignment, error) {
-	return nil, nil // TODO: Implement
+// SweepResult summarizes one overdue sweep.
+type SweepResult struct {
+	Late    int `json:"late"`
+	Expired int `json:"expired"`
+	Failed  int `json:"failed"`
 }
 
-func (s *AssignmentService) UpdateProgress(ctx context.Context, assignmentID int, percentComplete string) error {
-	return nil // TODO: Implement
+// SweepOverdue marks open assignments that are past due as late, and expires
+// open or late assignments once their chore's expire_days have passed since
+// the due date, counted in days of the household's timezone. Each assignment
+// is swept in its own transaction and re-checked under its lock, so the
+// sweep can run alongside members working on their chores. Changes are
+// audited without a user.
+func (s *AssignmentService) SweepOverdue(ctx context.Context, now time.Time) (*SweepResult, error) {
+	overdue, err := s.store.GetOverdueAssignments(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overdue assignments: %w", err)
+	}
+
+	result := &SweepResult{}
+	households := make(map[int]*model.Household)
+	for _, candidate := range overdue {
+		var to model.AssignmentStatus
+		err := s.store.WithTx(ctx, func(tx store.Store) error {
+			assignment, err := tx.GetAssignmentForUpdate(ctx, candidate.ID)
+			if err != nil {
+				return err
+			}
+			household, ok := households[assignment.Chore.HouseholdID]
+			if !ok {
+				if household, err = getHousehold(ctx, tx, assignment.Chore.HouseholdID); err != nil {
+					return err
+				}
+				households[household.ID] = household
+			}
+			to = overdueStatus(assignment, household.Location(), now)
+			if to == "" {
+				return nil
+			}
+
+			details := map[string]interface{}{
+				"due_date": assignment.DueDate,
+			}
+			if to == model.StatusExpired {
+				details["expire_days"] = *assignment.Chore.ExpireDays
+			}
+			return s.transition(ctx, tx, Actor{}, assignment, to, details)
+		})
+		switch {
+		case err != nil:
+			log.Printf("Sweeper: failed to sweep assignment %d: %v", candidate.ID, err)
+			result.Failed++
+		case to == model.StatusLate:
+			result.Late++
+		case to == model.StatusExpired:
+			result.Expired++
+		}
+	}
+
+	if result.Late > 0 || result.Expired > 0 || result.Failed > 0 {
+		log.Printf("Sweeper: marked %d assignments late and %d expired (%d failures)",
+			result.Late, result.Expired, result.Failed)
+	}
+	return result, nil
 }
 
-func (s *AssignmentService) CompleteChore(ctx context.Context, assignmentID int, percentComplete string, proofImage []byte) error {
-	return nil // TODO: Implement
+// overdueStatus returns the status an assignment should move to at now, or
+// "" when it is neither newly late nor expired. Expiry days are days in loc.
+func overdueStatus(assignment *model.Assignment, loc *time.Location, now time.Time) model.AssignmentStatus {
+	switch assignment.Status {
+	case model.StatusPending, model.StatusInProgress, model.StatusLate:
+	default:
+		return ""
+	}
+	if !assignment.DueDate.Before(now) {
+		return ""
+	}
+	if days := assignment.Chore.ExpireDays; days != nil && !assignment.DueDate.In(loc).AddDate(0, 0, *days).After(now) {
+		return model.StatusExpired
+	}
+	if assignment.Status != model.StatusLate {
+		return model.StatusLate
+	}
+	return ""
 }
 
-func (s *AssignmentService) ApproveChore(ctx context.Context, assignmentID int, approvalNotes string) error {
-	return nil // TODO: Implement
+// transition validates and applies a status change, then writes its audit
+// entry through the same transaction.
+func (s *AssignmentService) transition(ctx context.Context, tx store.Store, actor Actor, assignment *model.Assignment, to model.AssignmentStatus, details map[string]interface{}) error {
+	from := assignment.Status
+	if err := CheckTransition(from, to); err != nil {
+		return err
+	}
+
+	assignment.Status = to
+	if err := tx.UpdateAssignment(ctx, assignment); err != nil {
+		return fmt.Errorf("failed to update assignment: %w", err)
+	}
+	assignment.UpdatedAt = time.Now()
+
+	details["assignment_id"] = assignment.ID
+	details["chore_id"] = assignment.ChoreID
+	details["assigned_to"] = assignment.AssignedTo
+	details["from"] = from
+	details["to"] = to
+	return s.audit.Record(ctx, tx, assignment.Chore.HouseholdID, actor.UserID, "assignment_"+string(to), details)
 }
 
-func (s *AssignmentService) RejectChore(ctx context.Context, assignmentID int, approvalNotes string) error {
-	return nil // TODO: Implement
-}
\ No newline at end of file
+// loadForWork locks an assignment the actor is working on. Workers may only
+// work on their own assignments; managers may act on anyone's.
+func (s *AssignmentService) loadForWork(ctx context.Context, tx store.Store, actor Actor, id int) (*model.Assignment, error) {
+	assignment, err := tx.GetAssignmentForUpdate(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "assignment")
+	}
+	if !canView(actor, assignment) {
+		return nil, fmt.Errorf("assignment %w", ErrNotFound)
+	}
+	if assignment.AssignedTo != actor.UserID && !actor.Can(model.PermAssignmentsWork) {
+		return nil, ErrForbidden
+	}
+	return assignment, nil
+}
+
+// loadForReview locks an assignment a manager is approving or rejecting.
+func (s *AssignmentService) loadForReview(ctx context.Context, tx store.Store, actor Actor, id int) (*model.Assignment, error) {
+	assignment, err := tx.GetAssignmentForUpdate(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "assignment")
+	}
+	if assignment.Chore.HouseholdID != actor.HouseholdID {
+		return nil, fmt.Errorf("assignment %w", ErrNotFound)
+	}
+	return assignment, nil
+}
+
+func canView(actor Actor, assignment *model.Assignment) bool {
+	if assignment.Chore == nil || assignment.Chore.HouseholdID != actor.HouseholdID {
+		return false
+	}
+	return actor.Can(model.PermHouseholdView) || assignment.AssignedTo == actor.UserID
+}
+
+// decodeProofImage accepts raw base64 or a data: URL and processes the
+// image it holds.
+func (s *AssignmentService) decodeProofImage(encoded string) (*imaging.Image, error) {
+	if i := strings.Index(encoded, ","); strings.HasPrefix(encoded, "data:") && i >= 0 {
+		encoded = encoded[i+1:]
+	}
+	if s.images.MaxBytes > 0 && base64.StdEncoding.DecodedLen(len(encoded)) > s.images.MaxBytes+2 {
+		return nil, s.proofImageError(imaging.ErrTooLarge)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalidf("proof_image must be base64 encoded")
+	}
+	return s.processProofImage(data)
+}
+
+// processProofImage validates an uploaded proof image and normalizes it:
+// shrunk to the configured dimension and re-encoded without metadata.
+func (s *AssignmentService) processProofImage(data []byte) (*imaging.Image, error) {
+	image, err := imaging.Process(data, s.images)
+	if err != nil {
+		return nil, s.proofImageError(err)
+	}
+	return image, nil
+}
+
+func (s *AssignmentService) proofImageError(err error) error {
+	switch {
+	case errors.Is(err, imaging.ErrTooLarge):
+		return invalidf("proof_image must be at most %d MB and %d megapixels", s.images.MaxBytes>>20, imaging.MaxPixels/1_000_000)
+	case errors.Is(err, imaging.ErrNotImage):
+		return invalidf("proof_image must be a JPEG, PNG or GIF image")
+	}
+	return err
+}
+
+// AttachProofImage processes an uploaded photo and attaches it to an
+// assignment that can still be completed, replacing any earlier one.
+func (s *AssignmentService) AttachProofImage(ctx context.Context, actor Actor, assignmentID int, data []byte) (*model.ProofImage, error) {
+	image, err := s.processProofImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		assignment, err := s.loadForWork(ctx, tx, actor, assignmentID)
+		if err != nil {
+			return err
+		}
+		if err := CheckTransition(assignment.Status, model.StatusCompleted); err != nil {
+			return err
+		}
+		assignment.ProofImage, assignment.ProofImageType = image.Data, &image.ContentType
+		assignment.UpdatedAt = time.Now()
+		if err := tx.UpdateAssignment(ctx, assignment); err != nil {
+			return fmt.Errorf("failed to update assignment: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "proof_image_attached", map[string]interface{}{
+			"assignment_id": assignment.ID,
+			"content_type":  image.ContentType,
+			"width":         image.Width,
+			"height":        image.Height,
+			"size":          len(image.Data),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.ProofImage{
+		AssignmentID: assignmentID,
+		ContentType:  image.ContentType,
+		Width:        image.Width,
+		Height:       image.Height,
+		Size:         len(image.Data),
+	}, nil
+}
+
+// GetProofImage returns an assignment's proof image and its content type.
+// Images stored before uploads were processed aren't known to be images, so
+// they are served as application/octet-stream.
+func (s *AssignmentService) GetProofImage(ctx context.Context, actor Actor, assignmentID int) ([]byte, string, error) {
+	assignment, err := s.store.GetAssignmentByID(ctx, assignmentID)
+	if err != nil {
+		return nil, "", notFound(err, "assignment")
+	}
+	if !canView(actor, assignment) {
+		return nil, "", fmt.Errorf("assignment %w", ErrNotFound)
+	}
+	if len(assignment.ProofImage) == 0 {
+		return nil, "", fmt.Errorf("proof image %w", ErrNotFound)
+	}
+	contentType := "application/octet-stream"
+	if assignment.ProofImageType != nil {
+		contentType = *assignment.ProofImageType
+	}
+	return assignment.ProofImage, contentType, nil
+}
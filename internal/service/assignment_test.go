@@ -0,0 +1,72 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/choreme/choreme/internal/imaging"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+	"github.com/shopspring/decimal"
+)
+
+// addChore creates a one-off chore in household.
+func addChore(t *testing.T, st store.Store, household *model.Household, createdBy *model.User) *model.Chore {
+	t.Helper()
+	now := time.Now()
+	chore := &model.Chore{
+		HouseholdID: household.ID,
+		Title:       "Dishes",
+		Value:       decimal.NewFromInt(1),
+		Priority:    model.PriorityMedium,
+		CreatedBy:   createdBy.ID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := st.CreateChore(context.Background(), chore); err != nil {
+		t.Fatalf("failed to create chore: %v", err)
+	}
+	return chore
+}
+
+func TestProofImageContentType(t *testing.T) {
+	st := newTestStore(t)
+	s := NewAssignmentService(st, NewAuditService(st), nil, imaging.Options{MaxBytes: 1 << 20, MaxDimension: 100})
+	household, members := newTestHousehold(t, st)
+	ctx := context.Background()
+	worker := members[model.RoleWorker]
+	chore := addChore(t, st, household, members[model.RoleAdmin])
+
+	// A proof stored before uploads were processed
+	now := time.Now()
+	assignment := &model.Assignment{
+		ChoreID:    chore.ID,
+		AssignedTo: worker.ID,
+		DueDate:    now,
+		Status:     model.StatusPending,
+		ProofImage: []byte("<html><script>alert(1)</script></html>"),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := st.CreateAssignment(ctx, assignment); err != nil {
+		t.Fatal(err)
+	}
+	if _, contentType, err := s.GetProofImage(ctx, actorFor(worker), assignment.ID); err != nil || contentType != "application/octet-stream" {
+		t.Errorf("legacy proof served as %q, %v; want application/octet-stream", contentType, err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AttachProofImage(ctx, actorFor(worker), assignment.ID, buf.Bytes()); err != nil {
+		t.Fatalf("failed to attach proof image: %v", err)
+	}
+	if _, contentType, err := s.GetProofImage(ctx, actorFor(worker), assignment.ID); err != nil || contentType != "image/png" {
+		t.Errorf("proof served as %q, %v; want image/png", contentType, err)
+	}
+}
@@ -18,20 +18,51 @@ func NewAuditService(store store.Store) *AuditService {
 	}
 }
 
+type impersonatorKey struct{}
+
+// WithImpersonator marks ctx as a request a system administrator makes while
+// impersonating a user. Everything audited under it records them.
+func WithImpersonator(ctx context.Context, impersonatorID int) context.Context {
+	return context.WithValue(ctx, impersonatorKey{}, impersonatorID)
+}
+
+func impersonatorFrom(ctx context.Context) int {
+	id, _ := ctx.Value(impersonatorKey{}).(int)
+	return id
+}
+
 func (s *AuditService) LogAction(ctx context.Context, householdID, userID int, action string, details map[string]interface{}) {
+	// Log errors but don't fail the main operation if audit logging fails
+	if err := s.Record(ctx, s.store, householdID, userID, action, details); err != nil {
+		// In production, you might want to use a proper logger here
+		// log.Printf("Failed to create audit log: %v", err)
+	}
+}
+
+// Record writes an audit entry through st and returns any error, so callers
+// inside a transaction can roll back when the audit trail can't be written.
+// A zero userID records the entry without a user, for actions taken by
+// background jobs. Entries made while impersonating carry the impersonator
+// as impersonated_by.
+func (s *AuditService) Record(ctx context.Context, st store.Store, householdID, userID int, action string, details map[string]interface{}) error {
+	if impersonatorID := impersonatorFrom(ctx); impersonatorID != 0 {
+		flagged := make(map[string]interface{}, len(details)+1)
+		for key, value := range details {
+			flagged[key] = value
+		}
+		flagged["impersonated_by"] = impersonatorID
+		details = flagged
+	}
 	auditLog := &model.AuditLog{
 		HouseholdID: householdID,
-		UserID:      userID,
 		Action:      action,
 		Details:     details,
 		CreatedAt:   time.Now(),
 	}
-
-	// Log errors but don't fail the main operation if audit logging fails
-	if err := s.store.CreateAuditLog(ctx, auditLog); err != nil {
-		// In production, you might want to use a proper logger here
-		// log.Printf("Failed to create audit log: %v", err)
+	if userID != 0 {
+		auditLog.UserID = &userID
 	}
+	return st.CreateAuditLog(ctx, auditLog)
 }
 
 func (s *AuditService) GetAuditLogs(ctx context.Context, householdID int, filters model.AuditFilters) ([]*model.AuditLog, error) {
@@ -2,27 +2,95 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/choreme/choreme/internal/auth"
+	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/mailer"
 	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/oidc"
 	"github.com/choreme/choreme/internal/store"
 )
 
+// AccountLockedError is returned by Login for an account locked after too
+// many failed logins.
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return "account temporarily locked after too many failed logins"
+}
+
+// ErrAccountDeactivated is returned when a deactivated or removed member
+// tries to log in or use a session.
+var ErrAccountDeactivated = fmt.Errorf("%w: account is deactivated", ErrUnauthorized)
+
 type AuthService struct {
-	store store.Store
-	audit *AuditService
+	store      store.Store
+	audit      *AuditService
+	sessions   *SessionCache
+	mailer     mailer.Mailer
+	refreshTTL time.Duration
+	baseURL    string
+	lockout    config.AuthConfig
+	providers  []*oidc.Provider
+	oidcTTL    time.Duration
+	inviteTTL  time.Duration
+	// openRegistration lets anyone register a household once the instance
+	// is set up
+	openRegistration bool
+	setupToken       string
 }
 
-func NewAuthService(store store.Store, audit *AuditService) *AuthService {
-	return &AuthService{
-		store: store,
-		audit: audit,
+func NewAuthService(store store.Store, audit *AuditService, sessions *SessionCache, mailer mailer.Mailer, cfg *config.Config) *AuthService {
+	s := &AuthService{
+		store:      store,
+		audit:      audit,
+		sessions:   sessions,
+		mailer:     mailer,
+		refreshTTL: cfg.JWT.RefreshTokenTTL,
+		baseURL:    cfg.Server.BaseURL,
+		lockout:    cfg.Auth,
+		oidcTTL:    cfg.OIDC.StateTTL,
+		inviteTTL:  cfg.Auth.InviteTTL,
+
+		openRegistration: cfg.Auth.OpenRegistration,
+		setupToken:       cfg.Auth.SetupToken,
+	}
+	if s.setupToken == "" {
+		// Logged at startup while the instance needs setting up. Without
+		// one, setup is refused until the server restarts.
+		s.setupToken, _ = auth.GenerateOpaqueToken()
 	}
+	for _, provider := range cfg.OIDC.Providers {
+		s.providers = append(s.providers, oidc.New(provider, cfg.OIDC.RedirectURL))
+	}
+	return s
 }
 
-func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest, isFirstUser bool) (*model.User, error) {
+// Register creates a household with the user as its admin. Until the
+// instance has a system administrator only the holder of the setup token can
+// register, and becomes it; after that registering needs open registration.
+func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest) (*model.User, error) {
+	status, err := s.SetupStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	role := model.RoleAdmin
+	switch {
+	case status.SetupRequired:
+		if s.setupToken == "" || subtle.ConstantTimeCompare([]byte(req.SetupToken), []byte(s.setupToken)) != 1 {
+			return nil, fmt.Errorf("%w: the instance isn't set up yet, register with the setup token", ErrForbidden)
+		}
+		role = model.RoleSystemAdmin
+	case !status.RegistrationOpen:
+		return nil, fmt.Errorf("%w: registration is closed, ask for an invitation", ErrForbidden)
+	}
+
 	// Check if email already exists
 	existingUser, err := s.store.GetUserByEmail(ctx, req.Email)
 	if err == nil && existingUser != nil {
@@ -35,25 +103,9 @@ func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest,
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Create household
-	household := &model.Household{
-		Name:      req.HouseholdName,
-		CreatedAt: time.Now(),
-	}
-
-	if err := s.store.CreateHousehold(ctx, household); err != nil {
-		return nil, fmt.Errorf("failed to create household: %w", err)
-	}
+	household := model.NewHousehold(req.HouseholdName, time.Now())
 
-	// Determine role
-	role := model.RoleAdmin
-	if isFirstUser {
-		role = model.RoleSystemAdmin
-	}
-
-	// Create user
 	user := &model.User{
-		HouseholdID:           household.ID,
 		Name:                  req.Name,
 		Email:                 req.Email,
 		PasswordHash:          hashedPassword,
@@ -64,50 +116,193 @@ func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest,
 		UpdatedAt:             time.Now(),
 	}
 
-	if err := s.store.CreateUser(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
+	// Household, user and audit entry are created together or not at all
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if role == model.RoleSystemAdmin {
+			count, err := tx.CountSystemAdmins(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to check setup: %w", err)
+			}
+			if count > 0 {
+				return fmt.Errorf("%w: the instance is already set up", ErrConflict)
+			}
+		}
+		if err := tx.CreateHousehold(ctx, household); err != nil {
+			return fmt.Errorf("failed to create household: %w", err)
+		}
 
-	// Audit log
-	s.audit.LogAction(ctx, household.ID, user.ID, "user_registered", map[string]interface{}{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"role":    user.Role,
+		user.HouseholdID = household.ID
+		if err := tx.CreateUser(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		return s.audit.Record(ctx, tx, household.ID, user.ID, "user_registered", map[string]interface{}{
+			"user_id": user.ID,
+			"email":   user.Email,
+			"role":    user.Role,
+		})
 	})
+	if err != nil {
+		return nil, err
+	}
 
+	s.SendEmailVerification(ctx, user)
 	return user, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*model.User, error) {
+// Login checks a user's credentials. Consecutive failures lock the account
+// for a while, see recordLoginFailure; while it is locked even the right
+// password is refused. Users with two-factor authentication, or who need to
+// set it up, get a challenge to finish with CompleteTwoFactorLogin.
+func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*LoginResult, error) {
 	user, err := s.store.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
+	// A locked account refuses every attempt without checking the password,
+	// so the answer says nothing about it. The attempts still count.
+	now := time.Now()
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		if err := s.recordLoginFailure(ctx, user.ID, now); err != nil {
+			return nil, err
+		}
+		return nil, &AccountLockedError{Until: *user.LockedUntil}
+	}
 	if err := auth.VerifyPassword(user.PasswordHash, req.Password); err != nil {
+		if err := s.recordLoginFailure(ctx, user.ID, now); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	// Audit log
-	s.audit.LogAction(ctx, user.HouseholdID, user.ID, "user_login", map[string]interface{}{
+	if user.FailedLogins > 0 || user.LockedUntil != nil {
+		if err := s.store.UpdateLoginFailures(ctx, user.ID, 0, nil); err != nil {
+			return nil, fmt.Errorf("failed to reset failed logins: %w", err)
+		}
+	}
+
+	return s.finishLogin(ctx, user, map[string]interface{}{
 		"user_id": user.ID,
 		"email":   user.Email,
 	})
+}
 
-	return user, nil
+// finishLogin completes a login whose first factor was right: it asks for
+// the second factor when the user has or needs one, and otherwise audits
+// the login with details.
+func (s *AuthService) finishLogin(ctx context.Context, user *model.User, details map[string]interface{}) (*LoginResult, error) {
+	err := checkHousehold(ctx, s.store, user.HouseholdID)
+	if err != nil && !errors.Is(err, ErrHouseholdSuspended) {
+		return nil, err
+	}
+	if !user.Active() || err != nil {
+		user, err = s.landInActiveHousehold(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+	}
+	required, err := s.twoFactorRequired(ctx, s.store, user)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled() || required {
+		challenge, err := s.createChallenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Challenge: challenge}, nil
+	}
+
+	// Audit log
+	s.audit.LogAction(ctx, user.HouseholdID, user.ID, "user_login", details)
+
+	return &LoginResult{User: user}, nil
 }
 
-func (s *AuthService) JoinHousehold(ctx context.Context, req *model.JoinHouseholdRequest) (*model.User, error) {
-	// Find household by invite code
-	household, err := s.store.GetHouseholdByInviteCode(ctx, req.InviteCode)
+// landInActiveHousehold moves a user whose membership of their current
+// household is deactivated or gone, or whose household is suspended, to
+// another household they are still an active member of, and returns them as
+// a member of it.
+func (s *AuthService) landInActiveHousehold(ctx context.Context, user *model.User) (*model.User, error) {
+	memberships, err := s.store.GetMemberships(ctx, user.ID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid invite code")
+		return nil, fmt.Errorf("failed to get memberships: %w", err)
+	}
+	for _, membership := range memberships {
+		if membership.DeactivatedAt != nil || membership.HouseholdSuspendedAt != nil {
+			continue
+		}
+		if err := s.store.SetCurrentHousehold(ctx, user.ID, membership.HouseholdID); err != nil {
+			return nil, fmt.Errorf("failed to switch household: %w", err)
+		}
+		return s.store.GetUserByID(ctx, user.ID)
 	}
+	if user.Active() {
+		return nil, ErrHouseholdSuspended
+	}
+	return nil, ErrAccountDeactivated
+}
+
+// recordLoginFailure counts a failed login. Once the count reaches the
+// lockout threshold the account is locked, and every failure after the lock
+// expires locks it again for twice as long, up to the maximum duration.
+// Failures while the account is locked count towards the next lock without
+// extending the current one.
+func (s *AuthService) recordLoginFailure(ctx context.Context, userID int, now time.Time) error {
+	return s.store.WithTx(ctx, func(tx store.Store) error {
+		user, err := tx.GetUserForUpdate(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		failures := user.FailedLogins + 1
+		if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+			if err := tx.UpdateLoginFailures(ctx, user.ID, failures, user.LockedUntil); err != nil {
+				return fmt.Errorf("failed to record failed login: %w", err)
+			}
+			return nil
+		}
+		var lockedUntil *time.Time
+		if threshold := s.lockout.LockoutThreshold; threshold > 0 && failures >= threshold {
+			until := now.Add(s.lockoutDuration(failures - threshold))
+			lockedUntil = &until
+		}
+		if err := tx.UpdateLoginFailures(ctx, user.ID, failures, lockedUntil); err != nil {
+			return fmt.Errorf("failed to record failed login: %w", err)
+		}
+		if lockedUntil == nil {
+			return nil
+		}
+		return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "account_locked", map[string]interface{}{
+			"user_id":       user.ID,
+			"failed_logins": failures,
+			"locked_until":  lockedUntil,
+		})
+	})
+}
 
+// lockoutDuration is the lockout after the given number of failures past
+// the threshold.
+func (s *AuthService) lockoutDuration(extra int) time.Duration {
+	d := s.lockout.LockoutDuration
+	for i := 0; i < extra && d < s.lockout.LockoutMaxDuration; i++ {
+		d *= 2
+	}
+	if d > s.lockout.LockoutMaxDuration {
+		d = s.lockout.LockoutMaxDuration
+	}
+	return d
+}
+
+// JoinHousehold creates an account in the household of an invitation, with
+// the invitation's role. Invitations are mailed to their email address, so
+// joining with one proves it.
+func (s *AuthService) JoinHousehold(ctx context.Context, req *model.JoinHouseholdRequest) (*model.User, error) {
 	// Check if email already exists
 	existingUser, err := s.store.GetUserByEmail(ctx, req.Email)
 	if err == nil && existingUser != nil {
-		return nil, fmt.Errorf("email already registered")
+		return nil, fmt.Errorf("email already registered; log in and accept the invitation from your account")
 	}
 
 	// Hash password
@@ -116,40 +311,68 @@ func (s *AuthService) JoinHousehold(ctx context.Context, req *model.JoinHousehol
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Create user
+	now := time.Now()
 	user := &model.User{
-		HouseholdID:           household.ID,
 		Name:                  req.Name,
 		Email:                 req.Email,
 		PasswordHash:          hashedPassword,
-		Role:                  model.RoleWorker, // Default role for joined users
 		NotificationPrefEmail: true,
 		NotificationPrefPush:  true,
-		CreatedAt:             time.Now(),
-		UpdatedAt:             time.Now(),
+		CreatedAt:             now,
+		UpdatedAt:             now,
 	}
 
-	if err := s.store.CreateUser(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		invitation, err := s.useInvitation(ctx, tx, req.InviteCode, req.Email, now)
+		if err != nil {
+			return err
+		}
+		user.HouseholdID = invitation.HouseholdID
+		user.Role = invitation.Role
+		if invitation.Email != nil {
+			user.EmailVerifiedAt = &now
+		}
 
-	// Audit log
-	s.audit.LogAction(ctx, household.ID, user.ID, "user_joined_household", map[string]interface{}{
-		"user_id":      user.ID,
-		"email":        user.Email,
-		"invite_code":  req.InviteCode,
+		if err := tx.CreateUser(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		if user.EmailVerifiedAt != nil {
+			if err := tx.UpdateUser(ctx, user); err != nil {
+				return fmt.Errorf("failed to verify email: %w", err)
+			}
+		}
+
+		return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "user_joined_household", map[string]interface{}{
+			"user_id":       user.ID,
+			"email":         user.Email,
+			"role":          user.Role,
+			"invitation_id": invitation.ID,
+		})
 	})
+	if err != nil {
+		return nil, err
+	}
 
+	if user.EmailVerifiedAt == nil {
+		s.SendEmailVerification(ctx, user)
+	}
 	return user, nil
 }
 
-func (s *AuthService) IsFirstUser(ctx context.Context) (bool, error) {
-	// This is a simplified check - in a real implementation you might want to check
-	// if any system admin exists
-	users, err := s.store.GetUsersByHousehold(ctx, 1) // Check first household
+// SetupStatus reports whether the instance still needs its first system
+// administrator and whether registration is open.
+func (s *AuthService) SetupStatus(ctx context.Context) (*model.SetupStatus, error) {
+	count, err := s.store.CountSystemAdmins(ctx)
 	if err != nil {
-		// If error getting users, assume this might be the first user
-		return true, nil
+		return nil, fmt.Errorf("failed to check setup: %w", err)
 	}
-	return len(users) == 0, nil
+	return &model.SetupStatus{
+		SetupRequired:    count == 0,
+		RegistrationOpen: s.openRegistration,
+	}, nil
+}
+
+// SetupToken returns the token the first account registers with.
+func (s *AuthService) SetupToken() string {
+	return s.setupToken
 }
\ No newline at end of file
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/mailer"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+	"github.com/choreme/choreme/internal/store/sqlite"
+	"github.com/choreme/choreme/internal/store/storetest"
+)
+
+// newTestStore returns a store on a fresh, migrated SQLite database.
+func newTestStore(t *testing.T) store.Store {
+	dbCfg := config.DatabaseConfig{Type: "sqlite", Name: filepath.Join(t.TempDir(), "choreme.db")}
+	return sqlite.New(storetest.Open(t, dbCfg.Type, dbCfg.DriverName(), dbCfg.ConnectionString()))
+}
+
+func TestLoginWhileLocked(t *testing.T) {
+	st := newTestStore(t)
+	cfg := &config.Config{}
+	cfg.Auth.SetupToken = "setup"
+	cfg.Auth.LockoutThreshold = 2
+	cfg.Auth.LockoutDuration = time.Minute
+	cfg.Auth.LockoutMaxDuration = time.Hour
+	cfg.SMTP.OutboxDir = t.TempDir()
+	s := NewAuthService(st, NewAuditService(st), NewSessionCache(time.Minute), mailer.New(cfg.SMTP), cfg)
+
+	ctx := context.Background()
+	user, err := s.Register(ctx, &model.RegisterRequest{
+		HouseholdName: "Test",
+		Name:          "Alice",
+		Email:         testEmail,
+		Password:      "securepassword",
+		SetupToken:    "setup",
+	})
+	if err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+	login := func(password string) error {
+		_, err := s.Login(ctx, &model.LoginRequest{Email: testEmail, Password: password})
+		return err
+	}
+
+	for n := 0; n < 2; n++ {
+		if err := login("wrongpassword"); err == nil {
+			t.Fatal("login succeeded with the wrong password")
+		}
+	}
+
+	// The right and a wrong password get the same answer during the lock
+	var right, wrong *AccountLockedError
+	if err := login("securepassword"); !errors.As(err, &right) {
+		t.Fatalf("login with the right password = %v, want AccountLockedError", err)
+	}
+	if err := login("wrongpassword"); !errors.As(err, &wrong) {
+		t.Fatalf("login with a wrong password = %v, want AccountLockedError", err)
+	}
+	if !right.Until.Equal(wrong.Until) {
+		t.Errorf("locked until %v with the right password and %v with a wrong one", right.Until, wrong.Until)
+	}
+
+	// Both attempts counted without extending the lock
+	got, err := st.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.FailedLogins != 4 {
+		t.Errorf("failed logins = %d, want 4", got.FailedLogins)
+	}
+	if got.LockedUntil == nil || !got.LockedUntil.Equal(right.Until) {
+		t.Errorf("locked until %v, want %v", got.LockedUntil, right.Until)
+	}
+
+	// The next failure after the lock expires locks the account for twice
+	// as long per failure past the threshold
+	expired := time.Now().Add(-time.Second)
+	if err := st.UpdateLoginFailures(ctx, user.ID, got.FailedLogins, &expired); err != nil {
+		t.Fatal(err)
+	}
+	var relocked *AccountLockedError
+	if err := login("wrongpassword"); err == nil || errors.As(err, &relocked) {
+		t.Fatalf("login after the lock = %v, want invalid credentials", err)
+	}
+	if err := login("securepassword"); !errors.As(err, &relocked) {
+		t.Fatalf("login = %v, want AccountLockedError", err)
+	}
+	if d := time.Until(relocked.Until); d < 7*time.Minute || d > 8*time.Minute {
+		t.Errorf("locked for %v, want 8m", d)
+	}
+}
@@ -2,9 +2,14 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/recurrence"
 	"github.com/choreme/choreme/internal/store"
+	"github.com/shopspring/decimal"
 )
 
 type ChoreService struct {
@@ -19,23 +24,349 @@ func NewChoreService(store store.Store, audit *AuditService) *ChoreService {
 	}
 }
 
-// Placeholder implementations - to be completed
-func (s *ChoreService) CreateChore(ctx context.Context, chore *model.Chore) error {
-	return nil // TODO: Implement
+// CreateChore creates a chore in the actor's household together with one
+// pending assignment per assignee, all in a single transaction.
+func (s *ChoreService) CreateChore(ctx context.Context, actor Actor, req *model.CreateChoreRequest) (*model.Chore, error) {
+	if !actor.Can(model.PermChoresCreate) {
+		return nil, ErrForbidden
+	}
+
+	chore := &model.Chore{
+		HouseholdID:    actor.HouseholdID,
+		Title:          strings.TrimSpace(req.Title),
+		Description:    optionalString(req.Description),
+		Frequency:      optionalString(req.Frequency),
+		Category:       optionalString(req.Category),
+		Priority:       req.Priority,
+		AutoApprove:    req.AutoApprove,
+		ProofRequired:  req.ProofRequired,
+		ExpireDays:     req.ExpireDays,
+		CreatedBy:      actor.UserID,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		LatePenaltyPct: decimal.Zero,
+	}
+	if chore.Priority == "" {
+		chore.Priority = model.PriorityMedium
+	}
+
+	household, err := getHousehold(ctx, s.store, actor.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	if chore.Value, err = parseAmount("value", req.Value, household.DecimalPlaces); err != nil {
+		return nil, err
+	}
+	if req.LatePenaltyPct != "" {
+		if chore.LatePenaltyPct, err = parsePercent("late_penalty_pct", req.LatePenaltyPct); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateChore(chore); err != nil {
+		return nil, err
+	}
+
+	dueDate, err := parseDueDate("due_date", req.DueDate, household)
+	if err != nil {
+		return nil, err
+	}
+	// The first due date anchors the chore's recurrence and is its first
+	// generated occurrence
+	chore.ScheduleStart = &dueDate
+	chore.ScheduledUntil = &dueDate
+
+	assignees, err := s.householdMembers(ctx, actor.HouseholdID, req.AssignedTo)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateChore(ctx, chore); err != nil {
+			return fmt.Errorf("failed to create chore: %w", err)
+		}
+
+		for _, userID := range assignees {
+			assignment := &model.Assignment{
+				ChoreID:         chore.ID,
+				AssignedTo:      userID,
+				DueDate:         dueDate,
+				PercentComplete: decimal.Zero,
+				Status:          model.StatusPending,
+				CreatedAt:       time.Now(),
+				UpdatedAt:       time.Now(),
+			}
+			if err := tx.CreateAssignment(ctx, assignment); err != nil {
+				return fmt.Errorf("failed to create assignment: %w", err)
+			}
+			chore.Assignments = append(chore.Assignments, assignment)
+		}
+
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "chore_created", map[string]interface{}{
+			"chore_id":    chore.ID,
+			"title":       chore.Title,
+			"value":       household.FormatAmount(chore.Value),
+			"assigned_to": assignees,
+			"due_date":    dueDate,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chore, nil
 }
 
-func (s *ChoreService) GetChoreByID(ctx context.Context, id int) (*model.Chore, error) {
-	return nil, nil // TODO: Implement
+// GetChoreByID returns a chore with its assignments. Workers only see chores
+// assigned to them, and only their own assignments.
+func (s *ChoreService) GetChoreByID(ctx context.Context, actor Actor, id int) (*model.Chore, error) {
+	chore, err := s.getHouseholdChore(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := model.AssignmentFilters{ChoreID: &chore.ID}
+	if actor.Can(model.PermHouseholdView) {
+		chore.Assignments, err = s.store.GetAssignmentsByHousehold(ctx, actor.HouseholdID, filters)
+	} else {
+		chore.Assignments, err = s.store.GetAssignmentsByUser(ctx, actor.HouseholdID, actor.UserID, filters)
+		if err == nil && len(chore.Assignments) == 0 {
+			return nil, fmt.Errorf("chore %w", ErrNotFound)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+
+	// Assignments are already nested under the chore
+	for _, assignment := range chore.Assignments {
+		assignment.Chore = nil
+	}
+	return chore, nil
 }
 
-func (s *ChoreService) GetChoresByHousehold(ctx context.Context, householdID int, filters model.ChoreFilters) ([]*model.Chore, error) {
-	return nil, nil // TODO: Implement
+// GetChoresByHousehold lists the actor's household chores. Workers are always
+// restricted to chores assigned to themselves.
+func (s *ChoreService) GetChoresByHousehold(ctx context.Context, actor Actor, filters model.ChoreFilters) ([]*model.Chore, error) {
+	if !actor.Can(model.PermHouseholdView) {
+		filters.AssignedTo = &actor.UserID
+	}
+	filters.Limit, filters.Offset = normalizePage(filters.Limit, filters.Offset)
+
+	chores, err := s.store.GetChoresByHousehold(ctx, actor.HouseholdID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chores: %w", err)
+	}
+	if chores == nil {
+		chores = []*model.Chore{}
+	}
+	return chores, nil
 }
 
-func (s *ChoreService) UpdateChore(ctx context.Context, chore *model.Chore) error {
-	return nil // TODO: Implement
+func (s *ChoreService) UpdateChore(ctx context.Context, actor Actor, id int, req *model.UpdateChoreRequest) (*model.Chore, error) {
+	if !actor.Can(model.PermChoresUpdate) {
+		return nil, ErrForbidden
+	}
+
+	chore, err := s.getHouseholdChore(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		chore.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		chore.Description = optionalString(req.Description)
+	}
+	if req.Frequency != nil && !sameString(chore.Frequency, optionalString(req.Frequency)) {
+		chore.Frequency = optionalString(req.Frequency)
+		// Restart the recurrence from the latest generated occurrence
+		restart := time.Now()
+		if chore.ScheduledUntil != nil {
+			restart = *chore.ScheduledUntil
+		}
+		chore.ScheduleStart = &restart
+		chore.ScheduledUntil = &restart
+	}
+	if req.Category != nil {
+		chore.Category = optionalString(req.Category)
+	}
+	if req.Priority != nil {
+		chore.Priority = *req.Priority
+	}
+	if req.AutoApprove != nil {
+		chore.AutoApprove = *req.AutoApprove
+	}
+	if req.ProofRequired != nil {
+		chore.ProofRequired = *req.ProofRequired
+	}
+	if req.ExpireDays != nil {
+		chore.ExpireDays = req.ExpireDays
+		if *req.ExpireDays == 0 {
+			chore.ExpireDays = nil
+		}
+	}
+	household, err := getHousehold(ctx, s.store, actor.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Value != nil {
+		if chore.Value, err = parseAmount("value", *req.Value, household.DecimalPlaces); err != nil {
+			return nil, err
+		}
+	}
+	if req.LatePenaltyPct != nil {
+		if chore.LatePenaltyPct, err = parsePercent("late_penalty_pct", *req.LatePenaltyPct); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateChore(chore); err != nil {
+		return nil, err
+	}
+
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.UpdateChore(ctx, chore); err != nil {
+			return fmt.Errorf("failed to update chore: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "chore_updated", map[string]interface{}{
+			"chore_id": chore.ID,
+			"title":    chore.Title,
+			"value":    household.FormatAmount(chore.Value),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chore.UpdatedAt = time.Now()
+	return chore, nil
+}
+
+// DeleteChore removes a chore and its assignments. Ledger entries earned from
+// those assignments are kept.
+func (s *ChoreService) DeleteChore(ctx context.Context, actor Actor, id int) error {
+	if !actor.Can(model.PermChoresDelete) {
+		return ErrForbidden
+	}
+
+	chore, err := s.getHouseholdChore(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+
+	return s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.DeleteChore(ctx, chore.ID); err != nil {
+			return fmt.Errorf("failed to delete chore: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "chore_deleted", map[string]interface{}{
+			"chore_id": chore.ID,
+			"title":    chore.Title,
+		})
+	})
+}
+
+// getHouseholdChore loads a chore and hides chores from other households
+// behind ErrNotFound.
+func (s *ChoreService) getHouseholdChore(ctx context.Context, actor Actor, id int) (*model.Chore, error) {
+	chore, err := s.store.GetChoreByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "chore")
+	}
+	if chore.HouseholdID != actor.HouseholdID {
+		return nil, fmt.Errorf("chore %w", ErrNotFound)
+	}
+	return chore, nil
+}
+
+// householdMembers de-duplicates user IDs and checks that each is an active
+// member of the household.
+func (s *ChoreService) householdMembers(ctx context.Context, householdID int, userIDs []int) ([]int, error) {
+	if len(userIDs) == 0 {
+		return nil, invalidf("assigned_to must list at least one user")
+	}
+
+	users, err := s.store.GetUsersByHousehold(ctx, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household users: %w", err)
+	}
+	members := make(map[int]bool, len(users))
+	for _, user := range users {
+		members[user.ID] = user.Active()
+	}
+
+	seen := make(map[int]bool, len(userIDs))
+	var result []int
+	for _, id := range userIDs {
+		if !members[id] {
+			return nil, invalidf("user %d is not an active member of this household", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+func validateChore(chore *model.Chore) error {
+	if chore.Title == "" {
+		return invalidf("title is required")
+	}
+	switch chore.Priority {
+	case model.PriorityLow, model.PriorityMedium, model.PriorityHigh:
+	default:
+		return invalidf("priority must be low, medium or high")
+	}
+	if chore.ExpireDays != nil && *chore.ExpireDays < 0 {
+		return invalidf("expire_days must not be negative")
+	}
+	if chore.Frequency != nil {
+		if err := recurrence.Validate(*chore.Frequency); err != nil {
+			return invalidf("frequency: %v", err)
+		}
+	}
+	return nil
+}
+
+// parseAmount parses a non-negative decimal string rounded to places places.
+func parseAmount(field, value string, places int) (decimal.Decimal, error) {
+	amount, err := decimal.NewFromString(strings.TrimSpace(value))
+	if err != nil {
+		return decimal.Zero, invalidf("%s must be a decimal number", field)
+	}
+	if amount.IsNegative() {
+		return decimal.Zero, invalidf("%s must not be negative", field)
+	}
+	return amount.Round(int32(places)), nil
 }
 
-func (s *ChoreService) DeleteChore(ctx context.Context, id int) error {
-	return nil // TODO: Implement
-}
\ No newline at end of file
+// parsePercent parses a decimal percentage between 0 and 100.
+func parsePercent(field, value string) (decimal.Decimal, error) {
+	pct, err := parseAmount(field, value, 2)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if pct.GreaterThan(decimal.NewFromInt(100)) {
+		return decimal.Zero, invalidf("%s must be between 0 and 100", field)
+	}
+	return pct, nil
+}
+
+func sameString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// optionalString trims s and maps empty values to nil.
+func optionalString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/choreme/choreme/internal/model"
+)
+
+// Dates and times are stored in UTC and read from requests in the
+// household's timezone when they carry no offset of their own.
+
+// localLayouts are the layouts without an offset that ParseLocalTime reads
+// in a timezone, besides plain dates.
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+const dateLayout = "2006-01-02"
+
+var errInvalidTime = errors.New("invalid time")
+
+// ParseLocalTime parses an RFC 3339 timestamp, a date and time without an
+// offset or a YYYY-MM-DD date. The latter two are read in loc; dateOnly
+// reports a date, which is returned at its midnight.
+func ParseLocalTime(value string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.ParseInLocation(dateLayout, value, loc); err == nil {
+		return t, true, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	return time.Time{}, false, errInvalidTime
+}
+
+// parseDueDate parses a due date in the household's timezone. A date
+// without a time is due by the end of that day there. The result is in UTC.
+func parseDueDate(field, value string, household *model.Household) (time.Time, error) {
+	loc := household.Location()
+	t, dateOnly, err := ParseLocalTime(value, loc)
+	if err != nil {
+		return time.Time{}, invalidf("%s must be an RFC 3339 timestamp, a local date and time or a YYYY-MM-DD date", field)
+	}
+	if dateOnly {
+		t = time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, loc)
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/choreme/choreme/internal/model"
+)
+
+func TestParseDueDate(t *testing.T) {
+	household := model.NewHousehold("Test", time.Now())
+	household.Timezone = "America/New_York"
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		// A date means the end of that local day, whose offset changes
+		// with daylight saving
+		{"2026-03-07", time.Date(2026, 3, 8, 4, 59, 59, 0, time.UTC)},
+		{"2026-03-08", time.Date(2026, 3, 9, 3, 59, 59, 0, time.UTC)},
+		{"2026-10-31", time.Date(2026, 11, 1, 3, 59, 59, 0, time.UTC)},
+		{"2026-11-01", time.Date(2026, 11, 2, 4, 59, 59, 0, time.UTC)},
+		// A time without an offset is local
+		{"2026-03-08T12:00", time.Date(2026, 3, 8, 16, 0, 0, 0, time.UTC)},
+		{"2026-03-07 12:00:00", time.Date(2026, 3, 7, 17, 0, 0, 0, time.UTC)},
+		// An offset wins over the household's timezone
+		{"2026-03-08T12:00:00+01:00", time.Date(2026, 3, 8, 11, 0, 0, 0, time.UTC)},
+		{" 2026-11-01T06:30:00Z ", time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseDueDate("due_date", tt.value, household)
+		if err != nil {
+			t.Errorf("parseDueDate(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("parseDueDate(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "tomorrow", "2026-13-01", "03/08/2026"} {
+		if _, err := parseDueDate("due_date", value, household); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("parseDueDate(%q) = %v, want ErrInvalidInput", value, err)
+		}
+	}
+}
+
+func TestParseLocalTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value    string
+		want     time.Time
+		dateOnly bool
+	}{
+		{"2026-03-29", time.Date(2026, 3, 28, 23, 0, 0, 0, time.UTC), true},
+		{"2026-03-30", time.Date(2026, 3, 29, 22, 0, 0, 0, time.UTC), true},
+		{"2026-10-25T12:00:00", time.Date(2026, 10, 25, 11, 0, 0, 0, time.UTC), false},
+		{"2026-10-25T12:00:00-04:00", time.Date(2026, 10, 25, 16, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		got, dateOnly, err := ParseLocalTime(tt.value, berlin)
+		if err != nil {
+			t.Errorf("ParseLocalTime(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) || dateOnly != tt.dateOnly {
+			t.Errorf("ParseLocalTime(%q) = %v, %v, want %v, %v", tt.value, got, dateOnly, tt.want, tt.dateOnly)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/choreme/choreme/internal/auth"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+// RegisterDevice registers a shared device, such as a family tablet, for the
+// actor's household. The returned token is shown only once; the device
+// presents it to list members and log them in with their PIN.
+func (s *HouseholdService) RegisterDevice(ctx context.Context, actor Actor, name string) (*model.DeviceRegistration, error) {
+	if !actor.Can(model.PermDevicesManage) {
+		return nil, ErrForbidden
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, invalidf("name is required")
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate device token: %w", err)
+	}
+	device := &model.HouseholdDevice{
+		HouseholdID: actor.HouseholdID,
+		Name:        name,
+		TokenHash:   auth.HashToken(token),
+		CreatedBy:   &actor.UserID,
+		CreatedAt:   time.Now(),
+	}
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateHouseholdDevice(ctx, device); err != nil {
+			return fmt.Errorf("failed to create device: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "device_registered", map[string]interface{}{
+			"device_id": device.ID,
+			"name":      device.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.DeviceRegistration{Device: device, Token: token}, nil
+}
+
+// GetDevices lists the household's devices, including revoked ones.
+func (s *HouseholdService) GetDevices(ctx context.Context, actor Actor) ([]*model.HouseholdDevice, error) {
+	if !actor.Can(model.PermDevicesManage) {
+		return nil, ErrForbidden
+	}
+	devices, err := s.store.GetHouseholdDevices(ctx, actor.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+	return devices, nil
+}
+
+// RevokeDevice stops a device from listing members and logging them in.
+// Access tokens it already issued stay valid until they expire.
+func (s *HouseholdService) RevokeDevice(ctx context.Context, actor Actor, id int) error {
+	if !actor.Can(model.PermDevicesManage) {
+		return ErrForbidden
+	}
+	return s.store.WithTx(ctx, func(tx store.Store) error {
+		device, err := tx.GetHouseholdDeviceByID(ctx, id)
+		if err != nil {
+			return notFound(err, "device")
+		}
+		if device.HouseholdID != actor.HouseholdID {
+			return fmt.Errorf("device %w", ErrNotFound)
+		}
+		if device.RevokedAt != nil {
+			return nil
+		}
+		if err := tx.RevokeHouseholdDevice(ctx, device.ID, time.Now()); err != nil {
+			return fmt.Errorf("failed to revoke device: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "device_revoked", map[string]interface{}{
+			"device_id": device.ID,
+			"name":      device.Name,
+		})
+	})
+}
+
+// AuthenticateDevice looks up the device a token belongs to and records its
+// use. Unknown and revoked tokens are refused.
+func (s *HouseholdService) AuthenticateDevice(ctx context.Context, token string) (*model.HouseholdDevice, error) {
+	device, err := s.store.GetHouseholdDeviceByTokenHash(ctx, auth.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: invalid device token", ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+	if device.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: device has been revoked", ErrUnauthorized)
+	}
+	if err := checkHousehold(ctx, s.store, device.HouseholdID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.store.TouchHouseholdDevice(ctx, device.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to update device: %w", err)
+	}
+	device.LastUsedAt = &now
+	return device, nil
+}
+
+// GetDeviceMembers lists the active members of the device's household for
+// picking who logs in.
+func (s *HouseholdService) GetDeviceMembers(ctx context.Context, device *model.HouseholdDevice) ([]model.DeviceMember, error) {
+	users, err := s.store.GetUsersByHousehold(ctx, device.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	members := make([]model.DeviceMember, 0, len(users))
+	for _, user := range users {
+		if !user.Active() {
+			continue
+		}
+		members = append(members, model.DeviceMember{
+			ID:     user.ID,
+			Name:   user.Name,
+			Role:   user.Role,
+			HasPIN: user.PINHash != nil,
+		})
+	}
+	return members, nil
+}
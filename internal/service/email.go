@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/choreme/choreme/internal/auth"
+	"github.com/choreme/choreme/internal/mailer"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
+)
+
+// ErrInvalidToken is returned for mailed tokens that are unknown, expired or
+// already used.
+var ErrInvalidToken = fmt.Errorf("%w: invalid or expired token", ErrInvalidInput)
+
+// ForgotPassword mails a password reset link to the user with the given
+// email. Unknown addresses are silently ignored so the endpoint can't be used
+// to find out who has an account.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.store.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.Active() {
+		return nil
+	}
+
+	var raw string
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		raw, err = s.issueUserToken(ctx, tx, user, model.TokenPurposePasswordReset, passwordResetTTL)
+		if err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "password_reset_requested", map[string]interface{}{
+			"user_id": user.ID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your ChoreMe password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your ChoreMe account. To choose a new one, open:\n\n"+
+			"%s\n\n"+
+			"The link works once and expires in an hour. If you didn't ask for this, ignore this email.\n",
+			user.Name, s.link("/reset-password", raw)),
+	})
+	return nil
+}
+
+// ResetPassword sets a new password with a token from ForgotPassword. The
+// user's sessions all end, since the old password may have been compromised.
+// Reaching the mailbox also proves the address, so it counts as verified.
+func (s *AuthService) ResetPassword(ctx context.Context, token, password string) error {
+	if len(password) < 6 {
+		return invalidf("password must be at least 6 characters")
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var userID int
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		user, userToken, err := s.useUserToken(ctx, tx, token, model.TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+		userID = user.ID
+
+		now := time.Now()
+		user.PasswordHash = hash
+		if user.EmailVerifiedAt == nil && strings.EqualFold(userToken.Email, user.Email) {
+			user.EmailVerifiedAt = &now
+		}
+		if err := tx.UpdateUser(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if err := tx.RevokeUserRefreshTokens(ctx, user.ID, now); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		// Proving control of the mailbox lifts a lockout
+		if err := tx.UpdateLoginFailures(ctx, user.ID, 0, nil); err != nil {
+			return fmt.Errorf("failed to reset failed logins: %w", err)
+		}
+		return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "password_reset", map[string]interface{}{
+			"user_id": user.ID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.sessions.Invalidate(userID)
+	return nil
+}
+
+// SendEmailVerification mails a link that verifies the user's current email
+// address. Failing to send is logged rather than returned, so it never
+// blocks the registration or profile change that triggered it.
+func (s *AuthService) SendEmailVerification(ctx context.Context, user *model.User) {
+	raw, err := s.issueUserToken(ctx, s.store, user, model.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		log.Printf("Failed to create email verification for user %d: %v", user.ID, err)
+		return
+	}
+
+	s.send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your ChoreMe email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Please confirm that this is your email address by opening:\n\n"+
+			"%s\n\n"+
+			"The link expires in two days.\n",
+			user.Name, s.link("/verify-email", raw)),
+	})
+}
+
+// ResendEmailVerification mails the actor a new verification link.
+func (s *AuthService) ResendEmailVerification(ctx context.Context, actor Actor) error {
+	user, err := s.store.GetUserByID(ctx, actor.UserID)
+	if err != nil {
+		return notFound(err, "user")
+	}
+	if user.EmailVerifiedAt != nil {
+		return fmt.Errorf("%w: email address is already verified", ErrConflict)
+	}
+	s.SendEmailVerification(ctx, user)
+	return nil
+}
+
+// VerifyEmail marks the address a verification token was sent to as
+// verified, as long as it is still the user's address.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	var user *model.User
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var userToken *model.UserToken
+		var err error
+		user, userToken, err = s.useUserToken(ctx, tx, token, model.TokenPurposeEmailVerification)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(userToken.Email, user.Email) {
+			return ErrInvalidToken
+		}
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := tx.UpdateUser(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "email_verified", map[string]interface{}{
+			"user_id": user.ID,
+			"email":   user.Email,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// issueUserToken creates a token for purpose through st, replacing any
+// earlier unused one, and returns the token to mail.
+func (s *AuthService) issueUserToken(ctx context.Context, st store.Store, user *model.User, purpose model.TokenPurpose, ttl time.Duration) (string, error) {
+	raw, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	now := time.Now()
+	err = st.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.InvalidateUserTokens(ctx, user.ID, purpose, now); err != nil {
+			return fmt.Errorf("failed to invalidate tokens: %w", err)
+		}
+		err := tx.CreateUserToken(ctx, &model.UserToken{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			TokenHash: auth.HashToken(raw),
+			Email:     user.Email,
+			ExpiresAt: now.Add(ttl),
+			CreatedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// useUserToken locks a mailed token, checks it, marks it used and returns it
+// with its user.
+func (s *AuthService) useUserToken(ctx context.Context, tx store.Store, token string, purpose model.TokenPurpose) (*model.User, *model.UserToken, error) {
+	user, userToken, err := s.checkUserToken(ctx, tx, token, purpose)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := tx.MarkUserTokenUsed(ctx, userToken.ID, time.Now()); err != nil {
+		return nil, nil, fmt.Errorf("failed to use token: %w", err)
+	}
+	return user, userToken, nil
+}
+
+// checkUserToken locks a token and its user and checks the token is unused,
+// unexpired and for purpose, without using it up.
+func (s *AuthService) checkUserToken(ctx context.Context, tx store.Store, token string, purpose model.TokenPurpose) (*model.User, *model.UserToken, error) {
+	userToken, err := tx.GetUserTokenForUpdate(ctx, auth.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	if userToken.Purpose != purpose || userToken.UsedAt != nil || !time.Now().Before(userToken.ExpiresAt) {
+		return nil, nil, ErrInvalidToken
+	}
+
+	user, err := tx.GetUserForUpdate(ctx, userToken.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, userToken, nil
+}
+
+func (s *AuthService) link(path, token string) string {
+	return strings.TrimRight(s.baseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// send delivers a message, logging failures; callers have already committed
+// the change the message is about.
+func (s *AuthService) send(ctx context.Context, msg *mailer.Message) {
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send %q to %s: %v", msg.Subject, msg.To, err)
+	}
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by services. Handlers map them to HTTP status
+// codes; wrap them with context using fmt.Errorf("...: %w", err).
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("insufficient permissions")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrConflict     = errors.New("conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// invalidf reports a validation failure that wraps ErrInvalidInput.
+func invalidf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidInput, fmt.Sprintf(format, args...))
+}
+
+// notFound translates a missing row into ErrNotFound and passes any other
+// store error through unchanged.
+func notFound(err error, what string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s %w", what, ErrNotFound)
+	}
+	return err
+}
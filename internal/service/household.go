@@ -2,29 +2,204 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/choreme/choreme/internal/model"
 	"github.com/choreme/choreme/internal/store"
 )
 
+// maxScheduleDaysAhead caps how far ahead recurring chores are generated.
+const maxScheduleDaysAhead = 365
+
+// maxPointsLabel is the longest label points can be counted under.
+const maxPointsLabel = 32
+
+var (
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	localePattern   = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+)
+
 type HouseholdService struct {
-	store store.Store
-	audit *AuditService
+	store       store.Store
+	audit       *AuditService
+	sessions    *SessionCache
+	permissions *permissionCache
 }
 
-func NewHouseholdService(store store.Store, audit *AuditService) *HouseholdService {
+// NewHouseholdService caches households' role permissions as long as
+// sessions caches session epochs.
+func NewHouseholdService(store store.Store, audit *AuditService, sessions *SessionCache) *HouseholdService {
 	return &HouseholdService{
-		store: store,
-		audit: audit,
+		store:       store,
+		audit:       audit,
+		sessions:    sessions,
+		permissions: newPermissionCache(sessions.ttl),
+	}
+}
+
+// ErrHouseholdSuspended is returned when a member of a suspended household
+// tries to log in to it or use it.
+var ErrHouseholdSuspended = fmt.Errorf("%w: household is suspended", ErrUnauthorized)
+
+// checkHousehold returns ErrHouseholdSuspended when the household is
+// suspended.
+func checkHousehold(ctx context.Context, st store.Store, householdID int) error {
+	household, err := st.GetHouseholdByID(ctx, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to get household: %w", err)
 	}
+	if household.SuspendedAt != nil {
+		return ErrHouseholdSuspended
+	}
+	return nil
 }
 
-func (s *HouseholdService) UpdateInviteCode(ctx context.Context, householdID int, inviteCode string) error {
-	err := s.store.UpdateHouseholdInviteCode(ctx, householdID, inviteCode)
+// getHousehold returns a household with its settings.
+func getHousehold(ctx context.Context, st store.Store, householdID int) (*model.Household, error) {
+	household, err := st.GetHouseholdByID(ctx, householdID)
+	if err != nil {
+		return nil, notFound(err, "household")
+	}
+	return household, nil
+}
+
+// GetSettings returns the actor's household.
+func (s *HouseholdService) GetSettings(ctx context.Context, actor Actor) (*model.Household, error) {
+	return getHousehold(ctx, s.store, actor.HouseholdID)
+}
+
+func (s *HouseholdService) UpdateSettings(ctx context.Context, actor Actor, req *model.UpdateHouseholdSettingsRequest) (*model.Household, error) {
+	if !actor.Can(model.PermHouseholdSettings) {
+		return nil, ErrForbidden
+	}
+
+	var household *model.Household
+	var endedSessions []int
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		household, err = tx.GetHouseholdByID(ctx, actor.HouseholdID)
+		if err != nil {
+			return notFound(err, "household")
+		}
+
+		details := map[string]interface{}{}
+		if req.AllowNegativeBalance != nil {
+			household.AllowNegativeBalance = *req.AllowNegativeBalance
+			details["allow_negative_balance"] = household.AllowNegativeBalance
+		}
+		if req.ScheduleDaysAhead != nil {
+			if *req.ScheduleDaysAhead < 1 || *req.ScheduleDaysAhead > maxScheduleDaysAhead {
+				return invalidf("schedule_days_ahead must be between 1 and %d", maxScheduleDaysAhead)
+			}
+			household.ScheduleDaysAhead = *req.ScheduleDaysAhead
+			details["schedule_days_ahead"] = household.ScheduleDaysAhead
+		}
+		if req.RequireTwoFactor != nil && *req.RequireTwoFactor != household.RequireTwoFactor {
+			if *req.RequireTwoFactor {
+				if endedSessions, err = s.requireTwoFactor(ctx, tx, actor); err != nil {
+					return err
+				}
+			}
+			household.RequireTwoFactor = *req.RequireTwoFactor
+			details["require_two_factor"] = household.RequireTwoFactor
+		}
+		if err := applyLocaleSettings(household, req, details); err != nil {
+			return err
+		}
+
+		if err := tx.UpdateHousehold(ctx, household); err != nil {
+			return fmt.Errorf("failed to update household: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "household_settings_updated", details)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	for _, userID := range endedSessions {
+		s.sessions.Invalidate(userID)
+	}
+	return household, nil
+}
 
-	// This would need user context from the service call
-	// For now, we'll skip audit logging here
+// applyLocaleSettings validates and applies the settings that decide how the
+// household counts values and tells time, recording them in details.
+func applyLocaleSettings(household *model.Household, req *model.UpdateHouseholdSettingsRequest, details map[string]interface{}) error {
+	if req.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*req.Currency))
+		if !currencyPattern.MatchString(currency) {
+			return invalidf("currency must be a three-letter ISO 4217 code")
+		}
+		household.Currency = currency
+		details["currency"] = currency
+	}
+	if req.PointsLabel != nil {
+		label := optionalString(req.PointsLabel)
+		if label != nil && len(*label) > maxPointsLabel {
+			return invalidf("points_label must be at most %d characters", maxPointsLabel)
+		}
+		household.PointsLabel = label
+		details["points_label"] = label
+	}
+	if req.DecimalPlaces != nil {
+		if *req.DecimalPlaces < 0 || *req.DecimalPlaces > model.MaxDecimalPlaces {
+			return invalidf("decimal_places must be between 0 and %d", model.MaxDecimalPlaces)
+		}
+		household.DecimalPlaces = *req.DecimalPlaces
+		details["decimal_places"] = household.DecimalPlaces
+	}
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		// LoadLocation takes "" and "Local" too, which aren't IANA names
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || timezone == "Local" {
+			return invalidf("timezone must be an IANA timezone such as Europe/Berlin")
+		}
+		household.Timezone = timezone
+		details["timezone"] = timezone
+	}
+	if req.WeekStart != nil {
+		if *req.WeekStart < int(time.Sunday) || *req.WeekStart > int(time.Saturday) {
+			return invalidf("week_start must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		household.WeekStart = time.Weekday(*req.WeekStart)
+		details["week_start"] = *req.WeekStart
+	}
+	if req.Locale != nil {
+		locale := strings.TrimSpace(*req.Locale)
+		if !localePattern.MatchString(locale) {
+			return invalidf("locale must be a BCP 47 language tag such as en-US")
+		}
+		household.Locale = locale
+		details["locale"] = locale
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// requireTwoFactor prepares the household for mandatory two-factor
+// authentication. The actor must use it already so they can't lock
+// themselves out. Other managing members without it have their access
+// tokens invalidated; refreshing is refused for them too, so they set it up
+// when they log in again. Their IDs are returned.
+func (s *HouseholdService) requireTwoFactor(ctx context.Context, tx store.Store, actor Actor) ([]int, error) {
+	users, err := tx.GetUsersByHousehold(ctx, actor.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	var ended []int
+	for _, user := range users {
+		if !managingRole(user.Role) || user.TwoFactorEnabled() {
+			continue
+		}
+		if user.ID == actor.UserID {
+			return nil, invalidf("enable two-factor authentication on your own account first")
+		}
+		if err := tx.BumpSessionEpoch(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to end sessions: %w", err)
+		}
+		ended = append(ended, user.ID)
+	}
+	return ended, nil
+}
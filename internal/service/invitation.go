@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/choreme/choreme/internal/mailer"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+// maxInviteDays caps how long an invitation can stay open.
+const maxInviteDays = 90
+
+// ErrInvalidInvite is returned for invite codes that are unknown, expired,
+// revoked, used up or bound to another email address.
+var ErrInvalidInvite = fmt.Errorf("%w: invalid or expired invite code", ErrInvalidInput)
+
+// inviteEncoding writes invite codes in lower case so they are easy to read
+// out and type.
+var inviteEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// CreateInvitation opens an invitation to the actor's household. Invitations
+// bound to an email address are mailed there.
+func (s *AuthService) CreateInvitation(ctx context.Context, actor Actor, req *model.CreateInvitationRequest) (*model.Invitation, error) {
+	if !actor.Can(model.PermHouseholdInvite) {
+		return nil, ErrForbidden
+	}
+
+	role := req.Role
+	if role == "" {
+		role = model.RoleWorker
+	}
+	switch role {
+	case model.RoleAdmin, model.RoleManager, model.RoleWorker, model.RoleObserver:
+	default:
+		return nil, invalidf("invalid role %q", role)
+	}
+	if !canManageRole(actor.Role, role) {
+		return nil, fmt.Errorf("%w: you can't invite members as %s", ErrForbidden, role)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.inviteTTL)
+	if req.ExpiresInDays != nil {
+		days := *req.ExpiresInDays
+		if days < 1 || days > maxInviteDays {
+			return nil, invalidf("expires_in_days must be between 1 and %d", maxInviteDays)
+		}
+		expiresAt = now.AddDate(0, 0, days)
+	}
+
+	maxUses := req.MaxUses
+	if maxUses != nil && *maxUses < 1 {
+		return nil, invalidf("max_uses must be at least 1")
+	}
+	var email *string
+	if req.Email != nil && strings.TrimSpace(*req.Email) != "" {
+		e := strings.TrimSpace(*req.Email)
+		email = &e
+		one := 1
+		maxUses = &one
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	invitation := &model.Invitation{
+		HouseholdID: actor.HouseholdID,
+		Code:        code,
+		Role:        role,
+		Email:       email,
+		MaxUses:     maxUses,
+		ExpiresAt:   &expiresAt,
+		CreatedBy:   &actor.UserID,
+		CreatedAt:   now,
+	}
+	var household *model.Household
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		household, err = tx.GetHouseholdByID(ctx, actor.HouseholdID)
+		if err != nil {
+			return notFound(err, "household")
+		}
+		if err := tx.CreateInvitation(ctx, invitation); err != nil {
+			return fmt.Errorf("failed to create invitation: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "invitation_created", map[string]interface{}{
+			"invitation_id": invitation.ID,
+			"role":          invitation.Role,
+			"email":         invitation.Email,
+			"max_uses":      invitation.MaxUses,
+			"expires_at":    invitation.ExpiresAt,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	invitation.Link = s.inviteLink(invitation.Code)
+
+	if email != nil {
+		s.send(ctx, &mailer.Message{
+			To:      *email,
+			Subject: fmt.Sprintf("You're invited to %s on ChoreMe", household.Name),
+			Body: fmt.Sprintf("Hi,\n\n"+
+				"You're invited to join %s on ChoreMe as %s. To create your account, open:\n\n"+
+				"%s\n\n"+
+				"or enter the invite code %s. The invitation expires on %s.\n",
+				household.Name, role, invitation.Link, invitation.Code, expiresAt.Format("January 2, 2006")),
+		})
+	}
+	return invitation, nil
+}
+
+// GetInvitations lists the invitations of the actor's household, newest
+// first, used up and revoked ones included.
+func (s *AuthService) GetInvitations(ctx context.Context, actor Actor) ([]*model.Invitation, error) {
+	if !actor.Can(model.PermHouseholdInvite) {
+		return nil, ErrForbidden
+	}
+	invitations, err := s.store.GetInvitations(ctx, actor.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+	if invitations == nil {
+		invitations = []*model.Invitation{}
+	}
+	for _, invitation := range invitations {
+		invitation.Link = s.inviteLink(invitation.Code)
+	}
+	return invitations, nil
+}
+
+// GetInvitation returns an invitation of the actor's household.
+func (s *AuthService) GetInvitation(ctx context.Context, actor Actor, id int) (*model.Invitation, error) {
+	if !actor.Can(model.PermHouseholdInvite) {
+		return nil, ErrForbidden
+	}
+	invitation, err := s.store.GetInvitationByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "invitation")
+	}
+	if invitation.HouseholdID != actor.HouseholdID {
+		return nil, fmt.Errorf("invitation %w", ErrNotFound)
+	}
+	invitation.Link = s.inviteLink(invitation.Code)
+	return invitation, nil
+}
+
+// RevokeInvitation stops an invitation from being used. Members who already
+// joined with it stay.
+func (s *AuthService) RevokeInvitation(ctx context.Context, actor Actor, id int) error {
+	invitation, err := s.GetInvitation(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+	return s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.RevokeInvitation(ctx, invitation.ID, time.Now()); err != nil {
+			return fmt.Errorf("failed to revoke invitation: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "invitation_revoked", map[string]interface{}{
+			"invitation_id": invitation.ID,
+		})
+	})
+}
+
+// useInvitation checks an invite code for someone joining with email and
+// counts the use.
+func (s *AuthService) useInvitation(ctx context.Context, tx store.Store, code, email string, now time.Time) (*model.Invitation, error) {
+	invitation, err := tx.GetInvitationByCodeForUpdate(ctx, normalizeInviteCode(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidInvite
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if !invitation.Usable(now) {
+		return nil, ErrInvalidInvite
+	}
+	if err := checkHousehold(ctx, tx, invitation.HouseholdID); err != nil {
+		return nil, ErrInvalidInvite
+	}
+	if invitation.Email != nil && !strings.EqualFold(*invitation.Email, strings.TrimSpace(email)) {
+		return nil, ErrInvalidInvite
+	}
+	if err := tx.UseInvitation(ctx, invitation.ID); err != nil {
+		return nil, fmt.Errorf("failed to use invitation: %w", err)
+	}
+	invitation.Uses++
+	return invitation, nil
+}
+
+func (s *AuthService) inviteLink(code string) string {
+	return strings.TrimRight(s.baseURL, "/") + "/?code=" + url.QueryEscape(code)
+}
+
+func newInviteCode() (string, error) {
+	bytes := make([]byte, 10)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	return inviteEncoding.EncodeToString(bytes), nil
+}
+
+// normalizeInviteCode undoes what people do to codes they type: spaces
+// around them and upper case. Codes from before invitations were hex, which
+// is lower case already.
+func normalizeInviteCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
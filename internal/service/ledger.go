@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/choreme/choreme/internal/model"
 	"github.com/choreme/choreme/internal/store"
@@ -20,23 +23,188 @@ func NewLedgerService(store store.Store, audit *AuditService) *LedgerService {
 	}
 }
 
-// Placeholder implementations - to be completed
-func (s *LedgerService) CreateLedgerEntry(ctx context.Context, entry *model.LedgerEntry) error {
-	return nil // TODO: Implement
+// CreateLedgerEntry validates and writes an entry through st, which may be a
+// transaction. Amounts are signed: earnings are positive, spending negative
+// and adjustments either way. Entries count towards the user's balance in
+// the entry's household only.
+func (s *LedgerService) CreateLedgerEntry(ctx context.Context, st store.Store, entry *model.LedgerEntry) error {
+	entry.Amount = entry.Amount.Round(2)
+	switch entry.Type {
+	case model.LedgerTypeEarn:
+		if entry.Amount.IsNegative() {
+			return invalidf("earn entries must not be negative")
+		}
+	case model.LedgerTypeSpend:
+		if entry.Amount.IsPositive() {
+			return invalidf("spend entries must not be positive")
+		}
+	case model.LedgerTypeAdjust:
+		if entry.Amount.IsZero() {
+			return invalidf("adjustments must not be zero")
+		}
+	default:
+		return invalidf("unknown ledger entry type %q", entry.Type)
+	}
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if err := st.CreateLedgerEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to create ledger entry: %w", err)
+	}
+	return nil
+}
+
+// GetLedgerEntriesByUser lists one member's entries. Workers may only list
+// their own.
+func (s *LedgerService) GetLedgerEntriesByUser(ctx context.Context, actor Actor, userID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error) {
+	if err := s.checkMember(ctx, actor, userID); err != nil {
+		return nil, err
+	}
+	filters.Limit, filters.Offset = normalizePage(filters.Limit, filters.Offset)
+
+	entries, err := s.store.GetLedgerEntriesByUser(ctx, actor.HouseholdID, userID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger entries: %w", err)
+	}
+	if entries == nil {
+		entries = []*model.LedgerEntry{}
+	}
+	return entries, nil
+}
+
+// GetLedgerEntriesByHousehold lists the whole household's entries, falling
+// back to the actor's own entries for workers.
+func (s *LedgerService) GetLedgerEntriesByHousehold(ctx context.Context, actor Actor, filters model.LedgerFilters) ([]*model.LedgerEntry, error) {
+	if !actor.Can(model.PermHouseholdView) {
+		return s.GetLedgerEntriesByUser(ctx, actor, actor.UserID, filters)
+	}
+	filters.Limit, filters.Offset = normalizePage(filters.Limit, filters.Offset)
+
+	entries, err := s.store.GetLedgerEntriesByHousehold(ctx, actor.HouseholdID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger entries: %w", err)
+	}
+	if entries == nil {
+		entries = []*model.LedgerEntry{}
+	}
+	return entries, nil
 }
 
-func (s *LedgerService) GetLedgerEntriesByUser(ctx context.Context, userID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error) {
-	return nil, nil // TODO: Implement
+func (s *LedgerService) GetUserBalance(ctx context.Context, actor Actor, userID int) (decimal.Decimal, error) {
+	if err := s.checkMember(ctx, actor, userID); err != nil {
+		return decimal.Zero, err
+	}
+
+	balance, err := s.store.GetUserBalance(ctx, actor.HouseholdID, userID)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get balance: %w", err)
+	}
+	return balance, nil
 }
 
-func (s *LedgerService) GetLedgerEntriesByHousehold(ctx context.Context, householdID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error) {
-	return nil, nil // TODO: Implement
+// GetBalanceSummary returns a member's balance along with the amount held by
+// pending redemptions.
+func (s *LedgerService) GetBalanceSummary(ctx context.Context, actor Actor, userID int) (*model.BalanceSummary, error) {
+	if err := s.checkMember(ctx, actor, userID); err != nil {
+		return nil, err
+	}
+	return s.balanceSummary(ctx, s.store, actor.HouseholdID, userID)
 }
 
-func (s *LedgerService) GetUserBalance(ctx context.Context, userID int) (decimal.Decimal, error) {
-	return decimal.Zero, nil // TODO: Implement
+// balanceSummary reads a member's balance and holds through st, which may be
+// a transaction.
+func (s *LedgerService) balanceSummary(ctx context.Context, st store.Store, householdID, userID int) (*model.BalanceSummary, error) {
+	balance, err := st.GetUserBalance(ctx, householdID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	held, err := st.GetHeldBalance(ctx, householdID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get held balance: %w", err)
+	}
+	return &model.BalanceSummary{
+		UserID:    userID,
+		Balance:   balance,
+		Held:      held,
+		Available: balance.Sub(held),
+	}, nil
 }
 
-func (s *LedgerService) AdjustBalance(ctx context.Context, userID int, amount decimal.Decimal, description string) error {
-	return nil // TODO: Implement
-}
\ No newline at end of file
+func (s *LedgerService) GetAllUserBalances(ctx context.Context, actor Actor) ([]*model.UserBalance, error) {
+	if !actor.Can(model.PermHouseholdView) {
+		return nil, ErrForbidden
+	}
+
+	balances, err := s.store.GetAllUserBalances(ctx, actor.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", err)
+	}
+	if balances == nil {
+		balances = []*model.UserBalance{}
+	}
+	return balances, nil
+}
+
+// AdjustBalance records a manual, signed adjustment with a mandatory note.
+func (s *LedgerService) AdjustBalance(ctx context.Context, actor Actor, userID int, amount decimal.Decimal, description string) (*model.LedgerEntry, error) {
+	if !actor.Can(model.PermLedgerAdjust) {
+		return nil, ErrForbidden
+	}
+	if err := s.checkMember(ctx, actor, userID); err != nil {
+		return nil, err
+	}
+	user, err := s.store.GetMember(ctx, actor.HouseholdID, userID)
+	if err != nil {
+		return nil, notFound(err, "user")
+	}
+	if user.RemovedAt != nil {
+		return nil, invalidf("%s was removed from the household", user.Name)
+	}
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil, invalidf("description is required for adjustments")
+	}
+	household, err := getHousehold(ctx, s.store, actor.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &model.LedgerEntry{
+		HouseholdID: actor.HouseholdID,
+		UserID:      userID,
+		Type:        model.LedgerTypeAdjust,
+		Amount:      household.Round(amount),
+		Description: &description,
+	}
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := s.CreateLedgerEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "ledger_adjusted", map[string]interface{}{
+			"ledger_id":   entry.ID,
+			"user_id":     userID,
+			"amount":      household.FormatAmount(entry.Amount),
+			"description": description,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// checkMember allows workers to reach only their own ledger and everyone else
+// only members of their household, past ones included.
+func (s *LedgerService) checkMember(ctx context.Context, actor Actor, userID int) error {
+	if userID == actor.UserID {
+		return nil
+	}
+	if !actor.Can(model.PermHouseholdView) {
+		return ErrForbidden
+	}
+	if _, err := s.store.GetMember(ctx, actor.HouseholdID, userID); err != nil {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,409 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+// roleRank orders roles by how much of the household they run. Members can
+// only manage members ranked below them; admins can manage other admins too.
+func roleRank(role model.Role) int {
+	switch role {
+	case model.RoleSystemAdmin:
+		return 4
+	case model.RoleAdmin:
+		return 3
+	case model.RoleManager:
+		return 2
+	}
+	return 1
+}
+
+// canManageRole reports whether a member with role actor may manage members
+// with role target, or give members that role.
+func canManageRole(actor, target model.Role) bool {
+	if roleRank(actor) >= roleRank(model.RoleAdmin) {
+		return roleRank(target) <= roleRank(actor)
+	}
+	return roleRank(target) < roleRank(actor)
+}
+
+// GetMembers lists the actor's household members, deactivated ones included.
+func (s *UserService) GetMembers(ctx context.Context, actor Actor) ([]*model.User, error) {
+	if !actor.Can(model.PermMembersView) {
+		return nil, ErrForbidden
+	}
+	users, err := s.store.GetUsersByHousehold(ctx, actor.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %w", err)
+	}
+	return users, nil
+}
+
+// GetMember returns a member of the actor's household.
+func (s *UserService) GetMember(ctx context.Context, actor Actor, userID int) (*model.User, error) {
+	if !actor.Can(model.PermMembersView) {
+		return nil, ErrForbidden
+	}
+	user, err := s.store.GetMember(ctx, actor.HouseholdID, userID)
+	if err != nil {
+		return nil, notFound(err, "member")
+	}
+	if user.RemovedAt != nil {
+		return nil, fmt.Errorf("member %w", ErrNotFound)
+	}
+	return user, nil
+}
+
+// UpdateMember renames a member or changes their role. Members can step down
+// themselves, but the household always keeps an active admin.
+func (s *UserService) UpdateMember(ctx context.Context, actor Actor, userID int, req *model.UpdateMemberRequest) (*model.User, error) {
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		return nil, invalidf("name can't be empty")
+	}
+	if req.Role != nil {
+		switch *req.Role {
+		case model.RoleAdmin, model.RoleManager, model.RoleWorker, model.RoleObserver:
+		default:
+			return nil, invalidf("invalid role %q", *req.Role)
+		}
+		if !canManageRole(actor.Role, *req.Role) {
+			return nil, fmt.Errorf("%w: you can't make members %s", ErrForbidden, *req.Role)
+		}
+	}
+
+	var user *model.User
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		user, err = s.managedMember(ctx, tx, actor, userID, true)
+		if err != nil {
+			return err
+		}
+
+		details := map[string]interface{}{"user_id": user.ID}
+		if req.Name != nil && strings.TrimSpace(*req.Name) != user.Name {
+			details["old_name"] = user.Name
+			user.Name = strings.TrimSpace(*req.Name)
+			details["name"] = user.Name
+		}
+		if req.Role != nil && *req.Role != user.Role {
+			if user.Role == model.RoleSystemAdmin {
+				return invalidf("system admins keep their role")
+			}
+			if roleRank(*req.Role) < roleRank(model.RoleAdmin) {
+				if err := s.keepAdmin(ctx, tx, user); err != nil {
+					return err
+				}
+			}
+			details["old_role"] = user.Role
+			user.Role = *req.Role
+			details["role"] = user.Role
+		}
+		if len(details) == 1 {
+			return nil
+		}
+
+		user.UpdatedAt = time.Now()
+		if err := tx.UpdateUser(ctx, user); err != nil {
+			return fmt.Errorf("failed to update member: %w", err)
+		}
+		action := "member_updated"
+		if _, ok := details["role"]; ok {
+			action = "member_role_changed"
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, action, details)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.sessions.Invalidate(user.ID)
+	return user, nil
+}
+
+// DeactivateMember suspends a member: their sessions and refresh tokens end
+// and they can't log in, be assigned chores or redeem rewards until they are
+// reactivated. Everything they did stays.
+func (s *UserService) DeactivateMember(ctx context.Context, actor Actor, userID int) (*model.User, error) {
+	var user *model.User
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		user, err = s.managedMember(ctx, tx, actor, userID, false)
+		if err != nil {
+			return err
+		}
+		if user.DeactivatedAt != nil {
+			return fmt.Errorf("%w: member is already deactivated", ErrConflict)
+		}
+		if err := s.keepAdmin(ctx, tx, user); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user.DeactivatedAt = &now
+		if err := tx.UpdateUserStatus(ctx, user); err != nil {
+			return fmt.Errorf("failed to deactivate member: %w", err)
+		}
+		if err := s.endSessions(ctx, tx, user.ID, now); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "member_deactivated", map[string]interface{}{
+			"user_id": user.ID,
+			"name":    user.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.sessions.Invalidate(user.ID)
+	return user, nil
+}
+
+// ReactivateMember lets a deactivated member log in again.
+func (s *UserService) ReactivateMember(ctx context.Context, actor Actor, userID int) (*model.User, error) {
+	var user *model.User
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		user, err = s.managedMember(ctx, tx, actor, userID, false)
+		if err != nil {
+			return err
+		}
+		if user.DeactivatedAt == nil {
+			return fmt.Errorf("%w: member is not deactivated", ErrConflict)
+		}
+
+		user.DeactivatedAt = nil
+		if err := tx.UpdateUserStatus(ctx, user); err != nil {
+			return fmt.Errorf("failed to reactivate member: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "member_reactivated", map[string]interface{}{
+			"user_id": user.ID,
+			"name":    user.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RemoveMember takes a member out of the household for good. Their open
+// assignments and the API tokens they made for the household are dropped.
+// When it was their last household their login details and linked
+// identities go too, but the user row stays with its name so the ledger,
+// completed assignments, redemptions and audit log keep their history.
+func (s *UserService) RemoveMember(ctx context.Context, actor Actor, userID int) error {
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		user, err := s.managedMember(ctx, tx, actor, userID, false)
+		if err != nil {
+			return err
+		}
+		if err := s.keepAdmin(ctx, tx, user); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		dropped, err := s.dropOpenAssignments(ctx, tx, actor.HouseholdID, user.ID)
+		if err != nil {
+			return err
+		}
+		user.RemovedAt = &now
+		if err := tx.UpdateUserStatus(ctx, user); err != nil {
+			return fmt.Errorf("failed to remove member: %w", err)
+		}
+		if err := s.leaveHousehold(ctx, tx, user, now); err != nil {
+			return err
+		}
+		if err := s.endSessions(ctx, tx, user.ID, now); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "member_removed", map[string]interface{}{
+			"user_id":             user.ID,
+			"name":                user.Name,
+			"assignments_dropped": dropped,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.sessions.Invalidate(userID)
+	return nil
+}
+
+// managedMember loads a member of the actor's household for a change the
+// actor makes to them. Deactivating or removing oneself isn't allowed, since
+// only someone else could undo it.
+func (s *UserService) managedMember(ctx context.Context, tx store.Store, actor Actor, userID int, allowSelf bool) (*model.User, error) {
+	if !actor.Can(model.PermMembersManage) {
+		return nil, ErrForbidden
+	}
+	user, err := tx.GetMemberForUpdate(ctx, actor.HouseholdID, userID)
+	if err != nil {
+		return nil, notFound(err, "member")
+	}
+	if user.RemovedAt != nil {
+		return nil, fmt.Errorf("member %w", ErrNotFound)
+	}
+	if user.ID == actor.UserID {
+		if !allowSelf {
+			return nil, invalidf("you can't do this to your own membership")
+		}
+		return user, nil
+	}
+	if !canManageRole(actor.Role, user.Role) {
+		return nil, fmt.Errorf("%w: you can't manage members with the %s role", ErrForbidden, user.Role)
+	}
+	return user, nil
+}
+
+// keepAdmin refuses a change that takes user's admin rights away when no
+// other active admin would be left to run the household.
+func (s *UserService) keepAdmin(ctx context.Context, tx store.Store, user *model.User) error {
+	if roleRank(user.Role) < roleRank(model.RoleAdmin) || !user.Active() {
+		return nil
+	}
+	users, err := tx.GetUsersByHousehold(ctx, user.HouseholdID)
+	if err != nil {
+		return fmt.Errorf("failed to get members: %w", err)
+	}
+	for _, other := range users {
+		if other.ID != user.ID && other.Active() && roleRank(other.Role) >= roleRank(model.RoleAdmin) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: the household must keep at least one active admin", ErrConflict)
+}
+
+// endSessions revokes a user's refresh tokens and invalidates their access
+// tokens. Callers invalidate the session cache once the transaction commits.
+func (s *UserService) endSessions(ctx context.Context, tx store.Store, userID int, now time.Time) error {
+	if err := tx.RevokeUserRefreshTokens(ctx, userID, now); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if err := tx.BumpSessionEpoch(ctx, userID); err != nil {
+		return fmt.Errorf("failed to end sessions: %w", err)
+	}
+	return nil
+}
+
+// leaveHousehold tidies up after user's membership of user.HouseholdID has
+// been marked removed. Someone who still belongs to another household keeps
+// their login and lands there next time; anyone else loses their credentials.
+func (s *UserService) leaveHousehold(ctx context.Context, tx store.Store, user *model.User, now time.Time) error {
+	memberships, err := tx.GetMemberships(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get memberships: %w", err)
+	}
+	if len(memberships) == 0 {
+		return s.deleteCredentials(ctx, tx, user, now)
+	}
+
+	tokens, err := tx.GetAPITokens(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get API tokens: %w", err)
+	}
+	for _, token := range tokens {
+		if token.HouseholdID != user.HouseholdID {
+			continue
+		}
+		if err := tx.DeleteAPIToken(ctx, token.ID); err != nil {
+			return fmt.Errorf("failed to delete API token: %w", err)
+		}
+	}
+
+	for _, membership := range memberships {
+		if membership.Current {
+			return nil
+		}
+	}
+	next := memberships[0]
+	for _, membership := range memberships {
+		if membership.DeactivatedAt == nil {
+			next = membership
+			break
+		}
+	}
+	if err := tx.SetCurrentHousehold(ctx, user.ID, next.HouseholdID); err != nil {
+		return fmt.Errorf("failed to switch household: %w", err)
+	}
+	return nil
+}
+
+// deleteCredentials clears everything a removed member could log in with
+// and frees their email address for a new account.
+func (s *UserService) deleteCredentials(ctx context.Context, tx store.Store, user *model.User, now time.Time) error {
+	user.Email = ""
+	user.EmailVerifiedAt = nil
+	user.PasswordHash = ""
+	user.UpdatedAt = now
+	if err := tx.UpdateUser(ctx, user); err != nil {
+		return fmt.Errorf("failed to update member: %w", err)
+	}
+	if err := tx.UpdateUserPIN(ctx, user.ID, nil); err != nil {
+		return fmt.Errorf("failed to remove pin: %w", err)
+	}
+	user.PINHash = nil
+
+	user.TOTPSecret = nil
+	user.TOTPEnabledAt = nil
+	if err := tx.UpdateUserTwoFactor(ctx, user); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	if err := tx.DeleteRecoveryCodes(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, purpose := range []model.TokenPurpose{
+		model.TokenPurposePasswordReset, model.TokenPurposeEmailVerification, model.TokenPurposeTwoFactorChallenge,
+	} {
+		if err := tx.InvalidateUserTokens(ctx, user.ID, purpose, now); err != nil {
+			return fmt.Errorf("failed to invalidate tokens: %w", err)
+		}
+	}
+
+	identities, err := tx.GetUserIdentities(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get identities: %w", err)
+	}
+	for _, identity := range identities {
+		if err := tx.DeleteUserIdentity(ctx, identity.ID); err != nil {
+			return fmt.Errorf("failed to unlink identity: %w", err)
+		}
+	}
+
+	tokens, err := tx.GetAPITokens(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get API tokens: %w", err)
+	}
+	for _, token := range tokens {
+		if err := tx.DeleteAPIToken(ctx, token.ID); err != nil {
+			return fmt.Errorf("failed to delete API token: %w", err)
+		}
+	}
+	return nil
+}
+
+// dropOpenAssignments deletes a member's assignments that are still to be
+// done, those the lifecycle lets them complete, and returns how many there
+// were. Finished ones are history and stay.
+func (s *UserService) dropOpenAssignments(ctx context.Context, tx store.Store, householdID, userID int) (int, error) {
+	assignments, err := tx.GetAssignmentsByUser(ctx, householdID, userID, model.AssignmentFilters{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get assignments: %w", err)
+	}
+	dropped := 0
+	for _, assignment := range assignments {
+		if CheckTransition(assignment.Status, model.StatusCompleted) != nil {
+			continue
+		}
+		if err := tx.DeleteAssignment(ctx, assignment.ID); err != nil {
+			return 0, fmt.Errorf("failed to delete assignment: %w", err)
+		}
+		dropped++
+	}
+	return dropped, nil
+}
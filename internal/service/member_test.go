@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/choreme/choreme/internal/model"
+)
+
+func TestRemoveMemberDropsOpenAssignments(t *testing.T) {
+	st := newTestStore(t)
+	s := NewUserService(st, NewAuditService(st), NewSessionCache(time.Minute))
+	household, members := newTestHousehold(t, st)
+	ctx := context.Background()
+	admin, worker := members[model.RoleAdmin], members[model.RoleWorker]
+
+	chore := addChore(t, st, household, admin)
+
+	dropped := map[model.AssignmentStatus]bool{
+		model.StatusPending:    true,
+		model.StatusInProgress: true,
+		model.StatusLate:       true,
+		model.StatusRejected:   true,
+		model.StatusCompleted:  false,
+		model.StatusApproved:   false,
+		model.StatusExpired:    false,
+	}
+	now := time.Now()
+	assignments := make(map[model.AssignmentStatus]*model.Assignment)
+	for status := range dropped {
+		assignment := &model.Assignment{
+			ChoreID:    chore.ID,
+			AssignedTo: worker.ID,
+			DueDate:    now,
+			Status:     status,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := st.CreateAssignment(ctx, assignment); err != nil {
+			t.Fatal(err)
+		}
+		assignments[status] = assignment
+	}
+
+	if err := s.RemoveMember(ctx, actorFor(admin), worker.ID); err != nil {
+		t.Fatalf("failed to remove member: %v", err)
+	}
+
+	for status, assignment := range assignments {
+		_, err := st.GetAssignmentByID(ctx, assignment.ID)
+		switch {
+		case dropped[status] && !errors.Is(err, sql.ErrNoRows):
+			t.Errorf("%s assignment: got %v, want it dropped", status, err)
+		case !dropped[status] && err != nil:
+			t.Errorf("%s assignment: got %v, want it kept", status, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+// GetMemberships lists the households the actor belongs to.
+func (s *AuthService) GetMemberships(ctx context.Context, actor Actor) ([]*model.Membership, error) {
+	memberships, err := s.store.GetMemberships(ctx, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memberships: %w", err)
+	}
+	return memberships, nil
+}
+
+// AcceptInvitation adds the actor's account to the household of an
+// invitation, with the invitation's role. The actor stays in their current
+// household until they switch.
+func (s *AuthService) AcceptInvitation(ctx context.Context, actor Actor, req *model.AcceptInvitationRequest) (*model.Membership, error) {
+	user, err := s.store.GetUserByID(ctx, actor.UserID)
+	if err != nil {
+		return nil, notFound(err, "user")
+	}
+
+	now := time.Now()
+	var membership *model.Membership
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		invitation, err := s.useInvitation(ctx, tx, req.InviteCode, user.Email, now)
+		if err != nil {
+			return err
+		}
+		existing, err := tx.GetMemberForUpdate(ctx, invitation.HouseholdID, user.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get membership: %w", err)
+		}
+		if err == nil && existing.RemovedAt == nil {
+			return fmt.Errorf("%w: you are already a member of this household", ErrConflict)
+		}
+		household, err := tx.GetHouseholdByID(ctx, invitation.HouseholdID)
+		if err != nil {
+			return fmt.Errorf("failed to get household: %w", err)
+		}
+
+		membership = &model.Membership{
+			HouseholdID:   household.ID,
+			UserID:        user.ID,
+			HouseholdName: household.Name,
+			Role:          invitation.Role,
+			CreatedAt:     now,
+		}
+		if err := tx.SaveMembership(ctx, membership); err != nil {
+			return fmt.Errorf("failed to join household: %w", err)
+		}
+		return s.audit.Record(ctx, tx, household.ID, user.ID, "user_joined_household", map[string]interface{}{
+			"user_id":       user.ID,
+			"email":         user.Email,
+			"role":          membership.Role,
+			"invitation_id": invitation.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return membership, nil
+}
+
+// SwitchHousehold makes another of the actor's households their current one
+// and returns them as a member of it. Households that require two-factor
+// authentication of managers can't be switched to without it.
+func (s *AuthService) SwitchHousehold(ctx context.Context, actor Actor, householdID int) (*model.User, error) {
+	user, err := s.store.GetMember(ctx, householdID, actor.UserID)
+	if err != nil {
+		return nil, notFound(err, "household")
+	}
+	if user.RemovedAt != nil {
+		return nil, fmt.Errorf("household %w", ErrNotFound)
+	}
+	if user.DeactivatedAt != nil {
+		return nil, fmt.Errorf("%w: your membership of this household is deactivated", ErrForbidden)
+	}
+	if err := checkHousehold(ctx, s.store, householdID); err != nil {
+		if errors.Is(err, ErrHouseholdSuspended) {
+			return nil, fmt.Errorf("%w: household is suspended", ErrForbidden)
+		}
+		return nil, err
+	}
+	required, err := s.twoFactorRequired(ctx, s.store, user)
+	if err != nil {
+		return nil, err
+	}
+	if required && !user.TwoFactorEnabled() {
+		return nil, fmt.Errorf("%w: the household requires two-factor authentication, set it up first", ErrForbidden)
+	}
+
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.SetCurrentHousehold(ctx, user.ID, householdID); err != nil {
+			return fmt.Errorf("failed to switch household: %w", err)
+		}
+		return s.audit.Record(ctx, tx, householdID, user.ID, "household_switched", map[string]interface{}{
+			"user_id":        user.ID,
+			"from_household": actor.HouseholdID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/choreme/choreme/internal/auth"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/oidc"
+	"github.com/choreme/choreme/internal/store"
+)
+
+var (
+	// ErrInvalidOIDCState is returned for OpenID Connect callbacks whose
+	// state is unknown, expired or already used.
+	ErrInvalidOIDCState = fmt.Errorf("%w: invalid or expired login request", ErrUnauthorized)
+	// ErrIdentityNotLinked is returned for an OpenID Connect login with an
+	// identity no user has linked.
+	ErrIdentityNotLinked = fmt.Errorf("%w: no account is linked to this identity", ErrUnauthorized)
+)
+
+// OIDCProviders lists the configured OpenID Connect providers.
+func (s *AuthService) OIDCProviders() []model.OIDCProvider {
+	providers := make([]model.OIDCProvider, 0, len(s.providers))
+	for _, provider := range s.providers {
+		providers = append(providers, model.OIDCProvider{
+			Name:        provider.Name(),
+			DisplayName: provider.DisplayName(),
+		})
+	}
+	return providers
+}
+
+// StartOIDCLogin starts a login with an OpenID Connect provider.
+func (s *AuthService) StartOIDCLogin(ctx context.Context, provider string) (*model.OIDCAuthorization, error) {
+	return s.startOIDC(ctx, provider, nil)
+}
+
+// StartIdentityLink starts linking the actor's account at an OpenID Connect
+// provider; LinkIdentity finishes it.
+func (s *AuthService) StartIdentityLink(ctx context.Context, actor Actor, provider string) (*model.OIDCAuthorization, error) {
+	return s.startOIDC(ctx, provider, &actor.UserID)
+}
+
+// CompleteOIDCLogin finishes an OpenID Connect login with the code and
+// state the provider sent the user back with. The identity must be linked
+// to a user, unless the provider may link by email and vouches for an
+// address a user has verified. Users with two-factor authentication still
+// get a challenge.
+func (s *AuthService) CompleteOIDCLogin(ctx context.Context, req *model.OIDCCallbackRequest) (*LoginResult, error) {
+	authReq, provider, claims, err := s.finishOIDC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if authReq.UserID != nil {
+		return nil, ErrInvalidOIDCState
+	}
+
+	now := time.Now()
+	var user *model.User
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		identity, err := tx.GetUserIdentity(ctx, provider.Name(), claims.Subject)
+		if errors.Is(err, sql.ErrNoRows) {
+			identity, user, err = s.linkByEmail(ctx, tx, provider, claims, now)
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return fmt.Errorf("failed to get identity: %w", err)
+		} else {
+			user, err = tx.GetUserByID(ctx, identity.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to get user: %w", err)
+			}
+		}
+		email := identityEmail(claims)
+		if email == nil {
+			email = identity.Email
+		}
+		if err := tx.TouchUserIdentity(ctx, identity.ID, email, now); err != nil {
+			return fmt.Errorf("failed to update identity: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return nil, &AccountLockedError{Until: *user.LockedUntil}
+	}
+	return s.finishLogin(ctx, user, map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": provider.Name(),
+	})
+}
+
+// LinkIdentity finishes linking an OpenID Connect identity to the actor,
+// given the code and state of a request from StartIdentityLink.
+func (s *AuthService) LinkIdentity(ctx context.Context, actor Actor, req *model.OIDCCallbackRequest) (*model.UserIdentity, error) {
+	authReq, provider, claims, err := s.finishOIDC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if authReq.UserID == nil || *authReq.UserID != actor.UserID {
+		return nil, ErrInvalidOIDCState
+	}
+
+	var identity *model.UserIdentity
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		existing, err := tx.GetUserIdentity(ctx, provider.Name(), claims.Subject)
+		if err == nil {
+			if existing.UserID != actor.UserID {
+				return fmt.Errorf("%w: identity is linked to another account", ErrConflict)
+			}
+			identity = existing
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get identity: %w", err)
+		}
+
+		identity, err = s.createIdentity(ctx, tx, actor.UserID, provider, claims, time.Now())
+		if err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "identity_linked", map[string]interface{}{
+			"user_id":  actor.UserID,
+			"provider": identity.Provider,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// GetIdentities lists the OpenID Connect identities linked to the actor.
+func (s *AuthService) GetIdentities(ctx context.Context, actor Actor) ([]*model.UserIdentity, error) {
+	identities, err := s.store.GetUserIdentities(ctx, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identities: %w", err)
+	}
+	return identities, nil
+}
+
+// UnlinkIdentity removes one of the actor's linked identities.
+func (s *AuthService) UnlinkIdentity(ctx context.Context, actor Actor, id int) error {
+	return s.store.WithTx(ctx, func(tx store.Store) error {
+		identity, err := tx.GetUserIdentityByID(ctx, id)
+		if err != nil {
+			return notFound(err, "identity")
+		}
+		if identity.UserID != actor.UserID {
+			return fmt.Errorf("identity %w", ErrNotFound)
+		}
+		if err := tx.DeleteUserIdentity(ctx, identity.ID); err != nil {
+			return fmt.Errorf("failed to delete identity: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "identity_unlinked", map[string]interface{}{
+			"user_id":  actor.UserID,
+			"provider": identity.Provider,
+		})
+	})
+}
+
+func (s *AuthService) provider(name string) (*oidc.Provider, error) {
+	for _, provider := range s.providers {
+		if provider.Name() == name {
+			return provider, nil
+		}
+	}
+	return nil, fmt.Errorf("provider %w", ErrNotFound)
+}
+
+// startOIDC records a pending authorization request and returns the
+// provider URL to send the user to. Only a hash of the state is stored.
+func (s *AuthService) startOIDC(ctx context.Context, name string, userID *int) (*model.OIDCAuthorization, error) {
+	provider, err := s.provider(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var values [3]string
+	for i := range values {
+		if values[i], err = auth.GenerateOpaqueToken(); err != nil {
+			return nil, fmt.Errorf("failed to generate OIDC state: %w", err)
+		}
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.store.CreateOIDCAuthRequest(ctx, &model.OIDCAuthRequest{
+		StateHash:    auth.HashToken(state),
+		Provider:     provider.Name(),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		UserID:       userID,
+		ExpiresAt:    now.Add(s.oidcTTL),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OIDC request: %w", err)
+	}
+
+	return &model.OIDCAuthorization{
+		AuthorizationURL: authURL,
+		State:            state,
+		ExpiresIn:        int(s.oidcTTL.Seconds()),
+	}, nil
+}
+
+// finishOIDC uses up the pending request of a callback's state and
+// authenticates its code with the provider. The request can't be used
+// again even if the provider refuses the code.
+func (s *AuthService) finishOIDC(ctx context.Context, req *model.OIDCCallbackRequest) (*model.OIDCAuthRequest, *oidc.Provider, *oidc.Claims, error) {
+	var authReq *model.OIDCAuthRequest
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		authReq, err = tx.GetOIDCAuthRequestForUpdate(ctx, auth.HashToken(req.State))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidOIDCState
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get OIDC request: %w", err)
+		}
+		if err := tx.DeleteOIDCAuthRequest(ctx, authReq.ID); err != nil {
+			return fmt.Errorf("failed to delete OIDC request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if time.Now().After(authReq.ExpiresAt) {
+		return nil, nil, nil, ErrInvalidOIDCState
+	}
+
+	provider, err := s.provider(authReq.Provider)
+	if err != nil {
+		return nil, nil, nil, ErrInvalidOIDCState
+	}
+	claims, err := provider.Authenticate(ctx, req.Code, authReq.CodeVerifier, authReq.Nonce)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %s login failed: %v", ErrUnauthorized, provider.Name(), err)
+	}
+	return authReq, provider, claims, nil
+}
+
+// linkByEmail links a first login with an identity to the user with the
+// same email address. Both the provider and the user must have verified
+// the address; otherwise someone who registered with another person's
+// address could take over their login.
+func (s *AuthService) linkByEmail(ctx context.Context, tx store.Store, provider *oidc.Provider, claims *oidc.Claims, now time.Time) (*model.UserIdentity, *model.User, error) {
+	if !provider.LinkByEmail() || !bool(claims.EmailVerified) || claims.Email == "" {
+		return nil, nil, ErrIdentityNotLinked
+	}
+	user, err := tx.GetUserByEmail(ctx, claims.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrIdentityNotLinked
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailVerifiedAt == nil || !strings.EqualFold(user.Email, claims.Email) {
+		return nil, nil, ErrIdentityNotLinked
+	}
+
+	identity, err := s.createIdentity(ctx, tx, user.ID, provider, claims, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "identity_linked", map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": identity.Provider,
+		"by_email": true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return identity, user, nil
+}
+
+func (s *AuthService) createIdentity(ctx context.Context, tx store.Store, userID int, provider *oidc.Provider, claims *oidc.Claims, now time.Time) (*model.UserIdentity, error) {
+	identity := &model.UserIdentity{
+		UserID:    userID,
+		Provider:  provider.Name(),
+		Subject:   claims.Subject,
+		Email:     identityEmail(claims),
+		CreatedAt: now,
+	}
+	if err := tx.CreateUserIdentity(ctx, identity); err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+	return identity, nil
+}
+
+func identityEmail(claims *oidc.Claims) *string {
+	if claims.Email == "" {
+		return nil
+	}
+	return &claims.Email
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/mailer"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/oidc/oidctest"
+	"github.com/choreme/choreme/internal/store"
+)
+
+const testEmail = "alice@example.com"
+
+// newOIDCTestService returns an auth service on a fresh SQLite database
+// with one provider, the fake issuer behind it, and a registered user.
+func newOIDCTestService(t *testing.T, linkByEmail, userVerified bool) (*AuthService, *oidctest.Issuer, *model.User) {
+	issuer := oidctest.NewIssuer(t, "choreme")
+
+	st := newTestStore(t)
+
+	cfg := &config.Config{}
+	cfg.Auth.SetupToken = "setup"
+	cfg.SMTP.OutboxDir = t.TempDir()
+	cfg.OIDC.StateTTL = 10 * time.Minute
+	cfg.OIDC.RedirectURL = "http://localhost:8080/auth/oidc/callback"
+	cfg.OIDC.Providers = []config.OIDCProviderConfig{{
+		Name:        "fake",
+		Issuer:      issuer.URL,
+		ClientID:    "choreme",
+		Scopes:      []string{"openid", "email"},
+		LinkByEmail: linkByEmail,
+	}}
+	s := NewAuthService(st, NewAuditService(st), NewSessionCache(time.Minute), mailer.New(cfg.SMTP), cfg)
+
+	ctx := context.Background()
+	user, err := s.Register(ctx, &model.RegisterRequest{
+		HouseholdName: "Test",
+		Name:          "Alice",
+		Email:         testEmail,
+		Password:      "securepassword",
+		SetupToken:    "setup",
+	})
+	if err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+	if userVerified {
+		verifyEmail(t, st, user)
+	}
+	return s, issuer, user
+}
+
+func verifyEmail(t *testing.T, st store.Store, user *model.User) {
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := st.UpdateUser(context.Background(), user); err != nil {
+		t.Fatalf("failed to verify email: %v", err)
+	}
+}
+
+// oidcLogin logs in at the fake issuer as subject with the given email
+// claims.
+func oidcLogin(t *testing.T, s *AuthService, issuer *oidctest.Issuer, subject string, emailVerified interface{}) (*LoginResult, error) {
+	ctx := context.Background()
+	authz, err := s.StartOIDCLogin(ctx, "fake")
+	if err != nil {
+		t.Fatalf("failed to start login: %v", err)
+	}
+	claims := issuer.Claims(subject, "")
+	claims["email"] = testEmail
+	claims["email_verified"] = emailVerified
+	code := issuer.Authorize(authz.AuthorizationURL, claims)
+	return s.CompleteOIDCLogin(ctx, &model.OIDCCallbackRequest{State: authz.State, Code: code})
+}
+
+func TestOIDCFirstLoginLinksByVerifiedEmail(t *testing.T) {
+	s, issuer, user := newOIDCTestService(t, true, true)
+
+	result, err := oidcLogin(t, s, issuer, "subject-1", true)
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	if result.User == nil || result.User.ID != user.ID {
+		t.Fatalf("logged in as %+v, want user %d", result.User, user.ID)
+	}
+
+	identities, err := s.GetIdentities(context.Background(), Actor{UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(identities) != 1 || identities[0].Subject != "subject-1" {
+		t.Fatalf("identities = %+v, want the linked subject-1", identities)
+	}
+
+	// The linked identity logs in again whatever the email claims say
+	if _, err := oidcLogin(t, s, issuer, "subject-1", false); err != nil {
+		t.Errorf("second login failed: %v", err)
+	}
+}
+
+func TestOIDCFirstLoginIsNotLinked(t *testing.T) {
+	tests := []struct {
+		name          string
+		linkByEmail   bool
+		userVerified  bool
+		emailVerified interface{}
+	}{
+		{"provider doesn't link by email", false, true, true},
+		{"email not verified by the provider", true, true, false},
+		{"email verified as the string false", true, true, "false"},
+		{"no email_verified claim", true, true, nil},
+		{"user hasn't verified their email", true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, issuer, user := newOIDCTestService(t, tt.linkByEmail, tt.userVerified)
+
+			if _, err := oidcLogin(t, s, issuer, "subject-1", tt.emailVerified); !errors.Is(err, ErrIdentityNotLinked) {
+				t.Fatalf("login = %v, want ErrIdentityNotLinked", err)
+			}
+			identities, err := s.GetIdentities(context.Background(), Actor{UserID: user.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(identities) != 0 {
+				t.Errorf("identities = %+v, want none", identities)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+// householdRoles are the roles of a household's permission matrix, in the
+// order they are listed.
+var householdRoles = []model.Role{model.RoleAdmin, model.RoleManager, model.RoleWorker, model.RoleObserver}
+
+// defaultPermissions are the permissions of each role unless its household
+// changed them. Admins and system admins always have every permission.
+var defaultPermissions = map[model.Role][]model.Permission{
+	model.RoleManager: {
+		model.PermHouseholdView, model.PermHouseholdSettings, model.PermHouseholdInvite,
+		model.PermMembersView, model.PermMembersManage, model.PermDevicesManage,
+		model.PermChoresCreate, model.PermChoresUpdate, model.PermChoresDelete,
+		model.PermAssignmentsWork, model.PermAssignmentsApprove,
+		model.PermRewardsCreate, model.PermRewardsUpdate, model.PermRewardsDelete, model.PermRewardsRedeem,
+		model.PermRedemptionsApprove, model.PermLedgerAdjust,
+		model.PermAuditRead, model.PermReportsRead,
+	},
+	model.RoleWorker:   {model.PermRewardsRedeem},
+	model.RoleObserver: {model.PermHouseholdView},
+}
+
+// fixedRole reports whether a role has every permission, which households
+// can't change so they can't lock their admins out.
+func fixedRole(role model.Role) bool {
+	return role == model.RoleSystemAdmin || role == model.RoleAdmin
+}
+
+// managingRole reports whether a role runs its household, which makes
+// two-factor authentication mandatory for it when the household requires
+// it.
+func managingRole(role model.Role) bool {
+	switch role {
+	case model.RoleSystemAdmin, model.RoleAdmin, model.RoleManager:
+		return true
+	}
+	return false
+}
+
+// Permissions returns what a role may do in a household: its defaults with
+// the household's changes applied.
+func (s *HouseholdService) Permissions(ctx context.Context, householdID int, role model.Role) (model.PermissionSet, error) {
+	if fixedRole(role) {
+		return allPermissions(), nil
+	}
+	if roles, ok := s.permissions.get(householdID); ok {
+		return roles[role], nil
+	}
+
+	overrides, err := s.store.GetRolePermissions(ctx, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	roles := resolvePermissions(overrides)
+	s.permissions.set(householdID, roles)
+	return roles[role], nil
+}
+
+// GetRoles returns the actor's household permission matrix.
+func (s *HouseholdService) GetRoles(ctx context.Context, actor Actor) (*model.PermissionMatrix, error) {
+	overrides, err := s.store.GetRolePermissions(ctx, actor.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	roles := resolvePermissions(overrides)
+	customized := make(map[model.Role]bool)
+	for _, override := range overrides {
+		customized[override.Role] = true
+	}
+
+	matrix := &model.PermissionMatrix{Permissions: model.Permissions}
+	for _, role := range householdRoles {
+		permissions := roles[role]
+		if fixedRole(role) {
+			permissions = allPermissions()
+		}
+		matrix.Roles = append(matrix.Roles, model.RolePermissions{
+			Role:        role,
+			Permissions: sortedPermissions(permissions),
+			Editable:    !fixedRole(role),
+			Customized:  customized[role],
+		})
+	}
+	return matrix, nil
+}
+
+// UpdateRole replaces what a role may do in the actor's household. The
+// actor can only grant permissions they have themselves.
+func (s *HouseholdService) UpdateRole(ctx context.Context, actor Actor, role model.Role, req *model.UpdateRolePermissionsRequest) (*model.RolePermissions, error) {
+	if err := s.checkEditableRole(actor, role); err != nil {
+		return nil, err
+	}
+	wanted := make(model.PermissionSet, len(req.Permissions))
+	for _, permission := range req.Permissions {
+		if !validPermission(permission) {
+			return nil, invalidf("unknown permission %q", permission)
+		}
+		wanted[permission] = true
+	}
+
+	defaults := defaultPermissionSet(role)
+	granted, revoked := []model.Permission{}, []model.Permission{}
+	for _, permission := range model.Permissions {
+		switch {
+		case wanted[permission] && !defaults[permission]:
+			granted = append(granted, permission)
+		case !wanted[permission] && defaults[permission]:
+			revoked = append(revoked, permission)
+		}
+	}
+
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		overrides, err := tx.GetRolePermissions(ctx, actor.HouseholdID)
+		if err != nil {
+			return fmt.Errorf("failed to get role permissions: %w", err)
+		}
+		current := resolvePermissions(overrides)[role]
+		for permission := range wanted {
+			if !current[permission] && !actor.Can(permission) {
+				return fmt.Errorf("%w: you can't grant %s", ErrForbidden, permission)
+			}
+		}
+
+		if err := tx.DeleteRolePermissions(ctx, actor.HouseholdID, role); err != nil {
+			return fmt.Errorf("failed to update role permissions: %w", err)
+		}
+		for _, permission := range granted {
+			if err := s.createRolePermission(ctx, tx, actor.HouseholdID, role, permission, true); err != nil {
+				return err
+			}
+		}
+		for _, permission := range revoked {
+			if err := s.createRolePermission(ctx, tx, actor.HouseholdID, role, permission, false); err != nil {
+				return err
+			}
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "role_permissions_updated", map[string]interface{}{
+			"role":    role,
+			"granted": granted,
+			"revoked": revoked,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.permissions.invalidate(actor.HouseholdID)
+
+	return &model.RolePermissions{
+		Role:        role,
+		Permissions: sortedPermissions(wanted),
+		Editable:    true,
+		Customized:  len(granted)+len(revoked) > 0,
+	}, nil
+}
+
+// ResetRole restores a role's default permissions in the actor's household.
+func (s *HouseholdService) ResetRole(ctx context.Context, actor Actor, role model.Role) (*model.RolePermissions, error) {
+	if err := s.checkEditableRole(actor, role); err != nil {
+		return nil, err
+	}
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.DeleteRolePermissions(ctx, actor.HouseholdID, role); err != nil {
+			return fmt.Errorf("failed to reset role permissions: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "role_permissions_reset", map[string]interface{}{
+			"role": role,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.permissions.invalidate(actor.HouseholdID)
+
+	return &model.RolePermissions{
+		Role:        role,
+		Permissions: sortedPermissions(defaultPermissionSet(role)),
+		Editable:    true,
+	}, nil
+}
+
+func (s *HouseholdService) checkEditableRole(actor Actor, role model.Role) error {
+	if !actor.Can(model.PermRolesManage) {
+		return ErrForbidden
+	}
+	switch role {
+	case model.RoleManager, model.RoleWorker, model.RoleObserver:
+		return nil
+	case model.RoleAdmin, model.RoleSystemAdmin:
+		return invalidf("the permissions of %s can't be changed", role)
+	}
+	return fmt.Errorf("role %w", ErrNotFound)
+}
+
+func (s *HouseholdService) createRolePermission(ctx context.Context, tx store.Store, householdID int, role model.Role, permission model.Permission, granted bool) error {
+	err := tx.CreateRolePermission(ctx, &model.RolePermission{
+		HouseholdID: householdID,
+		Role:        role,
+		Permission:  permission,
+		Granted:     granted,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update role permissions: %w", err)
+	}
+	return nil
+}
+
+// resolvePermissions applies a household's overrides to the defaults of
+// every editable role.
+func resolvePermissions(overrides []*model.RolePermission) map[model.Role]model.PermissionSet {
+	roles := make(map[model.Role]model.PermissionSet)
+	for _, role := range householdRoles {
+		if !fixedRole(role) {
+			roles[role] = defaultPermissionSet(role)
+		}
+	}
+	for _, override := range overrides {
+		permissions, ok := roles[override.Role]
+		if !ok || !validPermission(override.Permission) {
+			continue
+		}
+		if override.Granted {
+			permissions[override.Permission] = true
+		} else {
+			delete(permissions, override.Permission)
+		}
+	}
+	return roles
+}
+
+func defaultPermissionSet(role model.Role) model.PermissionSet {
+	permissions := make(model.PermissionSet)
+	for _, permission := range defaultPermissions[role] {
+		permissions[permission] = true
+	}
+	return permissions
+}
+
+func allPermissions() model.PermissionSet {
+	permissions := make(model.PermissionSet, len(model.Permissions))
+	for _, permission := range model.Permissions {
+		permissions[permission] = true
+	}
+	return permissions
+}
+
+func validPermission(permission model.Permission) bool {
+	for _, p := range model.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedPermissions lists a set in the order of model.Permissions.
+func sortedPermissions(set model.PermissionSet) []model.Permission {
+	permissions := []model.Permission{}
+	for _, permission := range model.Permissions {
+		if set[permission] {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
+
+// permissionCache remembers households' resolved permissions for a short
+// time, like SessionCache does for session epochs.
+type permissionCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[int]permissionEntry
+}
+
+type permissionEntry struct {
+	roles   map[model.Role]model.PermissionSet
+	expires time.Time
+}
+
+func newPermissionCache(ttl time.Duration) *permissionCache {
+	return &permissionCache{
+		ttl:     ttl,
+		entries: make(map[int]permissionEntry),
+	}
+}
+
+func (c *permissionCache) invalidate(householdID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, householdID)
+}
+
+func (c *permissionCache) get(householdID int) (map[model.Role]model.PermissionSet, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[householdID]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.roles, true
+}
+
+func (c *permissionCache) set(householdID int, roles map[model.Role]model.PermissionSet) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxSessionCacheEntries {
+		for id, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[householdID] = permissionEntry{roles: roles, expires: now.Add(c.ttl)}
+}
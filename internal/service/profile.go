@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/choreme/choreme/internal/auth"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+const (
+	minPINLength = 4
+	maxPINLength = 8
+)
+
+// ErrInvalidPIN is returned for a PIN login with a wrong PIN, or for a
+// member who can't log in with one on the device.
+var ErrInvalidPIN = fmt.Errorf("%w: invalid PIN", ErrUnauthorized)
+
+// validatePIN checks a PIN is 4 to 8 digits.
+func validatePIN(pin string) error {
+	if len(pin) < minPINLength || len(pin) > maxPINLength {
+		return invalidf("pin must be %d to %d digits", minPINLength, maxPINLength)
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return invalidf("pin must be %d to %d digits", minPINLength, maxPINLength)
+		}
+	}
+	return nil
+}
+
+// CreateProfile adds an email-less worker profile, such as for a young
+// child, to the actor's household. It has no password and logs in with its
+// PIN on a household device.
+func (s *UserService) CreateProfile(ctx context.Context, actor Actor, req *model.CreateProfileRequest) (*model.User, error) {
+	if !actor.Can(model.PermMembersManage) {
+		return nil, ErrForbidden
+	}
+	if err := validatePIN(req.PIN); err != nil {
+		return nil, err
+	}
+	pinHash, err := auth.HashPassword(req.PIN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash pin: %w", err)
+	}
+
+	now := time.Now()
+	user := &model.User{
+		HouseholdID: actor.HouseholdID,
+		Name:        req.Name,
+		Role:        model.RoleWorker,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateUser(ctx, user); err != nil {
+			return fmt.Errorf("failed to create profile: %w", err)
+		}
+		if err := tx.UpdateUserPIN(ctx, user.ID, &pinHash); err != nil {
+			return fmt.Errorf("failed to set pin: %w", err)
+		}
+		user.PINHash = &pinHash
+
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "profile_created", map[string]interface{}{
+			"user_id": user.ID,
+			"name":    user.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetPIN sets the PIN a household member logs in with on household devices.
+// A profile's lockout from wrong PINs is lifted; members with a password
+// keep theirs, which it protects too.
+func (s *UserService) SetPIN(ctx context.Context, actor Actor, userID int, pin string) error {
+	if err := validatePIN(pin); err != nil {
+		return err
+	}
+	pinHash, err := auth.HashPassword(pin)
+	if err != nil {
+		return fmt.Errorf("failed to hash pin: %w", err)
+	}
+	return s.updatePIN(ctx, actor, userID, &pinHash, "pin_set")
+}
+
+// RemovePIN stops a member from logging in with a PIN. Profiles can't log
+// in at all without one.
+func (s *UserService) RemovePIN(ctx context.Context, actor Actor, userID int) error {
+	return s.updatePIN(ctx, actor, userID, nil, "pin_removed")
+}
+
+func (s *UserService) updatePIN(ctx context.Context, actor Actor, userID int, pinHash *string, action string) error {
+	return s.store.WithTx(ctx, func(tx store.Store) error {
+		user, err := s.managedMember(ctx, tx, actor, userID, true)
+		if err != nil {
+			return err
+		}
+		if err := tx.UpdateUserPIN(ctx, user.ID, pinHash); err != nil {
+			return fmt.Errorf("failed to update pin: %w", err)
+		}
+		if user.IsProfile() {
+			if err := tx.UpdateLoginFailures(ctx, user.ID, 0, nil); err != nil {
+				return fmt.Errorf("failed to reset failed logins: %w", err)
+			}
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, action, map[string]interface{}{
+			"user_id": user.ID,
+		})
+	})
+}
+
+// PINLogin logs a member in with their PIN on a household device of their
+// household. Wrong PINs count towards the same lockout as passwords, which
+// is what keeps short PINs from being guessed. Members with two-factor
+// authentication, or who need it by their household's policy, can't log in
+// with a PIN, since it would skip the second factor.
+func (s *AuthService) PINLogin(ctx context.Context, device *model.HouseholdDevice, userID int, pin string) (*model.User, error) {
+	user, err := s.store.GetMember(ctx, device.HouseholdID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidPIN
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	// Members who left the household or were deactivated are refused before
+	// their PIN is checked, so that guesses don't lock their account
+	if user.RemovedAt != nil || user.PINHash == nil || user.TwoFactorEnabled() {
+		return nil, ErrInvalidPIN
+	}
+	if !user.Active() {
+		return nil, ErrAccountDeactivated
+	}
+	required, err := s.twoFactorRequired(ctx, s.store, user)
+	if err != nil {
+		return nil, err
+	}
+	if required {
+		return nil, ErrInvalidPIN
+	}
+
+	now := time.Now()
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return nil, &AccountLockedError{Until: *user.LockedUntil}
+	}
+	if err := auth.VerifyPassword(*user.PINHash, pin); err != nil {
+		if err := s.recordLoginFailure(ctx, user.ID, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidPIN
+	}
+
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if user.FailedLogins > 0 || user.LockedUntil != nil {
+			if err := tx.UpdateLoginFailures(ctx, user.ID, 0, nil); err != nil {
+				return fmt.Errorf("failed to reset failed logins: %w", err)
+			}
+		}
+		return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "pin_login", map[string]interface{}{
+			"user_id":     user.ID,
+			"device_id":   device.ID,
+			"device_name": device.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
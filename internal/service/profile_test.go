@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/choreme/choreme/internal/auth"
+	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/mailer"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+// addMember creates a user with role in household. Users named without an
+// email address are profiles.
+func addMember(t *testing.T, st store.Store, household *model.Household, name string, role model.Role) *model.User {
+	t.Helper()
+	now := time.Now()
+	user := &model.User{
+		HouseholdID: household.ID,
+		Name:        name,
+		Role:        role,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if !strings.HasPrefix(name, "profile") {
+		user.Email = fmt.Sprintf("%s@example.com", name)
+	}
+	if err := st.CreateUser(context.Background(), user); err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+	return user
+}
+
+// newTestHousehold creates a household with a member of every role, keyed
+// by role.
+func newTestHousehold(t *testing.T, st store.Store) (*model.Household, map[model.Role]*model.User) {
+	t.Helper()
+	household := model.NewHousehold("Test", time.Now())
+	if err := st.CreateHousehold(context.Background(), household); err != nil {
+		t.Fatalf("failed to create household: %v", err)
+	}
+	members := make(map[model.Role]*model.User)
+	for _, role := range []model.Role{
+		model.RoleSystemAdmin, model.RoleAdmin, model.RoleManager, model.RoleWorker, model.RoleObserver,
+	} {
+		members[role] = addMember(t, st, household, string(role), role)
+	}
+	return household, members
+}
+
+// actorFor returns user acting with their role's default permissions.
+func actorFor(user *model.User) Actor {
+	permissions := defaultPermissionSet(user.Role)
+	if fixedRole(user.Role) {
+		permissions = allPermissions()
+	}
+	return Actor{
+		UserID:      user.ID,
+		HouseholdID: user.HouseholdID,
+		Role:        user.Role,
+		Permissions: permissions,
+	}
+}
+
+func TestSetPINOnlyForManagedRoles(t *testing.T) {
+	st := newTestStore(t)
+	s := NewUserService(st, NewAuditService(st), NewSessionCache(time.Minute))
+	_, members := newTestHousehold(t, st)
+	ctx := context.Background()
+	manager := actorFor(members[model.RoleManager])
+
+	for _, role := range []model.Role{model.RoleAdmin, model.RoleSystemAdmin} {
+		if err := s.SetPIN(ctx, manager, members[role].ID, "1234"); !errors.Is(err, ErrForbidden) {
+			t.Errorf("manager setting the PIN of the %s = %v, want ErrForbidden", role, err)
+		}
+		if err := s.RemovePIN(ctx, manager, members[role].ID); !errors.Is(err, ErrForbidden) {
+			t.Errorf("manager removing the PIN of the %s = %v, want ErrForbidden", role, err)
+		}
+		user, err := st.GetUserByID(ctx, members[role].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.PINHash != nil {
+			t.Errorf("the %s got a PIN", role)
+		}
+	}
+
+	for _, user := range []*model.User{members[model.RoleWorker], members[model.RoleManager]} {
+		if err := s.SetPIN(ctx, manager, user.ID, "1234"); err != nil {
+			t.Errorf("manager setting the PIN of the %s failed: %v", user.Role, err)
+		}
+	}
+}
+
+func TestSetPINKeepsPasswordLockout(t *testing.T) {
+	st := newTestStore(t)
+	s := NewUserService(st, NewAuditService(st), NewSessionCache(time.Minute))
+	household, members := newTestHousehold(t, st)
+	profile := addMember(t, st, household, "profile", model.RoleWorker)
+	ctx := context.Background()
+	admin := actorFor(members[model.RoleAdmin])
+
+	lockedUntil := time.Now().Add(time.Hour)
+	for _, user := range []*model.User{members[model.RoleWorker], profile} {
+		if err := st.UpdateLoginFailures(ctx, user.ID, 5, &lockedUntil); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetPIN(ctx, admin, user.ID, "1234"); err != nil {
+			t.Fatalf("failed to set PIN: %v", err)
+		}
+	}
+
+	worker, err := st.GetUserByID(ctx, members[model.RoleWorker].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worker.LockedUntil == nil || worker.FailedLogins != 5 {
+		t.Errorf("worker with a password has %d failed logins, locked until %v; want the lockout kept", worker.FailedLogins, worker.LockedUntil)
+	}
+	got, err := st.GetUserByID(ctx, profile.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LockedUntil != nil || got.FailedLogins != 0 {
+		t.Errorf("profile has %d failed logins, locked until %v; want the lockout lifted", got.FailedLogins, got.LockedUntil)
+	}
+}
+
+// setPIN gives user the PIN 1234.
+func setPIN(t *testing.T, st store.Store, user *model.User) {
+	t.Helper()
+	pinHash, err := auth.HashPassword("1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.UpdateUserPIN(context.Background(), user.ID, &pinHash); err != nil {
+		t.Fatalf("failed to set PIN: %v", err)
+	}
+}
+
+func newPINTestService(t *testing.T, st store.Store) *AuthService {
+	cfg := &config.Config{}
+	cfg.SMTP.OutboxDir = t.TempDir()
+	return NewAuthService(st, NewAuditService(st), NewSessionCache(time.Minute), mailer.New(cfg.SMTP), cfg)
+}
+
+func TestPINLoginRefusesTwoFactorUsers(t *testing.T) {
+	st := newTestStore(t)
+	s := newPINTestService(t, st)
+	household, members := newTestHousehold(t, st)
+	device := &model.HouseholdDevice{ID: 1, HouseholdID: household.ID, Name: "Kitchen"}
+	ctx := context.Background()
+	worker, manager := members[model.RoleWorker], members[model.RoleManager]
+	setPIN(t, st, worker)
+	setPIN(t, st, manager)
+
+	for _, user := range []*model.User{worker, manager} {
+		if _, err := s.PINLogin(ctx, device, user.ID, "1234"); err != nil {
+			t.Fatalf("PIN login of the %s failed: %v", user.Role, err)
+		}
+	}
+
+	// A worker using two-factor authentication
+	secret, now := "JBSWY3DPEHPK3PXP", time.Now()
+	worker.TOTPSecret, worker.TOTPEnabledAt = &secret, &now
+	if err := st.UpdateUserTwoFactor(ctx, worker); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PINLogin(ctx, device, worker.ID, "1234"); !errors.Is(err, ErrInvalidPIN) {
+		t.Errorf("PIN login with two-factor authentication = %v, want ErrInvalidPIN", err)
+	}
+
+	// A manager of a household requiring two-factor authentication
+	household.RequireTwoFactor = true
+	if err := st.UpdateHousehold(ctx, household); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PINLogin(ctx, device, manager.ID, "1234"); !errors.Is(err, ErrInvalidPIN) {
+		t.Errorf("PIN login of a manager needing two-factor authentication = %v, want ErrInvalidPIN", err)
+	}
+}
+
+func TestPINLoginRefusesInactiveMembers(t *testing.T) {
+	st := newTestStore(t)
+	s := newPINTestService(t, st)
+	users := NewUserService(st, NewAuditService(st), NewSessionCache(time.Minute))
+	household, members := newTestHousehold(t, st)
+	device := &model.HouseholdDevice{ID: 1, HouseholdID: household.ID, Name: "Kitchen"}
+	ctx := context.Background()
+	admin := actorFor(members[model.RoleAdmin])
+
+	deactivated := members[model.RoleWorker]
+	setPIN(t, st, deactivated)
+	if _, err := users.DeactivateMember(ctx, admin, deactivated.ID); err != nil {
+		t.Fatalf("failed to deactivate member: %v", err)
+	}
+
+	// The removed member belongs to another household, so keeps their PIN
+	removed := addMember(t, st, household, "profile", model.RoleWorker)
+	setPIN(t, st, removed)
+	other := model.NewHousehold("Other", time.Now())
+	if err := st.CreateHousehold(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	err := st.SaveMembership(ctx, &model.Membership{
+		HouseholdID: other.ID,
+		UserID:      removed.ID,
+		Role:        model.RoleWorker,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := users.RemoveMember(ctx, admin, removed.ID); err != nil {
+		t.Fatalf("failed to remove member: %v", err)
+	}
+
+	tests := []struct {
+		user *model.User
+		want error
+	}{
+		{deactivated, ErrAccountDeactivated},
+		{removed, ErrInvalidPIN},
+	}
+	for _, tt := range tests {
+		for _, pin := range []string{"1234", "0000"} {
+			if _, err := s.PINLogin(ctx, device, tt.user.ID, pin); !errors.Is(err, tt.want) {
+				t.Errorf("PIN login of %s with %s = %v, want %v", tt.user.Name, pin, err, tt.want)
+			}
+		}
+		// The wrong PIN wasn't checked, so it didn't count
+		user, err := st.GetUserByID(ctx, tt.user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.FailedLogins != 0 {
+			t.Errorf("%s has %d failed logins, want 0", tt.user.Name, user.FailedLogins)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+	"github.com/shopspring/decimal"
+)
+
+// defaultReportDays is how far back reports reach without a date_from.
+const defaultReportDays = 30
+
+// maxReportBuckets caps how many periods one report spans.
+const maxReportBuckets = 400
+
+// ReportService summarizes a household's chores and earnings by the days,
+// weeks or months of its own calendar.
+type ReportService struct {
+	store store.Store
+}
+
+func NewReportService(store store.Store) *ReportService {
+	return &ReportService{
+		store: store,
+	}
+}
+
+// GetChoreReport counts the assignments due in each period as completed,
+// missed or still open.
+func (s *ReportService) GetChoreReport(ctx context.Context, actor Actor, filters model.ReportFilters) (*model.ChoreReport, error) {
+	if !actor.Can(model.PermReportsRead) {
+		return nil, ErrForbidden
+	}
+	household, err := getHousehold(ctx, s.store, actor.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	groupBy, bounds, err := reportPeriods(household, filters, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := bounds[0].UTC(), bounds[len(bounds)-1].UTC().Add(-time.Nanosecond)
+	assignmentFilters := model.AssignmentFilters{DueAfter: &from, DueBefore: &to}
+	var assignments []*model.Assignment
+	if filters.UserID != nil {
+		assignments, err = s.store.GetAssignmentsByUser(ctx, actor.HouseholdID, *filters.UserID, assignmentFilters)
+	} else {
+		assignments, err = s.store.GetAssignmentsByHousehold(ctx, actor.HouseholdID, assignmentFilters)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments: %w", err)
+	}
+
+	report := &model.ChoreReport{
+		GroupBy:  groupBy,
+		Timezone: household.Location().String(),
+		Buckets:  make([]*model.ChoreReportBucket, len(bounds)-1),
+	}
+	for i := range report.Buckets {
+		report.Buckets[i] = &model.ChoreReportBucket{Start: bounds[i], End: bounds[i+1]}
+	}
+	for _, assignment := range assignments {
+		i := bucketOf(bounds, assignment.DueDate)
+		if i < 0 {
+			continue
+		}
+		switch assignment.Status {
+		case model.StatusCompleted, model.StatusApproved:
+			report.Buckets[i].Completed++
+		case model.StatusExpired:
+			report.Buckets[i].Missed++
+		default:
+			report.Buckets[i].Open++
+		}
+	}
+	return report, nil
+}
+
+// GetEarningsReport sums each member's ledger entries in each period. Only
+// members with entries in a period get a bucket for it.
+func (s *ReportService) GetEarningsReport(ctx context.Context, actor Actor, filters model.ReportFilters) (*model.EarningsReport, error) {
+	if !actor.Can(model.PermReportsRead) {
+		return nil, ErrForbidden
+	}
+	household, err := getHousehold(ctx, s.store, actor.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	groupBy, bounds, err := reportPeriods(household, filters, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := bounds[0].UTC(), bounds[len(bounds)-1].UTC().Add(-time.Nanosecond)
+	ledgerFilters := model.LedgerFilters{DateFrom: &from, DateTo: &to}
+	var entries []*model.LedgerEntry
+	if filters.UserID != nil {
+		entries, err = s.store.GetLedgerEntriesByUser(ctx, actor.HouseholdID, *filters.UserID, ledgerFilters)
+	} else {
+		entries, err = s.store.GetLedgerEntriesByHousehold(ctx, actor.HouseholdID, ledgerFilters)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger entries: %w", err)
+	}
+
+	type key struct{ bucket, userID int }
+	buckets := make(map[key]*model.EarningsReportBucket)
+	for _, entry := range entries {
+		i := bucketOf(bounds, entry.CreatedAt)
+		if i < 0 {
+			continue
+		}
+		bucket, ok := buckets[key{i, entry.UserID}]
+		if !ok {
+			bucket = &model.EarningsReportBucket{
+				Start:    bounds[i],
+				End:      bounds[i+1],
+				UserID:   entry.UserID,
+				Earned:   decimal.Zero,
+				Spent:    decimal.Zero,
+				Adjusted: decimal.Zero,
+			}
+			buckets[key{i, entry.UserID}] = bucket
+		}
+		switch entry.Type {
+		case model.LedgerTypeEarn:
+			bucket.Earned = bucket.Earned.Add(entry.Amount)
+		case model.LedgerTypeSpend:
+			bucket.Spent = bucket.Spent.Sub(entry.Amount)
+		case model.LedgerTypeAdjust:
+			bucket.Adjusted = bucket.Adjusted.Add(entry.Amount)
+		}
+	}
+
+	report := &model.EarningsReport{
+		GroupBy:     groupBy,
+		Timezone:    household.Location().String(),
+		Currency:    household.Currency,
+		PointsLabel: household.PointsLabel,
+		Buckets:     make([]*model.EarningsReportBucket, 0, len(buckets)),
+	}
+	for _, bucket := range buckets {
+		report.Buckets = append(report.Buckets, bucket)
+	}
+	sort.Slice(report.Buckets, func(i, j int) bool {
+		a, b := report.Buckets[i], report.Buckets[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		return a.UserID < b.UserID
+	})
+	return report, nil
+}
+
+// reportPeriods returns the period a report is grouped by and the
+// boundaries of its buckets in the household's timezone: the start of the
+// period containing date_from, the start of every period after it, and the
+// end of the period containing date_to. Without a range, reports cover the
+// last defaultReportDays days up to now, grouped by week.
+func reportPeriods(household *model.Household, filters model.ReportFilters, now time.Time) (model.Period, []time.Time, error) {
+	groupBy := filters.GroupBy
+	if groupBy == "" {
+		groupBy = model.PeriodWeek
+	}
+	if !groupBy.Valid() {
+		return "", nil, invalidf("group_by must be day, week or month")
+	}
+
+	to := now
+	if filters.DateTo != nil {
+		to = *filters.DateTo
+	}
+	from := to.AddDate(0, 0, -defaultReportDays)
+	if filters.DateFrom != nil {
+		from = *filters.DateFrom
+	}
+	if to.Before(from) {
+		return "", nil, invalidf("date_from must not be after date_to")
+	}
+
+	start, end := household.Bounds(groupBy, from)
+	bounds := []time.Time{start, end}
+	for !end.After(to) {
+		if len(bounds) > maxReportBuckets {
+			return "", nil, invalidf("reports span at most %d periods; narrow the range or group by a longer period", maxReportBuckets)
+		}
+		_, end = household.Bounds(groupBy, end)
+		bounds = append(bounds, end)
+	}
+	return groupBy, bounds, nil
+}
+
+// bucketOf returns the index of the bucket t falls in, or -1 when it is
+// outside every bucket.
+func bucketOf(bounds []time.Time, t time.Time) int {
+	if t.Before(bounds[0]) {
+		return -1
+	}
+	i := sort.Search(len(bounds)-1, func(i int) bool {
+		return bounds[i+1].After(t)
+	})
+	if i == len(bounds)-1 {
+		return -1
+	}
+	return i
+}
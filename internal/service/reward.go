@@ -2,44 +2,352 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/choreme/choreme/internal/model"
 	"github.com/choreme/choreme/internal/store"
 )
 
+// ErrInsufficientBalance is returned when a redemption would take the user's
+// available balance below zero in a household that doesn't allow it.
+var ErrInsufficientBalance = fmt.Errorf("%w: insufficient balance", ErrConflict)
+
 type RewardService struct {
-	store store.Store
-	audit *AuditService
+	store  store.Store
+	audit  *AuditService
+	ledger *LedgerService
 }
 
-func NewRewardService(store store.Store, audit *AuditService) *RewardService {
+func NewRewardService(store store.Store, audit *AuditService, ledger *LedgerService) *RewardService {
 	return &RewardService{
-		store: store,
-		audit: audit,
+		store:  store,
+		audit:  audit,
+		ledger: ledger,
+	}
+}
+
+func (s *RewardService) CreateReward(ctx context.Context, actor Actor, req *model.CreateRewardRequest) (*model.Reward, error) {
+	if !actor.Can(model.PermRewardsCreate) {
+		return nil, ErrForbidden
+	}
+
+	reward := &model.Reward{
+		HouseholdID: actor.HouseholdID,
+		Title:       strings.TrimSpace(req.Title),
+		Description: optionalString(req.Description),
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+	}
+	if reward.Title == "" {
+		return nil, invalidf("title is required")
+	}
+	household, err := getHousehold(ctx, s.store, actor.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	if reward.Cost, err = parseAmount("cost", req.Cost, household.DecimalPlaces); err != nil {
+		return nil, err
+	}
+
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.CreateReward(ctx, reward); err != nil {
+			return fmt.Errorf("failed to create reward: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "reward_created", map[string]interface{}{
+			"reward_id": reward.ID,
+			"title":     reward.Title,
+			"cost":      household.FormatAmount(reward.Cost),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reward, nil
+}
+
+// GetRewardByID returns a reward from the actor's household. Inactive rewards
+// are only visible to members who can view the whole household.
+func (s *RewardService) GetRewardByID(ctx context.Context, actor Actor, id int) (*model.Reward, error) {
+	reward, err := s.getHouseholdReward(ctx, s.store, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if !reward.IsActive && !actor.Can(model.PermHouseholdView) {
+		return nil, fmt.Errorf("reward %w", ErrNotFound)
+	}
+	return reward, nil
+}
+
+// GetRewardsByHousehold lists the household's rewards. Workers only see
+// active rewards; others see inactive ones too when includeInactive is set.
+func (s *RewardService) GetRewardsByHousehold(ctx context.Context, actor Actor, includeInactive bool) ([]*model.Reward, error) {
+	rewards, err := s.store.GetRewardsByHousehold(ctx, actor.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rewards: %w", err)
+	}
+
+	includeInactive = includeInactive && actor.Can(model.PermHouseholdView)
+	result := []*model.Reward{}
+	for _, reward := range rewards {
+		if reward.IsActive || includeInactive {
+			result = append(result, reward)
+		}
+	}
+	return result, nil
+}
+
+// UpdateReward changes a reward. Cost changes don't affect pending
+// redemptions, which hold the cost they were requested at.
+func (s *RewardService) UpdateReward(ctx context.Context, actor Actor, id int, req *model.UpdateRewardRequest) (*model.Reward, error) {
+	if !actor.Can(model.PermRewardsUpdate) {
+		return nil, ErrForbidden
+	}
+
+	reward, err := s.getHouseholdReward(ctx, s.store, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Title != nil {
+		reward.Title = strings.TrimSpace(*req.Title)
+		if reward.Title == "" {
+			return nil, invalidf("title is required")
+		}
+	}
+	if req.Description != nil {
+		reward.Description = optionalString(req.Description)
+	}
+	household, err := getHousehold(ctx, s.store, actor.HouseholdID)
+	if err != nil {
+		return nil, err
 	}
+	if req.Cost != nil {
+		if reward.Cost, err = parseAmount("cost", *req.Cost, household.DecimalPlaces); err != nil {
+			return nil, err
+		}
+	}
+	if req.IsActive != nil {
+		reward.IsActive = *req.IsActive
+	}
+
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.UpdateReward(ctx, reward); err != nil {
+			return fmt.Errorf("failed to update reward: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "reward_updated", map[string]interface{}{
+			"reward_id": reward.ID,
+			"title":     reward.Title,
+			"cost":      household.FormatAmount(reward.Cost),
+			"is_active": reward.IsActive,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reward, nil
+}
+
+// DeleteReward removes a reward and its redemption history. Rewards with
+// pending redemptions can't be deleted until those are approved or rejected;
+// deactivate them instead to stop new redemptions.
+func (s *RewardService) DeleteReward(ctx context.Context, actor Actor, id int) error {
+	if !actor.Can(model.PermRewardsDelete) {
+		return ErrForbidden
+	}
+
+	return s.store.WithTx(ctx, func(tx store.Store) error {
+		reward, err := s.getHouseholdReward(ctx, tx, actor, id)
+		if err != nil {
+			return err
+		}
+
+		status := model.RedemptionStatusPending
+		pending, err := tx.GetRedemptionsByHousehold(ctx, actor.HouseholdID, model.RedemptionFilters{
+			Status:   &status,
+			RewardID: &reward.ID,
+			Limit:    1,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get redemptions: %w", err)
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%w: reward has pending redemptions", ErrConflict)
+		}
+
+		if err := tx.DeleteReward(ctx, reward.ID); err != nil {
+			return fmt.Errorf("failed to delete reward: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "reward_deleted", map[string]interface{}{
+			"reward_id": reward.ID,
+			"title":     reward.Title,
+		})
+	})
 }
 
-// Placeholder implementations - to be completed
-func (s *RewardService) CreateReward(ctx context.Context, reward *model.Reward) error {
-	return nil // TODO: Implement
+// RedeemReward requests a reward for the actor. The reward's cost is held
+// against the actor's balance until a manager approves or rejects the
+// redemption, so the same balance can't be spent twice in the meantime.
+// Unless the household allows negative balances, the cost must fit in the
+// balance that isn't already held.
+func (s *RewardService) RedeemReward(ctx context.Context, actor Actor, rewardID int) (*model.Redemption, error) {
+	if !actor.Can(model.PermRewardsRedeem) {
+		return nil, ErrForbidden
+	}
+
+	var redemption *model.Redemption
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		reward, err := s.getHouseholdReward(ctx, tx, actor, rewardID)
+		if err != nil {
+			return err
+		}
+		if !reward.IsActive {
+			return fmt.Errorf("%w: reward is not available", ErrConflict)
+		}
+		household, err := tx.GetHouseholdByID(ctx, actor.HouseholdID)
+		if err != nil {
+			return fmt.Errorf("failed to get household: %w", err)
+		}
+
+		// Lock the member so concurrent redemptions see each other's holds
+		if _, err := tx.GetMemberForUpdate(ctx, actor.HouseholdID, actor.UserID); err != nil {
+			return notFound(err, "user")
+		}
+		summary, err := s.ledger.balanceSummary(ctx, tx, actor.HouseholdID, actor.UserID)
+		if err != nil {
+			return err
+		}
+		if !household.AllowNegativeBalance && summary.Available.LessThan(reward.Cost) {
+			return ErrInsufficientBalance
+		}
+
+		redemption = &model.Redemption{
+			RewardID:   reward.ID,
+			UserID:     actor.UserID,
+			Status:     model.RedemptionStatusPending,
+			Cost:       reward.Cost,
+			RedeemedAt: time.Now(),
+			Reward:     reward,
+		}
+		if err := tx.CreateRedemption(ctx, redemption); err != nil {
+			return fmt.Errorf("failed to create redemption: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "reward_redeemed", map[string]interface{}{
+			"redemption_id": redemption.ID,
+			"reward_id":     reward.ID,
+			"title":         reward.Title,
+			"cost":          household.FormatAmount(redemption.Cost),
+			"available":     household.FormatAmount(summary.Available),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redemption, nil
 }
 
-func (s *RewardService) GetRewardByID(ctx context.Context, id int) (*model.Reward, error) {
-	return nil, nil // TODO: Implement
+// GetRedemptions lists redemptions in the actor's household, or only the
+// actor's own for workers.
+func (s *RewardService) GetRedemptions(ctx context.Context, actor Actor, filters model.RedemptionFilters) ([]*model.Redemption, error) {
+	filters.Limit, filters.Offset = normalizePage(filters.Limit, filters.Offset)
+
+	var redemptions []*model.Redemption
+	var err error
+	if actor.Can(model.PermHouseholdView) {
+		redemptions, err = s.store.GetRedemptionsByHousehold(ctx, actor.HouseholdID, filters)
+	} else {
+		redemptions, err = s.store.GetRedemptionsByUser(ctx, actor.HouseholdID, actor.UserID, filters)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redemptions: %w", err)
+	}
+	if redemptions == nil {
+		redemptions = []*model.Redemption{}
+	}
+	return redemptions, nil
 }
 
-func (s *RewardService) GetRewardsByHousehold(ctx context.Context, householdID int) ([]*model.Reward, error) {
-	return nil, nil // TODO: Implement
+// ApproveRedemption turns a pending redemption's hold into a spend entry on
+// the ledger.
+func (s *RewardService) ApproveRedemption(ctx context.Context, actor Actor, id int) (*model.Redemption, error) {
+	return s.review(ctx, actor, id, model.RedemptionStatusApproved)
 }
 
-func (s *RewardService) UpdateReward(ctx context.Context, reward *model.Reward) error {
-	return nil // TODO: Implement
+// RejectRedemption releases a pending redemption's hold.
+func (s *RewardService) RejectRedemption(ctx context.Context, actor Actor, id int) (*model.Redemption, error) {
+	return s.review(ctx, actor, id, model.RedemptionStatusRejected)
 }
 
-func (s *RewardService) DeleteReward(ctx context.Context, id int) error {
-	return nil // TODO: Implement
+func (s *RewardService) review(ctx context.Context, actor Actor, id int, to model.RedemptionStatus) (*model.Redemption, error) {
+	if !actor.Can(model.PermRedemptionsApprove) {
+		return nil, ErrForbidden
+	}
+
+	var redemption *model.Redemption
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		redemption, err = tx.GetRedemptionForUpdate(ctx, id)
+		if err != nil {
+			return notFound(err, "redemption")
+		}
+		if redemption.Reward.HouseholdID != actor.HouseholdID {
+			return fmt.Errorf("redemption %w", ErrNotFound)
+		}
+		if redemption.Status != model.RedemptionStatusPending {
+			return fmt.Errorf("%w: redemption is already %s", ErrConflict, redemption.Status)
+		}
+
+		now := time.Now()
+		redemption.Status = to
+		if to == model.RedemptionStatusApproved {
+			redemption.ApprovedAt = &now
+		}
+		if err := tx.UpdateRedemption(ctx, redemption); err != nil {
+			return fmt.Errorf("failed to update redemption: %w", err)
+		}
+
+		if to == model.RedemptionStatusApproved {
+			description := "Reward: " + redemption.Reward.Title
+			err := s.ledger.CreateLedgerEntry(ctx, tx, &model.LedgerEntry{
+				HouseholdID:  redemption.Reward.HouseholdID,
+				UserID:       redemption.UserID,
+				Type:         model.LedgerTypeSpend,
+				Amount:       redemption.Cost.Neg(),
+				Description:  &description,
+				RedemptionID: &redemption.ID,
+				CreatedAt:    now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		household, err := getHousehold(ctx, tx, actor.HouseholdID)
+		if err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "redemption_"+string(to), map[string]interface{}{
+			"redemption_id": redemption.ID,
+			"reward_id":     redemption.RewardID,
+			"user_id":       redemption.UserID,
+			"cost":          household.FormatAmount(redemption.Cost),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redemption, nil
 }
 
-func (s *RewardService) RedeemReward(ctx context.Context, rewardID, userID int) error {
-	return nil // TODO: Implement
-}
\ No newline at end of file
+// getHouseholdReward loads a reward through st and hides rewards from other
+// households behind ErrNotFound.
+func (s *RewardService) getHouseholdReward(ctx context.Context, st store.Store, actor Actor, id int) (*model.Reward, error) {
+	reward, err := st.GetRewardByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "reward")
+	}
+	if reward.HouseholdID != actor.HouseholdID {
+		return nil, fmt.Errorf("reward %w", ErrNotFound)
+	}
+	return reward, nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/recurrence"
+	"github.com/choreme/choreme/internal/store"
+	"github.com/shopspring/decimal"
+)
+
+// maxOccurrencesPerRun caps the occurrences of one chore a scheduler run
+// materializes. Chores recur at most daily, so it covers the longest
+// schedule horizon; a chore catching up on more continues on the next run.
+const maxOccurrencesPerRun = 400
+
+// ScheduleService materializes the assignments of recurring chores ahead of
+// time.
+type ScheduleService struct {
+	store store.Store
+	audit *AuditService
+}
+
+func NewScheduleService(store store.Store, audit *AuditService) *ScheduleService {
+	return &ScheduleService{
+		store: store,
+		audit: audit,
+	}
+}
+
+// ScheduleResult summarizes one scheduler run.
+type ScheduleResult struct {
+	Chores      int `json:"chores"`
+	Assignments int `json:"assignments"`
+	Skipped     int `json:"skipped"`
+	Failed      int `json:"failed"`
+}
+
+// GenerateAssignments creates the assignments of every recurring chore up to
+// its household's schedule horizon. Each chore is scheduled in its own
+// transaction from the latest occurrence generated so far, so runs are
+// idempotent and can be repeated or run from several instances. A chore
+// that fails is logged and retried on the next run.
+func (s *ScheduleService) GenerateAssignments(ctx context.Context, now time.Time) (*ScheduleResult, error) {
+	chores, err := s.store.GetRecurringChores(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring chores: %w", err)
+	}
+
+	result := &ScheduleResult{}
+	households := make(map[int]*model.Household)
+	for _, chore := range chores {
+		household, ok := households[chore.HouseholdID]
+		if !ok {
+			if household, err = s.store.GetHouseholdByID(ctx, chore.HouseholdID); err != nil {
+				log.Printf("Scheduler: failed to get household %d: %v", chore.HouseholdID, err)
+				result.Failed++
+				continue
+			}
+			households[chore.HouseholdID] = household
+		}
+
+		created, skipped, err := s.scheduleChore(ctx, chore.ID, household, now)
+		if err != nil {
+			log.Printf("Scheduler: failed to schedule chore %d: %v", chore.ID, err)
+			result.Failed++
+			continue
+		}
+		result.Chores++
+		result.Assignments += created
+		result.Skipped += skipped
+	}
+
+	if result.Assignments > 0 || result.Failed > 0 {
+		log.Printf("Scheduler: generated %d assignments for %d chores (%d missed occurrences skipped, %d failures)",
+			result.Assignments, result.Chores, result.Skipped, result.Failed)
+	}
+	return result, nil
+}
+
+// scheduleChore generates one chore's occurrences after its latest generated
+// occurrence, up to the household's horizon. Occurrences follow the
+// household's timezone, so a chore keeps its local time of day across
+// daylight saving changes. When the scheduler has not run for a while, of
+// the occurrences already past only the latest is created: a missed
+// occurrence still produces the next chore to do, without flooding the
+// assignee with every one that passed in the meantime. At most
+// maxOccurrencesPerRun occurrences are generated in one run.
+func (s *ScheduleService) scheduleChore(ctx context.Context, choreID int, household *model.Household, now time.Time) (created, skipped int, err error) {
+	daysAhead := household.ScheduleDaysAhead
+	if daysAhead <= 0 {
+		daysAhead = model.DefaultScheduleDaysAhead
+	}
+	loc := household.Location()
+	horizon := now.In(loc).AddDate(0, 0, daysAhead)
+
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		chore, err := tx.GetChoreForUpdate(ctx, choreID)
+		if err != nil {
+			return err
+		}
+		if chore.Frequency == nil || chore.ScheduleStart == nil {
+			return nil
+		}
+		rule, err := recurrence.Parse(*chore.Frequency, chore.ScheduleStart.In(loc))
+		if err != nil || rule == nil {
+			return err
+		}
+
+		from := *chore.ScheduleStart
+		if chore.ScheduledUntil != nil {
+			from = *chore.ScheduledUntil
+		}
+		occurrences := rule.Between(from, horizon, maxOccurrencesPerRun)
+		if len(occurrences) == 0 {
+			return nil
+		}
+		latest := occurrences[len(occurrences)-1]
+
+		// A capped run still catching up on past occurrences skips all but
+		// the last, which the next run reconsiders in case it is the latest
+		// one past
+		if len(occurrences) == maxOccurrencesPerRun && latest.Before(now) {
+			skipped = len(occurrences) - 1
+			return tx.UpdateChoreSchedule(ctx, chore.ID, occurrences[skipped-1].UTC())
+		}
+
+		past := 0
+		for past < len(occurrences) && occurrences[past].Before(now) {
+			past++
+		}
+		if past > 1 {
+			skipped = past - 1
+			occurrences = occurrences[skipped:]
+		}
+
+		assignees, err := s.currentAssignees(ctx, tx, chore)
+		if err != nil {
+			return err
+		}
+		for _, due := range occurrences {
+			for _, userID := range assignees {
+				assignment := &model.Assignment{
+					ChoreID:         chore.ID,
+					AssignedTo:      userID,
+					DueDate:         due.UTC(),
+					PercentComplete: decimal.Zero,
+					Status:          model.StatusPending,
+					CreatedAt:       now,
+					UpdatedAt:       now,
+				}
+				if err := tx.CreateAssignment(ctx, assignment); err != nil {
+					return fmt.Errorf("failed to create assignment: %w", err)
+				}
+				created++
+			}
+		}
+
+		return tx.UpdateChoreSchedule(ctx, chore.ID, latest.UTC())
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return created, skipped, nil
+}
+
+// currentAssignees returns the chore's assignees that are still active
+// members of its household.
+func (s *ScheduleService) currentAssignees(ctx context.Context, tx store.Store, chore *model.Chore) ([]int, error) {
+	assignees, err := tx.GetChoreAssignees(ctx, chore.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chore assignees: %w", err)
+	}
+	users, err := tx.GetUsersByHousehold(ctx, chore.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household users: %w", err)
+	}
+	members := make(map[int]bool, len(users))
+	for _, user := range users {
+		members[user.ID] = user.Active()
+	}
+
+	var result []int
+	for _, userID := range assignees {
+		if members[userID] {
+			result = append(result, userID)
+		}
+	}
+	return result, nil
+}
@@ -1,9 +1,48 @@
 package service
 
 import (
+	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/imaging"
+	"github.com/choreme/choreme/internal/mailer"
+	"github.com/choreme/choreme/internal/model"
 	"github.com/choreme/choreme/internal/store"
 )
 
+// Actor identifies the authenticated user a service call is made on behalf
+// of. Services use it to scope every read and write to the actor's household.
+type Actor struct {
+	UserID      int
+	HouseholdID int
+	Role        model.Role
+	// Permissions are what the actor's role may do in their household, as
+	// resolved by HouseholdService.Permissions.
+	Permissions model.PermissionSet
+}
+
+// Can reports whether the actor has a permission.
+func (a Actor) Can(permission model.Permission) bool {
+	return a.Permissions[permission]
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// normalizePage applies the default and maximum page size to a listing.
+func normalizePage(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
 type Services struct {
 	Auth       *AuthService
 	Household  *HouseholdService
@@ -12,22 +51,34 @@ type Services struct {
 	Assignment *AssignmentService
 	Reward     *RewardService
 	Ledger     *LedgerService
+	Report     *ReportService
+	Schedule   *ScheduleService
 	Audit      *AuditService
+	Admin      *AdminService
 	store      store.Store
 }
 
-func New(store store.Store) *Services {
+func New(store store.Store, cfg *config.Config) *Services {
 	auditService := NewAuditService(store)
-	
+	ledgerService := NewLedgerService(store, auditService)
+	sessions := NewSessionCache(cfg.JWT.SessionCacheTTL)
+	authService := NewAuthService(store, auditService, sessions, mailer.New(cfg.SMTP), cfg)
+
 	return &Services{
-		Auth:       NewAuthService(store, auditService),
-		Household:  NewHouseholdService(store, auditService),
-		User:       NewUserService(store, auditService),
+		Auth:       authService,
+		Household:  NewHouseholdService(store, auditService, sessions),
+		User:       NewUserService(store, auditService, sessions),
 		Chore:      NewChoreService(store, auditService),
-		Assignment: NewAssignmentService(store, auditService),
-		Reward:     NewRewardService(store, auditService),
-		Ledger:     NewLedgerService(store, auditService),
+		Assignment: NewAssignmentService(store, auditService, ledgerService, imaging.Options{
+			MaxBytes:     cfg.Image.MaxSizeMB << 20,
+			MaxDimension: cfg.Image.MaxDimensionPx,
+		}),
+		Reward:     NewRewardService(store, auditService, ledgerService),
+		Ledger:     ledgerService,
+		Report:     NewReportService(store),
+		Schedule:   NewScheduleService(store, auditService),
 		Audit:      auditService,
+		Admin:      NewAdminService(store, auditService, sessions, authService),
 		store:      store,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/choreme/choreme/internal/auth"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+// ErrInvalidRefreshToken is returned for refresh tokens that are unknown,
+// expired, revoked or already rotated.
+var ErrInvalidRefreshToken = fmt.Errorf("%w: invalid refresh token", ErrUnauthorized)
+
+// CreateSession starts a new refresh token family for a user who just
+// authenticated and returns its first refresh token.
+func (s *AuthService) CreateSession(ctx context.Context, user *model.User) (string, error) {
+	familyID, err := newFamilyID()
+	if err != nil {
+		return "", err
+	}
+	return s.issueRefreshToken(ctx, s.store, user.ID, familyID, time.Now())
+}
+
+// RefreshSession rotates a refresh token: the presented token is marked used
+// and a new one in the same family is returned along with its user. A token
+// that was already rotated being presented again means it leaked, so the
+// whole family is revoked and the legitimate client has to log in again too.
+func (s *AuthService) RefreshSession(ctx context.Context, refreshToken string) (*model.User, string, error) {
+	var user *model.User
+	var next string
+	var reused *model.RefreshToken
+	now := time.Now()
+
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		token, err := tx.GetRefreshTokenForUpdate(ctx, auth.HashToken(refreshToken))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("failed to get refresh token: %w", err)
+		}
+		if token.RevokedAt != nil || !now.Before(token.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+		if token.UsedAt != nil {
+			// Revoke the family in this transaction and report the failure
+			// once it has committed
+			reused = token
+			if err := tx.RevokeRefreshTokenFamily(ctx, token.FamilyID, now); err != nil {
+				return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+			}
+			// The access tokens issued alongside may have leaked too
+			if err := tx.BumpSessionEpoch(ctx, token.UserID); err != nil {
+				return fmt.Errorf("failed to end sessions: %w", err)
+			}
+			user, err = tx.GetUserByID(ctx, token.UserID)
+			if err != nil {
+				return notFound(err, "user")
+			}
+			return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "refresh_token_reused", map[string]interface{}{
+				"user_id":   user.ID,
+				"family_id": token.FamilyID,
+			})
+		}
+
+		user, err = tx.GetUserByID(ctx, token.UserID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if !user.Active() {
+			return ErrAccountDeactivated
+		}
+		if err := checkHousehold(ctx, tx, user.HouseholdID); err != nil {
+			return err
+		}
+		required, err := s.twoFactorRequired(ctx, tx, user)
+		if err != nil {
+			return err
+		}
+		if required && !user.TwoFactorEnabled() {
+			return ErrTwoFactorSetupRequired
+		}
+		if err := tx.MarkRefreshTokenUsed(ctx, token.ID, now); err != nil {
+			return fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+		next, err = s.issueRefreshToken(ctx, tx, user.ID, token.FamilyID, now)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if reused != nil {
+		s.sessions.Invalidate(reused.UserID)
+		return nil, "", ErrInvalidRefreshToken
+	}
+	return user, next, nil
+}
+
+// Logout revokes the session the refresh token belongs to. Unknown tokens
+// are ignored, so logging out twice succeeds.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	return s.store.WithTx(ctx, func(tx store.Store) error {
+		token, err := tx.GetRefreshTokenForUpdate(ctx, auth.HashToken(refreshToken))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get refresh token: %w", err)
+		}
+		if token.RevokedAt != nil {
+			return nil
+		}
+		if err := tx.RevokeRefreshTokenFamily(ctx, token.FamilyID, time.Now()); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+
+		user, err := tx.GetUserByID(ctx, token.UserID)
+		if err != nil {
+			return notFound(err, "user")
+		}
+		return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "user_logout", map[string]interface{}{
+			"user_id": user.ID,
+		})
+	})
+}
+
+// LogoutAll revokes every refresh token of the actor and invalidates their
+// access tokens, ending their sessions on all devices.
+func (s *AuthService) LogoutAll(ctx context.Context, actor Actor) error {
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.RevokeUserRefreshTokens(ctx, actor.UserID, time.Now()); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		if err := tx.BumpSessionEpoch(ctx, actor.UserID); err != nil {
+			return fmt.Errorf("failed to end sessions: %w", err)
+		}
+		return s.audit.Record(ctx, tx, actor.HouseholdID, actor.UserID, "user_logout_all", map[string]interface{}{
+			"user_id": actor.UserID,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.sessions.Invalidate(actor.UserID)
+	return nil
+}
+
+// TokenCleanupResult counts the expired tokens a cleanup deleted.
+type TokenCleanupResult struct {
+	RefreshTokens int64 `json:"refresh_tokens"`
+	UserTokens    int64 `json:"user_tokens"`
+	OIDCRequests  int64 `json:"oidc_auth_requests"`
+}
+
+// CleanupTokens deletes refresh tokens, mailed tokens and OIDC login
+// requests that expired before now. Rotated refresh tokens are kept until
+// they expire so their reuse is still detected.
+func (s *AuthService) CleanupTokens(ctx context.Context, now time.Time) (*TokenCleanupResult, error) {
+	var result TokenCleanupResult
+	var err error
+	if result.RefreshTokens, err = s.store.DeleteExpiredRefreshTokens(ctx, now); err != nil {
+		return nil, fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+	if result.UserTokens, err = s.store.DeleteExpiredUserTokens(ctx, now); err != nil {
+		return nil, fmt.Errorf("failed to delete expired user tokens: %w", err)
+	}
+	if result.OIDCRequests, err = s.store.DeleteExpiredOIDCAuthRequests(ctx, now); err != nil {
+		return nil, fmt.Errorf("failed to delete expired OIDC requests: %w", err)
+	}
+	return &result, nil
+}
+
+func (s *AuthService) issueRefreshToken(ctx context.Context, st store.Store, userID int, familyID string, now time.Time) (string, error) {
+	raw, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := &model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: auth.HashToken(raw),
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	}
+	if err := st.CreateRefreshToken(ctx, token); err != nil {
+		return "", fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return raw, nil
+}
+
+func newFamilyID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate token family: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// ValidateSession reports whether an access token issued at epoch is still
+// valid for the user, i.e. the user exists and their access hasn't changed
+// since. Results are cached briefly so most requests don't hit the store.
+func (s *AuthService) ValidateSession(ctx context.Context, userID, epoch int) (bool, error) {
+	if current, ok := s.sessions.get(userID); ok {
+		return current == epoch, nil
+	}
+
+	user, err := s.store.GetUserByID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	s.sessions.set(userID, user.SessionEpoch)
+	return user.SessionEpoch == epoch, nil
+}
+
+// SessionCache remembers users' current session epochs for a short time.
+// Services that change a user's access invalidate the user's entry, so the
+// change takes effect immediately on this instance and within the TTL on
+// others.
+type SessionCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[int]sessionEntry
+}
+
+type sessionEntry struct {
+	epoch   int
+	expires time.Time
+}
+
+func NewSessionCache(ttl time.Duration) *SessionCache {
+	return &SessionCache{
+		ttl:     ttl,
+		entries: make(map[int]sessionEntry),
+	}
+}
+
+// Invalidate drops the cached epoch of a user.
+func (c *SessionCache) Invalidate(userID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, userID)
+}
+
+func (c *SessionCache) get(userID int) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[userID]
+	if !ok || time.Now().After(entry.expires) {
+		return 0, false
+	}
+	return entry.epoch, true
+}
+
+func (c *SessionCache) set(userID, epoch int) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxSessionCacheEntries {
+		for id, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[userID] = sessionEntry{epoch: epoch, expires: now.Add(c.ttl)}
+}
+
+// maxSessionCacheEntries is the cache size above which expired entries are
+// swept on insert.
+const maxSessionCacheEntries = 10000
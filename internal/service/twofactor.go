@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/choreme/choreme/internal/auth"
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+)
+
+const (
+	// twoFactorChallengeTTL is how long a user has to give their second
+	// factor after their password.
+	twoFactorChallengeTTL = 5 * time.Minute
+	recoveryCodeCount     = 10
+	totpIssuer            = "ChoreMe"
+)
+
+var (
+	// ErrInvalidChallenge is returned for login challenge tokens that are
+	// unknown, expired or already used.
+	ErrInvalidChallenge = fmt.Errorf("%w: invalid or expired login challenge", ErrUnauthorized)
+	// ErrInvalidTwoFactorCode is returned for a wrong TOTP or recovery code.
+	ErrInvalidTwoFactorCode = fmt.Errorf("%w: invalid two-factor code", ErrUnauthorized)
+	// ErrTwoFactorSetupRequired is returned when refreshing the session of a
+	// user whose household requires two-factor authentication they haven't
+	// set up; logging in again walks them through it.
+	ErrTwoFactorSetupRequired = fmt.Errorf("%w: two-factor authentication setup required", ErrUnauthorized)
+)
+
+// LoginResult is the outcome of a correct password. Either User is set and
+// a session can start, or Challenge is and the second factor is needed.
+type LoginResult struct {
+	User      *model.User
+	Challenge *model.TwoFactorChallengeResponse
+}
+
+// twoFactorRequired reports whether the user's household makes two-factor
+// authentication mandatory for them. It applies to everyone who manages
+// the household.
+func (s *AuthService) twoFactorRequired(ctx context.Context, st store.Store, user *model.User) (bool, error) {
+	if !managingRole(user.Role) {
+		return false, nil
+	}
+	household, err := st.GetHouseholdByID(ctx, user.HouseholdID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get household: %w", err)
+	}
+	return household.RequireTwoFactor, nil
+}
+
+// createChallenge hands out the token a login continues with once the
+// password was right.
+func (s *AuthService) createChallenge(ctx context.Context, user *model.User) (*model.TwoFactorChallengeResponse, error) {
+	token, err := s.issueUserToken(ctx, s.store, user, model.TokenPurposeTwoFactorChallenge, twoFactorChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		SetupRequired:     !user.TwoFactorEnabled(),
+		ChallengeToken:    token,
+		ExpiresIn:         int(twoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+// SetupTwoFactorForChallenge starts enrollment for a user who has to set up
+// two-factor authentication before their login can complete. The challenge
+// stays valid; CompleteTwoFactorLogin with a code from the new secret
+// finishes both.
+func (s *AuthService) SetupTwoFactorForChallenge(ctx context.Context, challengeToken string) (*model.TwoFactorSetup, error) {
+	var setup *model.TwoFactorSetup
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		user, _, err := s.checkUserToken(ctx, tx, challengeToken, model.TokenPurposeTwoFactorChallenge)
+		if errors.Is(err, ErrInvalidToken) {
+			return ErrInvalidChallenge
+		}
+		if err != nil {
+			return err
+		}
+		if user.TwoFactorEnabled() {
+			return fmt.Errorf("%w: two-factor authentication is already enabled", ErrConflict)
+		}
+		setup, err = s.newTOTPSecret(ctx, tx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return setup, nil
+}
+
+// CompleteTwoFactorLogin checks the second factor of a login and returns the
+// user to start a session for. For a user enrolling during login the code
+// must come from the new secret, which enables two-factor authentication;
+// their recovery codes are returned then. A wrong code counts as a failed
+// login towards the lockout.
+func (s *AuthService) CompleteTwoFactorLogin(ctx context.Context, challengeToken, code string) (*model.User, []string, error) {
+	var user *model.User
+	var recoveryCodes []string
+	var failed bool
+	now := time.Now()
+
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		var token *model.UserToken
+		var err error
+		user, token, err = s.checkUserToken(ctx, tx, challengeToken, model.TokenPurposeTwoFactorChallenge)
+		if errors.Is(err, ErrInvalidToken) {
+			return ErrInvalidChallenge
+		}
+		if err != nil {
+			return err
+		}
+		if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+			return &AccountLockedError{Until: *user.LockedUntil}
+		}
+		if !user.Active() {
+			return ErrAccountDeactivated
+		}
+		if err := checkHousehold(ctx, tx, user.HouseholdID); err != nil {
+			return err
+		}
+
+		enrolling := !user.TwoFactorEnabled()
+		if enrolling && user.TOTPSecret == nil {
+			return invalidf("set up two-factor authentication first")
+		}
+		ok, err := s.checkSecondFactor(ctx, tx, user, code, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			failed = true
+			return nil
+		}
+
+		if err := tx.MarkUserTokenUsed(ctx, token.ID, now); err != nil {
+			return fmt.Errorf("failed to use token: %w", err)
+		}
+		if enrolling {
+			if recoveryCodes, err = s.enableTwoFactor(ctx, tx, user, now); err != nil {
+				return err
+			}
+		}
+		if user.FailedLogins > 0 {
+			if err := tx.UpdateLoginFailures(ctx, user.ID, 0, nil); err != nil {
+				return fmt.Errorf("failed to reset failed logins: %w", err)
+			}
+		}
+		return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "user_login", map[string]interface{}{
+			"user_id":    user.ID,
+			"email":      user.Email,
+			"two_factor": true,
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if failed {
+		if err := s.recordLoginFailure(ctx, user.ID, now); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrInvalidTwoFactorCode
+	}
+	return user, recoveryCodes, nil
+}
+
+// GetTwoFactorStatus reports the actor's two-factor authentication state.
+func (s *AuthService) GetTwoFactorStatus(ctx context.Context, actor Actor) (*model.TwoFactorStatus, error) {
+	user, err := s.store.GetMember(ctx, actor.HouseholdID, actor.UserID)
+	if err != nil {
+		return nil, notFound(err, "user")
+	}
+	required, err := s.twoFactorRequired(ctx, s.store, user)
+	if err != nil {
+		return nil, err
+	}
+	status := &model.TwoFactorStatus{
+		Enabled:   user.TwoFactorEnabled(),
+		EnabledAt: user.TOTPEnabledAt,
+		Required:  required,
+	}
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = s.store.CountUnusedRecoveryCodes(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+	}
+	return status, nil
+}
+
+// SetupTwoFactor generates a new TOTP secret for the actor, who confirms
+// their password first. It takes effect once EnableTwoFactor confirms a code
+// from it.
+func (s *AuthService) SetupTwoFactor(ctx context.Context, actor Actor, password string) (*model.TwoFactorSetup, error) {
+	var setup *model.TwoFactorSetup
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		user, err := tx.GetMemberForUpdate(ctx, actor.HouseholdID, actor.UserID)
+		if err != nil {
+			return notFound(err, "user")
+		}
+		if err := auth.VerifyPassword(user.PasswordHash, password); err != nil {
+			return invalidf("incorrect password")
+		}
+		if user.TwoFactorEnabled() {
+			return fmt.Errorf("%w: two-factor authentication is already enabled", ErrConflict)
+		}
+		setup, err = s.newTOTPSecret(ctx, tx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return setup, nil
+}
+
+// EnableTwoFactor turns on two-factor authentication once the actor proves
+// their authenticator works with a code from the secret SetupTwoFactor
+// generated. It returns the recovery codes, which are shown only now.
+func (s *AuthService) EnableTwoFactor(ctx context.Context, actor Actor, code string) ([]string, error) {
+	var recoveryCodes []string
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		user, err := tx.GetMemberForUpdate(ctx, actor.HouseholdID, actor.UserID)
+		if err != nil {
+			return notFound(err, "user")
+		}
+		if user.TwoFactorEnabled() {
+			return fmt.Errorf("%w: two-factor authentication is already enabled", ErrConflict)
+		}
+		if user.TOTPSecret == nil {
+			return invalidf("set up two-factor authentication first")
+		}
+		now := time.Now()
+		ok, err := s.checkSecondFactor(ctx, tx, user, code, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidf("invalid two-factor code")
+		}
+		recoveryCodes, err = s.enableTwoFactor(ctx, tx, user, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off for the actor after
+// checking their password and a current code. Members whose household
+// requires it can't turn it off.
+func (s *AuthService) DisableTwoFactor(ctx context.Context, actor Actor, password, code string) error {
+	return s.store.WithTx(ctx, func(tx store.Store) error {
+		user, err := tx.GetMemberForUpdate(ctx, actor.HouseholdID, actor.UserID)
+		if err != nil {
+			return notFound(err, "user")
+		}
+		if !user.TwoFactorEnabled() {
+			return invalidf("two-factor authentication is not enabled")
+		}
+		if err := auth.VerifyPassword(user.PasswordHash, password); err != nil {
+			return invalidf("incorrect password")
+		}
+		required, err := s.twoFactorRequired(ctx, tx, user)
+		if err != nil {
+			return err
+		}
+		if required {
+			return fmt.Errorf("%w: your household requires two-factor authentication", ErrForbidden)
+		}
+		ok, err := s.checkSecondFactor(ctx, tx, user, code, time.Now())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidf("invalid two-factor code")
+		}
+
+		user.TOTPSecret = nil
+		user.TOTPEnabledAt = nil
+		user.TOTPLastStep = 0
+		if err := tx.UpdateUserTwoFactor(ctx, user); err != nil {
+			return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+		}
+		if err := tx.DeleteRecoveryCodes(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "two_factor_disabled", map[string]interface{}{
+			"user_id": user.ID,
+		})
+	})
+}
+
+// RegenerateRecoveryCodes replaces the actor's recovery codes after checking
+// a current code, and returns the new ones.
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, actor Actor, code string) ([]string, error) {
+	var recoveryCodes []string
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		user, err := tx.GetMemberForUpdate(ctx, actor.HouseholdID, actor.UserID)
+		if err != nil {
+			return notFound(err, "user")
+		}
+		if !user.TwoFactorEnabled() {
+			return invalidf("two-factor authentication is not enabled")
+		}
+		now := time.Now()
+		ok, err := s.checkSecondFactor(ctx, tx, user, code, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return invalidf("invalid two-factor code")
+		}
+		if recoveryCodes, err = s.replaceRecoveryCodes(ctx, tx, user.ID, now); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "recovery_codes_regenerated", map[string]interface{}{
+			"user_id": user.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+// checkSecondFactor reports whether code is a current TOTP code for the
+// locked user or, once two-factor authentication is on, one of their unused
+// recovery codes. Either is used up by the check.
+func (s *AuthService) checkSecondFactor(ctx context.Context, tx store.Store, user *model.User, code string, now time.Time) (bool, error) {
+	if user.TOTPSecret == nil {
+		return false, nil
+	}
+	if step, ok := auth.ValidateTOTP(*user.TOTPSecret, code, now, user.TOTPLastStep); ok {
+		user.TOTPLastStep = step
+		if err := tx.UpdateUserTwoFactor(ctx, user); err != nil {
+			return false, fmt.Errorf("failed to update two-factor state: %w", err)
+		}
+		return true, nil
+	}
+	if !user.TwoFactorEnabled() {
+		return false, nil
+	}
+
+	used, err := tx.UseRecoveryCode(ctx, user.ID, auth.HashRecoveryCode(code), now)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if !used {
+		return false, nil
+	}
+	return true, s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "recovery_code_used", map[string]interface{}{
+		"user_id": user.ID,
+	})
+}
+
+// newTOTPSecret stores a new, not yet enabled TOTP secret for the locked
+// user, replacing any earlier unconfirmed one.
+func (s *AuthService) newTOTPSecret(ctx context.Context, tx store.Store, user *model.User) (*model.TwoFactorSetup, error) {
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	user.TOTPSecret = &secret
+	user.TOTPEnabledAt = nil
+	user.TOTPLastStep = 0
+	if err := tx.UpdateUserTwoFactor(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+	return &model.TwoFactorSetup{
+		Secret: secret,
+		URI:    auth.TOTPURI(secret, totpIssuer, user.Email),
+	}, nil
+}
+
+// enableTwoFactor turns on two-factor authentication for the locked user
+// whose pending secret was just confirmed, and returns their recovery codes.
+func (s *AuthService) enableTwoFactor(ctx context.Context, tx store.Store, user *model.User, now time.Time) ([]string, error) {
+	user.TOTPEnabledAt = &now
+	if err := tx.UpdateUserTwoFactor(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	recoveryCodes, err := s.replaceRecoveryCodes(ctx, tx, user.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	err = s.audit.Record(ctx, tx, user.HouseholdID, user.ID, "two_factor_enabled", map[string]interface{}{
+		"user_id": user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+func (s *AuthService) replaceRecoveryCodes(ctx context.Context, tx store.Store, userID int, now time.Time) ([]string, error) {
+	if err := tx.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := auth.GenerateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		err = tx.CreateRecoveryCode(ctx, &model.RecoveryCode{
+			UserID:    userID,
+			CodeHash:  auth.HashRecoveryCode(code),
+			CreatedAt: now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create recovery code: %w", err)
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
@@ -8,14 +8,16 @@ import (
 )
 
 type UserService struct {
-	store store.Store
-	audit *AuditService
+	store    store.Store
+	audit    *AuditService
+	sessions *SessionCache
 }
 
-func NewUserService(store store.Store, audit *AuditService) *UserService {
+func NewUserService(store store.Store, audit *AuditService, sessions *SessionCache) *UserService {
 	return &UserService{
-		store: store,
-		audit: audit,
+		store:    store,
+		audit:    audit,
+		sessions: sessions,
 	}
 }
 
@@ -23,6 +25,11 @@ func (s *UserService) GetUserByID(ctx context.Context, id int) (*model.User, err
 	return s.store.GetUserByID(ctx, id)
 }
 
+// GetUserInHousehold returns a user as a member of the given household.
+func (s *UserService) GetUserInHousehold(ctx context.Context, householdID, userID int) (*model.User, error) {
+	return s.store.GetMember(ctx, householdID, userID)
+}
+
 func (s *UserService) GetUsersByHousehold(ctx context.Context, householdID int) ([]*model.User, error) {
 	return s.store.GetUsersByHousehold(ctx, householdID)
 }
@@ -32,6 +39,7 @@ func (s *UserService) UpdateUser(ctx context.Context, user *model.User) error {
 	if err != nil {
 		return err
 	}
+	s.sessions.Invalidate(user.ID)
 
 	// Audit log
 	s.audit.LogAction(ctx, user.HouseholdID, user.ID, "user_updated", map[string]interface{}{
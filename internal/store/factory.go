@@ -3,19 +3,39 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"sync"
 
 	"github.com/choreme/choreme/internal/config"
-	"github.com/choreme/choreme/internal/store/mysql"
-	"github.com/choreme/choreme/internal/store/postgres"
-	"github.com/choreme/choreme/internal/store/sqlite"
-
-	// Import database drivers
-	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/lib/pq"
-	_ "github.com/mattn/go-sqlite3"
 )
 
+// Constructor wraps an open database handle in a backend Store.
+type Constructor func(db *sql.DB) Store
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Constructor{}
+)
+
+// Register makes a backend available to NewStore under the given database
+// type. Backends call it from init, so binaries enable a backend by importing
+// its package.
+func Register(dbType string, ctor Constructor) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if _, dup := backends[dbType]; dup {
+		panic("store: Register called twice for " + dbType)
+	}
+	backends[dbType] = ctor
+}
+
 func NewStore(cfg *config.DatabaseConfig) (Store, error) {
+	backendsMu.RLock()
+	ctor, ok := backends[cfg.Type]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported database type: %s", cfg.Type)
+	}
+
 	db, err := sql.Open(cfg.DriverName(), cfg.ConnectionString())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -25,14 +45,5 @@ func NewStore(cfg *config.DatabaseConfig) (Store, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	switch cfg.Type {
-	case "postgres":
-		return postgres.New(db), nil
-	case "mysql":
-		return mysql.New(db), nil
-	case "sqlite":
-		return sqlite.New(db), nil
-	default:
-		return nil, fmt.Errorf("unsupported database type: %s", cfg.Type)
-	}
-}
\ No newline at end of file
+	return ctor(db), nil
+}
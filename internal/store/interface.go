@@ -2,6 +2,9 @@ package store
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/choreme/choreme/internal/model"
 	"github.com/shopspring/decimal"
@@ -13,25 +16,92 @@ type Store interface {
 	Ping() error
 
 	// Transaction support
-	BeginTx(ctx context.Context) (interface{}, error)
+	BeginTx(ctx context.Context) (Tx, error)
+	// WithTx runs fn against a Store bound to a single transaction, committing
+	// when fn returns nil and rolling back otherwise. Called on a Tx it joins
+	// the running transaction.
+	WithTx(ctx context.Context, fn func(Store) error) error
 
 	// Household operations
 	CreateHousehold(ctx context.Context, household *model.Household) error
 	GetHouseholdByID(ctx context.Context, id int) (*model.Household, error)
-	GetHouseholdByInviteCode(ctx context.Context, inviteCode string) (*model.Household, error)
-	UpdateHouseholdInviteCode(ctx context.Context, id int, inviteCode string) error
+	// UpdateHousehold saves a household's name and settings.
+	UpdateHousehold(ctx context.Context, household *model.Household) error
+	// GetHouseholds returns every household on the instance with its
+	// member and chore counts, oldest first.
+	GetHouseholds(ctx context.Context) ([]*model.HouseholdSummary, error)
+	// SuspendHousehold suspends a household or, with nil, lifts its
+	// suspension.
+	SuspendHousehold(ctx context.Context, id int, suspendedAt *time.Time) error
 
-	// User operations
+	// User operations. Users are loaded as a member of one household, with
+	// the role and status of that membership; unless a household is given,
+	// it is the one they are currently in.
+	// CreateUser creates a user along with their membership of
+	// user.HouseholdID.
 	CreateUser(ctx context.Context, user *model.User) error
 	GetUserByID(ctx context.Context, id int) (*model.User, error)
+	// GetUserForUpdate is GetUserByID that also locks the row for the rest
+	// of the transaction when called on a Tx.
+	GetUserForUpdate(ctx context.Context, id int) (*model.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	// GetMember loads a user as a member of a household, removed members
+	// included.
+	GetMember(ctx context.Context, householdID, userID int) (*model.User, error)
+	// GetMemberForUpdate is GetMember that also locks the user and the
+	// membership for the rest of the transaction when called on a Tx.
+	// Balance-changing operations lock the member to serialize against each
+	// other.
+	GetMemberForUpdate(ctx context.Context, householdID, userID int) (*model.User, error)
+	// GetUsersByHousehold returns the household's members, deactivated ones
+	// included but not removed ones.
 	GetUsersByHousehold(ctx context.Context, householdID int) ([]*model.User, error)
+	// UpdateUser writes a user back, with their role in user.HouseholdID.
+	// It doesn't change the household they are currently in.
 	UpdateUser(ctx context.Context, user *model.User) error
+	// SetCurrentHousehold sets the household the user's logins and refreshed
+	// sessions land in.
+	SetCurrentHousehold(ctx context.Context, userID, householdID int) error
+	// BumpSessionEpoch invalidates every access token issued to the user.
+	BumpSessionEpoch(ctx context.Context, userID int) error
+	// UpdateLoginFailures records the user's consecutive failed logins and
+	// any lockout they triggered.
+	UpdateLoginFailures(ctx context.Context, userID int, failedLogins int, lockedUntil *time.Time) error
+	// UpdateUserTwoFactor saves the user's TOTP secret, enablement and last
+	// accepted time step.
+	UpdateUserTwoFactor(ctx context.Context, user *model.User) error
+	// UpdateUserPIN sets or, with nil, removes the user's PIN hash.
+	UpdateUserPIN(ctx context.Context, userID int, pinHash *string) error
+	// UpdateUserStatus saves when the user was deactivated in and removed
+	// from user.HouseholdID.
+	UpdateUserStatus(ctx context.Context, user *model.User) error
 	DeleteUser(ctx context.Context, id int) error
 
+	// Membership operations
+	// GetMemberships returns the households the user belongs to, oldest
+	// membership first. Households they were removed from are left out.
+	GetMemberships(ctx context.Context, userID int) ([]*model.Membership, error)
+	// SaveMembership adds a user to a household, or gives a member who was
+	// removed a fresh membership.
+	SaveMembership(ctx context.Context, membership *model.Membership) error
+	// CountSystemAdmins returns how many system administrator memberships
+	// the instance has.
+	CountSystemAdmins(ctx context.Context) (int, error)
+
 	// Chore operations
 	CreateChore(ctx context.Context, chore *model.Chore) error
 	GetChoreByID(ctx context.Context, id int) (*model.Chore, error)
+	// GetChoreForUpdate is GetChoreByID that also locks the row for the rest
+	// of the transaction when called on a Tx.
+	GetChoreForUpdate(ctx context.Context, id int) (*model.Chore, error)
+	// GetRecurringChores returns chores in every household that have a
+	// frequency and a schedule start.
+	GetRecurringChores(ctx context.Context) ([]*model.Chore, error)
+	// UpdateChoreSchedule records the latest occurrence generated for a
+	// recurring chore.
+	UpdateChoreSchedule(ctx context.Context, id int, scheduledUntil time.Time) error
+	// GetChoreAssignees returns the users the chore has been assigned to.
+	GetChoreAssignees(ctx context.Context, choreID int) ([]int, error)
 	GetChoresByHousehold(ctx context.Context, householdID int, filters model.ChoreFilters) ([]*model.Chore, error)
 	UpdateChore(ctx context.Context, chore *model.Chore) error
 	DeleteChore(ctx context.Context, id int) error
@@ -39,11 +109,17 @@ type Store interface {
 	// Assignment operations
 	CreateAssignment(ctx context.Context, assignment *model.Assignment) error
 	GetAssignmentByID(ctx context.Context, id int) (*model.Assignment, error)
-	GetAssignmentsByUser(ctx context.Context, userID int, filters model.AssignmentFilters) ([]*model.Assignment, error)
+	// GetAssignmentForUpdate is GetAssignmentByID that also locks the row
+	// for the rest of the transaction when called on a Tx.
+	GetAssignmentForUpdate(ctx context.Context, id int) (*model.Assignment, error)
+	// GetAssignmentsByUser returns a member's assignments in one household.
+	GetAssignmentsByUser(ctx context.Context, householdID, userID int, filters model.AssignmentFilters) ([]*model.Assignment, error)
 	GetAssignmentsByHousehold(ctx context.Context, householdID int, filters model.AssignmentFilters) ([]*model.Assignment, error)
 	UpdateAssignment(ctx context.Context, assignment *model.Assignment) error
 	DeleteAssignment(ctx context.Context, id int) error
-	GetOverdueAssignments(ctx context.Context) ([]*model.Assignment, error)
+	// GetOverdueAssignments returns open assignments due before now, along
+	// with late assignments whose chore expires, oldest first.
+	GetOverdueAssignments(ctx context.Context, now time.Time) ([]*model.Assignment, error)
 
 	// Reward operations
 	CreateReward(ctx context.Context, reward *model.Reward) error
@@ -55,32 +131,159 @@ type Store interface {
 	// Redemption operations
 	CreateRedemption(ctx context.Context, redemption *model.Redemption) error
 	GetRedemptionByID(ctx context.Context, id int) (*model.Redemption, error)
-	GetRedemptionsByUser(ctx context.Context, userID int) ([]*model.Redemption, error)
-	GetRedemptionsByHousehold(ctx context.Context, householdID int) ([]*model.Redemption, error)
+	// GetRedemptionForUpdate is GetRedemptionByID that also locks the row
+	// for the rest of the transaction when called on a Tx.
+	GetRedemptionForUpdate(ctx context.Context, id int) (*model.Redemption, error)
+	// GetRedemptionsByUser returns a member's redemptions in one household.
+	GetRedemptionsByUser(ctx context.Context, householdID, userID int, filters model.RedemptionFilters) ([]*model.Redemption, error)
+	GetRedemptionsByHousehold(ctx context.Context, householdID int, filters model.RedemptionFilters) ([]*model.Redemption, error)
 	UpdateRedemption(ctx context.Context, redemption *model.Redemption) error
 
-	// Ledger operations
+	// Ledger operations. Entries and balances belong to a membership: a
+	// user has a separate balance in each of their households.
 	CreateLedgerEntry(ctx context.Context, entry *model.LedgerEntry) error
-	GetLedgerEntriesByUser(ctx context.Context, userID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error)
+	GetLedgerEntriesByUser(ctx context.Context, householdID, userID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error)
 	GetLedgerEntriesByHousehold(ctx context.Context, householdID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error)
-	GetUserBalance(ctx context.Context, userID int) (decimal.Decimal, error)
+	GetUserBalance(ctx context.Context, householdID, userID int) (decimal.Decimal, error)
+	// GetHeldBalance sums the cost of the member's pending redemptions.
+	GetHeldBalance(ctx context.Context, householdID, userID int) (decimal.Decimal, error)
+	// GetAllUserBalances returns the balance of each of the household's
+	// members who weren't removed.
 	GetAllUserBalances(ctx context.Context, householdID int) ([]*model.UserBalance, error)
 
 	// Audit log operations
 	CreateAuditLog(ctx context.Context, log *model.AuditLog) error
+	// GetAuditLogs returns audit entries of every household, newest first,
+	// unless the filters name one.
+	GetAuditLogs(ctx context.Context, filters model.AuditFilters) ([]*model.AuditLog, error)
 	GetAuditLogsByHousehold(ctx context.Context, householdID int, filters model.AuditFilters) ([]*model.AuditLog, error)
 	GetAuditLogsByUser(ctx context.Context, userID int, filters model.AuditFilters) ([]*model.AuditLog, error)
+
+	// Refresh token operations
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	// GetRefreshTokenForUpdate looks a token up by hash and, inside a
+	// transaction, locks it so a token can only be rotated once.
+	GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id int, usedAt time.Time) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int, revokedAt time.Time) error
+	// DeleteExpiredRefreshTokens removes tokens that expired before the given
+	// time and returns how many were removed.
+	DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) (int64, error)
+
+	// User token operations
+	CreateUserToken(ctx context.Context, token *model.UserToken) error
+	// GetUserTokenForUpdate looks a token up by hash and, inside a
+	// transaction, locks it so it can only be used once.
+	GetUserTokenForUpdate(ctx context.Context, tokenHash string) (*model.UserToken, error)
+	MarkUserTokenUsed(ctx context.Context, id int, usedAt time.Time) error
+	// InvalidateUserTokens marks the user's unused tokens for purpose as used.
+	InvalidateUserTokens(ctx context.Context, userID int, purpose model.TokenPurpose, usedAt time.Time) error
+	DeleteExpiredUserTokens(ctx context.Context, before time.Time) (int64, error)
+
+	// Household device operations
+	CreateHouseholdDevice(ctx context.Context, device *model.HouseholdDevice) error
+	GetHouseholdDeviceByID(ctx context.Context, id int) (*model.HouseholdDevice, error)
+	GetHouseholdDeviceByTokenHash(ctx context.Context, tokenHash string) (*model.HouseholdDevice, error)
+	GetHouseholdDevices(ctx context.Context, householdID int) ([]*model.HouseholdDevice, error)
+	TouchHouseholdDevice(ctx context.Context, id int, usedAt time.Time) error
+	RevokeHouseholdDevice(ctx context.Context, id int, revokedAt time.Time) error
+
+	// API token operations
+	CreateAPIToken(ctx context.Context, token *model.APIToken) error
+	GetAPITokenByID(ctx context.Context, id int) (*model.APIToken, error)
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (*model.APIToken, error)
+	GetAPITokens(ctx context.Context, userID int) ([]*model.APIToken, error)
+	TouchAPIToken(ctx context.Context, id int, usedAt time.Time) error
+	DeleteAPIToken(ctx context.Context, id int) error
+
+	// Role permission operations
+	GetRolePermissions(ctx context.Context, householdID int) ([]*model.RolePermission, error)
+	CreateRolePermission(ctx context.Context, permission *model.RolePermission) error
+	// DeleteRolePermissions removes a role's overrides in a household,
+	// restoring its defaults.
+	DeleteRolePermissions(ctx context.Context, householdID int, role model.Role) error
+
+	// Invitation operations
+	CreateInvitation(ctx context.Context, invitation *model.Invitation) error
+	GetInvitationByID(ctx context.Context, id int) (*model.Invitation, error)
+	// GetInvitationByCodeForUpdate looks an invitation up by code and, inside
+	// a transaction, locks it until the use is counted.
+	GetInvitationByCodeForUpdate(ctx context.Context, code string) (*model.Invitation, error)
+	GetInvitations(ctx context.Context, householdID int) ([]*model.Invitation, error)
+	// UseInvitation counts one use of an invitation.
+	UseInvitation(ctx context.Context, id int) error
+	RevokeInvitation(ctx context.Context, id int, revokedAt time.Time) error
+
+	// User identity operations
+	CreateUserIdentity(ctx context.Context, identity *model.UserIdentity) error
+	GetUserIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error)
+	GetUserIdentityByID(ctx context.Context, id int) (*model.UserIdentity, error)
+	GetUserIdentities(ctx context.Context, userID int) ([]*model.UserIdentity, error)
+	// TouchUserIdentity records a login with the identity and the email
+	// address the provider reported for it.
+	TouchUserIdentity(ctx context.Context, id int, email *string, loginAt time.Time) error
+	DeleteUserIdentity(ctx context.Context, id int) error
+
+	// OIDC authorization request operations
+	CreateOIDCAuthRequest(ctx context.Context, req *model.OIDCAuthRequest) error
+	// GetOIDCAuthRequestForUpdate looks a request up by state hash and,
+	// inside a transaction, locks it so it can only be used once.
+	GetOIDCAuthRequestForUpdate(ctx context.Context, stateHash string) (*model.OIDCAuthRequest, error)
+	DeleteOIDCAuthRequest(ctx context.Context, id int) error
+	DeleteExpiredOIDCAuthRequests(ctx context.Context, before time.Time) (int64, error)
+
+	// Recovery code operations
+	CreateRecoveryCode(ctx context.Context, code *model.RecoveryCode) error
+	// UseRecoveryCode marks the user's unused code with the given hash as
+	// used and reports whether there was one.
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string, usedAt time.Time) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID int) (int, error)
+	DeleteRecoveryCodes(ctx context.Context, userID int) error
+
+	// Rate limit operations
+	// GetRateLimitBucketForUpdate loads a bucket by key and, inside a
+	// transaction, locks it so concurrent requests take tokens in turn.
+	GetRateLimitBucketForUpdate(ctx context.Context, key string) (*model.RateLimitBucket, error)
+	// SaveRateLimitBucket inserts the bucket or replaces the one with its key.
+	SaveRateLimitBucket(ctx context.Context, bucket *model.RateLimitBucket) error
+	DeleteRateLimitBucketsBefore(ctx context.Context, before time.Time) (int64, error)
 }
 
+// Tx is a Store whose every method executes inside one database transaction.
 type Tx interface {
 	Store
 	Commit() error
 	Rollback() error
 }
 
-// Common transaction interface that all implementations satisfy
-type Transaction interface {
-	Commit() error
-	Rollback() error
-}
+// ErrTxInProgress is returned by Tx.BeginTx; use WithTx to join the running
+// transaction instead.
+var ErrTxInProgress = errors.New("transaction already in progress")
 
+// RunInTx is the shared WithTx implementation for the backends.
+func RunInTx(ctx context.Context, s Store, fn func(Store) error) (err error) {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
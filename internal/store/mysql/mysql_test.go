@@ -0,0 +1,23 @@
+package mysql
+
+import (
+	"os"
+	"testing"
+
+	"github.com/choreme/choreme/internal/store/storetest"
+)
+
+// The tests run against the database CHOREME_TEST_MYSQL_DSN points to,
+// connected to like the server does, e.g.
+// choreme:secret@tcp(localhost:3306)/choreme_test?parseTime=true&loc=UTC&time_zone=%27%2B00%3A00%27
+func openTestDB(t *testing.T) *Store {
+	dsn := os.Getenv("CHOREME_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("CHOREME_TEST_MYSQL_DSN is not set")
+	}
+	return New(storetest.Open(t, "mysql", "mysql", dsn))
+}
+
+func TestTimesInUTC(t *testing.T) {
+	storetest.TestTimesInUTC(t, openTestDB(t))
+}
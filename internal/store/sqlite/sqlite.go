@@ -4,18 +4,27 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
 	"github.com/shopspring/decimal"
+
+	_ "github.com/mattn/go-sqlite3"
 )
 
+func init() {
+	store.Register("sqlite", func(db *sql.DB) store.Store { return New(db) })
+}
+
 type Store struct {
 	db *sql.DB
+	q  store.Querier
 }
 
 func New(db *sql.DB) *Store {
-	return &Store{db: db}
+	return &Store{db: db, q: store.UTC(db)}
 }
 
 func (s *Store) Close() error {
@@ -26,18 +35,41 @@ func (s *Store) Ping() error {
 	return s.db.Ping()
 }
 
-func (s *Store) BeginTx(ctx context.Context) (interface{}, error) {
+func (s *Store) BeginTx(ctx context.Context) (store.Tx, error) {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	return &Tx{tx: tx, store: s}, nil
+	return &Tx{Store: &Store{db: s.db, q: store.UTC(tx)}, tx: tx}, nil
+}
+
+func (s *Store) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return store.RunInTx(ctx, s, fn)
 }
 
 // Household operations
+const householdColumns = `id, name, allow_negative_balance, schedule_days_ahead, created_at,
+	require_two_factor, suspended_at, currency, points_label, decimal_places, timezone, week_start, locale`
+
+func scanHousehold(row scanner) (*model.Household, error) {
+	household := &model.Household{}
+	err := row.Scan(
+		&household.ID, &household.Name, &household.AllowNegativeBalance, &household.ScheduleDaysAhead,
+		&household.CreatedAt, &household.RequireTwoFactor, &household.SuspendedAt, &household.Currency,
+		&household.PointsLabel, &household.DecimalPlaces, &household.Timezone, &household.WeekStart, &household.Locale)
+	if err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
 func (s *Store) CreateHousehold(ctx context.Context, household *model.Household) error {
-	query := `INSERT INTO households (name, invite_code, created_at) VALUES (?, ?, ?)`
-	result, err := s.db.ExecContext(ctx, query, household.Name, household.InviteCode, household.CreatedAt)
+	query := `INSERT INTO households (name, allow_negative_balance, schedule_days_ahead, created_at, require_two_factor,
+			  currency, points_label, decimal_places, timezone, week_start, locale)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query, household.Name, household.AllowNegativeBalance, household.ScheduleDaysAhead, household.CreatedAt,
+		household.RequireTwoFactor, household.Currency, household.PointsLabel, household.DecimalPlaces, household.Timezone,
+		household.WeekStart, household.Locale)
 	if err != nil {
 		return err
 	}
@@ -50,39 +82,87 @@ func (s *Store) CreateHousehold(ctx context.Context, household *model.Household)
 }
 
 func (s *Store) GetHouseholdByID(ctx context.Context, id int) (*model.Household, error) {
-	household := &model.Household{}
-	query := `SELECT id, name, invite_code, created_at FROM households WHERE id = ?`
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&household.ID, &household.Name, &household.InviteCode, &household.CreatedAt)
+	query := `SELECT ` + householdColumns + ` FROM households WHERE id = ?`
+	return scanHousehold(s.q.QueryRowContext(ctx, query, id))
+}
+
+func (s *Store) UpdateHousehold(ctx context.Context, household *model.Household) error {
+	query := `UPDATE households SET name = ?, allow_negative_balance = ?, schedule_days_ahead = ?, require_two_factor = ?,
+			  currency = ?, points_label = ?, decimal_places = ?, timezone = ?, week_start = ?, locale = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, household.Name, household.AllowNegativeBalance, household.ScheduleDaysAhead,
+		household.RequireTwoFactor, household.Currency, household.PointsLabel, household.DecimalPlaces, household.Timezone,
+		household.WeekStart, household.Locale, household.ID)
+	return err
+}
+
+func (s *Store) GetHouseholds(ctx context.Context) ([]*model.HouseholdSummary, error) {
+	query := `SELECT ` + householdColumns + `,
+			  (SELECT COUNT(*) FROM household_members m WHERE m.household_id = h.id AND m.removed_at IS NULL),
+			  (SELECT COUNT(*) FROM chores c WHERE c.household_id = h.id)
+			  FROM households h ORDER BY h.id`
+	rows, err := s.q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	return household, nil
+	defer rows.Close()
+
+	var households []*model.HouseholdSummary
+	for rows.Next() {
+		household := &model.HouseholdSummary{}
+		err := rows.Scan(
+			&household.ID, &household.Name, &household.AllowNegativeBalance, &household.ScheduleDaysAhead,
+			&household.CreatedAt, &household.RequireTwoFactor, &household.SuspendedAt, &household.Currency,
+			&household.PointsLabel, &household.DecimalPlaces, &household.Timezone, &household.WeekStart, &household.Locale,
+			&household.MemberCount, &household.ChoreCount)
+		if err != nil {
+			return nil, err
+		}
+		households = append(households, household)
+	}
+	return households, rows.Err()
 }
 
-func (s *Store) GetHouseholdByInviteCode(ctx context.Context, inviteCode string) (*model.Household, error) {
-	household := &model.Household{}
-	query := `SELECT id, name, invite_code, created_at FROM households WHERE invite_code = ?`
-	err := s.db.QueryRowContext(ctx, query, inviteCode).Scan(
-		&household.ID, &household.Name, &household.InviteCode, &household.CreatedAt)
+func (s *Store) SuspendHousehold(ctx context.Context, id int, suspendedAt *time.Time) error {
+	query := `UPDATE households SET suspended_at = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, suspendedAt, id)
+	return err
+}
+
+// User operations. The role and status come from the membership the user
+// is loaded with.
+const userColumns = `u.id, m.household_id, u.name, u.email, u.password_hash, m.role, u.notification_pref_email, u.notification_pref_push,
+	u.created_at, u.updated_at, u.session_epoch, u.email_verified_at, u.failed_logins, u.locked_until, u.totp_secret, u.totp_enabled_at,
+	u.totp_last_step, u.pin_hash, m.deactivated_at, m.removed_at`
+
+// currentMember joins users to the membership of the household they are in.
+const currentMember = `users u JOIN household_members m ON m.user_id = u.id AND m.household_id = u.household_id`
+
+func scanUser(row scanner) (*model.User, error) {
+	user := &model.User{}
+	var email sql.NullString
+	err := row.Scan(
+		&user.ID, &user.HouseholdID, &user.Name, &email, &user.PasswordHash, &user.Role,
+		&user.NotificationPrefEmail, &user.NotificationPrefPush, &user.CreatedAt, &user.UpdatedAt,
+		&user.SessionEpoch, &user.EmailVerifiedAt, &user.FailedLogins, &user.LockedUntil, &user.TOTPSecret, &user.TOTPEnabledAt,
+		&user.TOTPLastStep, &user.PINHash, &user.DeactivatedAt, &user.RemovedAt)
 	if err != nil {
 		return nil, err
 	}
-	return household, nil
+	user.Email = email.String
+	return user, nil
 }
 
-func (s *Store) UpdateHouseholdInviteCode(ctx context.Context, id int, inviteCode string) error {
-	query := `UPDATE households SET invite_code = ? WHERE id = ?`
-	_, err := s.db.ExecContext(ctx, query, inviteCode, id)
-	return err
+// nullString stores an empty string as NULL, e.g. the email address of a
+// profile without one.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
 }
 
-// User operations
 func (s *Store) CreateUser(ctx context.Context, user *model.User) error {
 	query := `INSERT INTO users (household_id, name, email, password_hash, role, notification_pref_email, notification_pref_push, created_at, updated_at) 
 			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	result, err := s.db.ExecContext(ctx, query,
-		user.HouseholdID, user.Name, user.Email, user.PasswordHash, user.Role,
+	result, err := s.q.ExecContext(ctx, query,
+		user.HouseholdID, user.Name, nullString(user.Email), user.PasswordHash, user.Role,
 		user.NotificationPrefEmail, user.NotificationPrefPush, user.CreatedAt, user.UpdatedAt)
 	if err != nil {
 		return err
@@ -92,39 +172,44 @@ func (s *Store) CreateUser(ctx context.Context, user *model.User) error {
 		return err
 	}
 	user.ID = int(id)
-	return nil
+
+	query = `INSERT INTO household_members (household_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`
+	_, err = s.q.ExecContext(ctx, query, user.HouseholdID, user.ID, user.Role, user.CreatedAt)
+	return err
 }
 
 func (s *Store) GetUserByID(ctx context.Context, id int) (*model.User, error) {
-	user := &model.User{}
-	query := `SELECT id, household_id, name, email, password_hash, role, notification_pref_email, notification_pref_push, created_at, updated_at 
-			  FROM users WHERE id = ?`
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID, &user.HouseholdID, &user.Name, &user.Email, &user.PasswordHash, &user.Role,
-		&user.NotificationPrefEmail, &user.NotificationPrefPush, &user.CreatedAt, &user.UpdatedAt)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	query := `SELECT ` + userColumns + ` FROM ` + currentMember + ` WHERE u.id = ?`
+	return scanUser(s.q.QueryRowContext(ctx, query, id))
+}
+
+// GetUserForUpdate loads a user for a read-modify-write. SQLite transactions
+// take the write lock at BEGIN, so no row lock is needed.
+func (s *Store) GetUserForUpdate(ctx context.Context, id int) (*model.User, error) {
+	return s.GetUserByID(ctx, id)
 }
 
 func (s *Store) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	user := &model.User{}
-	query := `SELECT id, household_id, name, email, password_hash, role, notification_pref_email, notification_pref_push, created_at, updated_at 
-			  FROM users WHERE email = ?`
-	err := s.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID, &user.HouseholdID, &user.Name, &user.Email, &user.PasswordHash, &user.Role,
-		&user.NotificationPrefEmail, &user.NotificationPrefPush, &user.CreatedAt, &user.UpdatedAt)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	query := `SELECT ` + userColumns + ` FROM ` + currentMember + ` WHERE u.email = ?`
+	return scanUser(s.q.QueryRowContext(ctx, query, email))
+}
+
+func (s *Store) GetMember(ctx context.Context, householdID, userID int) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users u JOIN household_members m ON m.user_id = u.id
+			  WHERE m.household_id = ? AND u.id = ?`
+	return scanUser(s.q.QueryRowContext(ctx, query, householdID, userID))
+}
+
+// GetMemberForUpdate loads a member for a read-modify-write. SQLite
+// transactions take the write lock at BEGIN, so no row lock is needed.
+func (s *Store) GetMemberForUpdate(ctx context.Context, householdID, userID int) (*model.User, error) {
+	return s.GetMember(ctx, householdID, userID)
 }
 
 func (s *Store) GetUsersByHousehold(ctx context.Context, householdID int) ([]*model.User, error) {
-	query := `SELECT id, household_id, name, email, password_hash, role, notification_pref_email, notification_pref_push, created_at, updated_at 
-			  FROM users WHERE household_id = ?`
-	rows, err := s.db.QueryContext(ctx, query, householdID)
+	query := `SELECT ` + userColumns + ` FROM users u JOIN household_members m ON m.user_id = u.id
+			  WHERE m.household_id = ? AND m.removed_at IS NULL`
+	rows, err := s.q.QueryContext(ctx, query, householdID)
 	if err != nil {
 		return nil, err
 	}
@@ -132,211 +217,1386 @@ func (s *Store) GetUsersByHousehold(ctx context.Context, householdID int) ([]*mo
 
 	var users []*model.User
 	for rows.Next() {
-		user := &model.User{}
-		err := rows.Scan(
-			&user.ID, &user.HouseholdID, &user.Name, &user.Email, &user.PasswordHash, &user.Role,
-			&user.NotificationPrefEmail, &user.NotificationPrefPush, &user.CreatedAt, &user.UpdatedAt)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, err
 		}
 		users = append(users, user)
 	}
-	return users, nil
+	return users, rows.Err()
 }
 
+// UpdateUser writes a user back. Changing the role, email or password bumps
+// the session epoch, which invalidates the user's outstanding access tokens.
+// The epoch is computed first, from the old values.
 func (s *Store) UpdateUser(ctx context.Context, user *model.User) error {
-	query := `UPDATE users SET name = ?, email = ?, notification_pref_email = ?, notification_pref_push = ?, updated_at = ? WHERE id = ?`
-	_, err := s.db.ExecContext(ctx, query, user.Name, user.Email, user.NotificationPrefEmail, user.NotificationPrefPush, time.Now(), user.ID)
+	query := `UPDATE users SET
+			  session_epoch = session_epoch + CASE WHEN COALESCE(email, '') <> ? OR password_hash <> ?
+			  OR (SELECT role FROM household_members WHERE household_id = ? AND user_id = users.id) <> ?
+			  THEN 1 ELSE 0 END,
+			  name = ?, email = ?, password_hash = ?, notification_pref_email = ?,
+			  notification_pref_push = ?, email_verified_at = ?, updated_at = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query,
+		user.Email, user.PasswordHash, user.HouseholdID, user.Role,
+		user.Name, nullString(user.Email), user.PasswordHash, user.NotificationPrefEmail,
+		user.NotificationPrefPush, user.EmailVerifiedAt, time.Now(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	query = `UPDATE household_members SET role = ? WHERE household_id = ? AND user_id = ?`
+	_, err = s.q.ExecContext(ctx, query, user.Role, user.HouseholdID, user.ID)
 	return err
 }
 
-func (s *Store) DeleteUser(ctx context.Context, id int) error {
-	query := `DELETE FROM users WHERE id = ?`
-	_, err := s.db.ExecContext(ctx, query, id)
+func (s *Store) SetCurrentHousehold(ctx context.Context, userID, householdID int) error {
+	query := `UPDATE users SET household_id = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, householdID, userID)
 	return err
 }
 
-// Stub implementations for other methods (to be implemented)
-func (s *Store) CreateChore(ctx context.Context, chore *model.Chore) error {
-	return nil // TODO: Implement
+func (s *Store) BumpSessionEpoch(ctx context.Context, userID int) error {
+	query := `UPDATE users SET session_epoch = session_epoch + 1 WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, userID)
+	return err
 }
 
-func (s *Store) GetChoreByID(ctx context.Context, id int) (*model.Chore, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) UpdateLoginFailures(ctx context.Context, userID int, failedLogins int, lockedUntil *time.Time) error {
+	query := `UPDATE users SET failed_logins = ?, locked_until = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, failedLogins, lockedUntil, userID)
+	return err
 }
 
-func (s *Store) GetChoresByHousehold(ctx context.Context, householdID int, filters model.ChoreFilters) ([]*model.Chore, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) UpdateUserTwoFactor(ctx context.Context, user *model.User) error {
+	query := `UPDATE users SET totp_secret = ?, totp_enabled_at = ?, totp_last_step = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, user.TOTPSecret, user.TOTPEnabledAt, user.TOTPLastStep, user.ID)
+	return err
 }
 
-func (s *Store) UpdateChore(ctx context.Context, chore *model.Chore) error {
-	return nil // TODO: Implement
+func (s *Store) UpdateUserPIN(ctx context.Context, userID int, pinHash *string) error {
+	query := `UPDATE users SET pin_hash = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, pinHash, userID)
+	return err
 }
 
-func (s *Store) DeleteChore(ctx context.Context, id int) error {
-	return nil // TODO: Implement
+func (s *Store) UpdateUserStatus(ctx context.Context, user *model.User) error {
+	query := `UPDATE household_members SET deactivated_at = ?, removed_at = ? WHERE household_id = ? AND user_id = ?`
+	_, err := s.q.ExecContext(ctx, query, user.DeactivatedAt, user.RemovedAt, user.HouseholdID, user.ID)
+	return err
 }
 
-func (s *Store) CreateAssignment(ctx context.Context, assignment *model.Assignment) error {
-	return nil // TODO: Implement
+func (s *Store) DeleteUser(ctx context.Context, id int) error {
+	query := `DELETE FROM users WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, id)
+	return err
 }
 
-func (s *Store) GetAssignmentByID(ctx context.Context, id int) (*model.Assignment, error) {
-	return nil, nil // TODO: Implement
+// Membership operations
+func (s *Store) GetMemberships(ctx context.Context, userID int) ([]*model.Membership, error) {
+	query := `SELECT m.household_id, m.user_id, h.name, m.role, m.deactivated_at, m.created_at, h.suspended_at,
+			  m.household_id = u.household_id
+			  FROM household_members m JOIN households h ON h.id = m.household_id JOIN users u ON u.id = m.user_id
+			  WHERE m.user_id = ? AND m.removed_at IS NULL ORDER BY m.created_at, m.household_id`
+	rows, err := s.q.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memberships []*model.Membership
+	for rows.Next() {
+		membership := &model.Membership{}
+		err := rows.Scan(&membership.HouseholdID, &membership.UserID, &membership.HouseholdName, &membership.Role,
+			&membership.DeactivatedAt, &membership.CreatedAt, &membership.HouseholdSuspendedAt, &membership.Current)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, membership)
+	}
+	return memberships, rows.Err()
 }
 
-func (s *Store) GetAssignmentsByUser(ctx context.Context, userID int, filters model.AssignmentFilters) ([]*model.Assignment, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) SaveMembership(ctx context.Context, membership *model.Membership) error {
+	query := `INSERT INTO household_members (household_id, user_id, role, created_at) VALUES (?, ?, ?, ?)
+			  ON CONFLICT (household_id, user_id) DO UPDATE SET
+			  role = excluded.role, deactivated_at = NULL, removed_at = NULL, created_at = excluded.created_at`
+	_, err := s.q.ExecContext(ctx, query, membership.HouseholdID, membership.UserID, membership.Role, membership.CreatedAt)
+	return err
 }
 
-func (s *Store) GetAssignmentsByHousehold(ctx context.Context, householdID int, filters model.AssignmentFilters) ([]*model.Assignment, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) CountSystemAdmins(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM household_members WHERE role = ? AND removed_at IS NULL`
+	var count int
+	err := s.q.QueryRowContext(ctx, query, model.RoleSystemAdmin).Scan(&count)
+	return count, err
 }
 
-func (s *Store) UpdateAssignment(ctx context.Context, assignment *model.Assignment) error {
-	return nil // TODO: Implement
+// Chore operations
+const choreColumns = `c.id, c.household_id, c.title, c.description, c.value, c.frequency, c.category, c.priority,
+	c.auto_approve, c.proof_required, c.late_penalty_pct, c.expire_days, c.created_by, c.created_at, c.updated_at,
+	c.schedule_start, c.scheduled_until`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
 }
 
-func (s *Store) DeleteAssignment(ctx context.Context, id int) error {
-	return nil // TODO: Implement
+func scanChore(row scanner, extra ...interface{}) (*model.Chore, error) {
+	chore := &model.Chore{}
+	dest := []interface{}{
+		&chore.ID, &chore.HouseholdID, &chore.Title, &chore.Description, &chore.Value, &chore.Frequency,
+		&chore.Category, &chore.Priority, &chore.AutoApprove, &chore.ProofRequired, &chore.LatePenaltyPct,
+		&chore.ExpireDays, &chore.CreatedBy, &chore.CreatedAt, &chore.UpdatedAt,
+		&chore.ScheduleStart, &chore.ScheduledUntil,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return chore, nil
 }
 
-func (s *Store) GetOverdueAssignments(ctx context.Context) ([]*model.Assignment, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) CreateChore(ctx context.Context, chore *model.Chore) error {
+	query := `INSERT INTO chores (household_id, title, description, value, frequency, category, priority, auto_approve,
+			  proof_required, late_penalty_pct, expire_days, created_by, created_at, updated_at, schedule_start, scheduled_until)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query,
+		chore.HouseholdID, chore.Title, chore.Description, chore.Value, chore.Frequency, chore.Category,
+		chore.Priority, chore.AutoApprove, chore.ProofRequired, chore.LatePenaltyPct, chore.ExpireDays,
+		chore.CreatedBy, chore.CreatedAt, chore.UpdatedAt, chore.ScheduleStart, chore.ScheduledUntil)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	chore.ID = int(id)
+	return nil
 }
 
-func (s *Store) CreateReward(ctx context.Context, reward *model.Reward) error {
-	return nil // TODO: Implement
+func (s *Store) GetChoreByID(ctx context.Context, id int) (*model.Chore, error) {
+	query := `SELECT ` + choreColumns + ` FROM chores c WHERE c.id = ?`
+	return scanChore(s.q.QueryRowContext(ctx, query, id))
 }
 
-func (s *Store) GetRewardByID(ctx context.Context, id int) (*model.Reward, error) {
-	return nil, nil // TODO: Implement
+// GetChoreForUpdate loads a chore for a read-modify-write. SQLite
+// transactions take the write lock at BEGIN, so no row lock is needed.
+func (s *Store) GetChoreForUpdate(ctx context.Context, id int) (*model.Chore, error) {
+	return s.GetChoreByID(ctx, id)
 }
 
-func (s *Store) GetRewardsByHousehold(ctx context.Context, householdID int) ([]*model.Reward, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) GetRecurringChores(ctx context.Context) ([]*model.Chore, error) {
+	query := `SELECT ` + choreColumns + ` FROM chores c
+			  WHERE c.frequency IS NOT NULL AND c.frequency <> '' AND c.schedule_start IS NOT NULL ORDER BY c.id`
+	rows, err := s.q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chores []*model.Chore
+	for rows.Next() {
+		chore, err := scanChore(rows)
+		if err != nil {
+			return nil, err
+		}
+		chores = append(chores, chore)
+	}
+	return chores, rows.Err()
 }
 
-func (s *Store) UpdateReward(ctx context.Context, reward *model.Reward) error {
-	return nil // TODO: Implement
+func (s *Store) UpdateChoreSchedule(ctx context.Context, id int, scheduledUntil time.Time) error {
+	query := `UPDATE chores SET scheduled_until = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, scheduledUntil, id)
+	return err
 }
 
-func (s *Store) DeleteReward(ctx context.Context, id int) error {
-	return nil // TODO: Implement
+func (s *Store) GetChoreAssignees(ctx context.Context, choreID int) ([]int, error) {
+	query := `SELECT DISTINCT assigned_to FROM assignments WHERE chore_id = ? ORDER BY assigned_to`
+	rows, err := s.q.QueryContext(ctx, query, choreID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
 }
 
-func (s *Store) CreateRedemption(ctx context.Context, redemption *model.Redemption) error {
-	return nil // TODO: Implement
+func (s *Store) GetChoresByHousehold(ctx context.Context, householdID int, filters model.ChoreFilters) ([]*model.Chore, error) {
+	query := `SELECT ` + choreColumns + ` FROM chores c WHERE c.household_id = ?`
+	args := []interface{}{householdID}
+
+	if filters.Category != nil {
+		query += ` AND c.category = ?`
+		args = append(args, *filters.Category)
+	}
+	if filters.Priority != nil {
+		query += ` AND c.priority = ?`
+		args = append(args, *filters.Priority)
+	}
+	if filters.CreatedBy != nil {
+		query += ` AND c.created_by = ?`
+		args = append(args, *filters.CreatedBy)
+	}
+
+	// Assignment-based filters match chores with at least one qualifying assignment
+	if filters.Status != nil || filters.AssignedTo != nil || filters.DateFrom != nil || filters.DateTo != nil {
+		query += ` AND EXISTS (SELECT 1 FROM assignments a WHERE a.chore_id = c.id`
+		if filters.Status != nil {
+			query += ` AND a.status = ?`
+			args = append(args, *filters.Status)
+		}
+		if filters.AssignedTo != nil {
+			query += ` AND a.assigned_to = ?`
+			args = append(args, *filters.AssignedTo)
+		}
+		if filters.DateFrom != nil {
+			query += ` AND a.due_date >= ?`
+			args = append(args, *filters.DateFrom)
+		}
+		if filters.DateTo != nil {
+			query += ` AND a.due_date <= ?`
+			args = append(args, *filters.DateTo)
+		}
+		query += `)`
+	}
+
+	query += ` ORDER BY c.created_at DESC, c.id DESC`
+	if filters.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filters.Limit, filters.Offset)
+	}
+
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chores []*model.Chore
+	for rows.Next() {
+		chore, err := scanChore(rows)
+		if err != nil {
+			return nil, err
+		}
+		chores = append(chores, chore)
+	}
+	return chores, rows.Err()
 }
 
-func (s *Store) GetRedemptionByID(ctx context.Context, id int) (*model.Redemption, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) UpdateChore(ctx context.Context, chore *model.Chore) error {
+	query := `UPDATE chores SET title = ?, description = ?, value = ?, frequency = ?, category = ?, priority = ?,
+			  auto_approve = ?, proof_required = ?, late_penalty_pct = ?, expire_days = ?, schedule_start = ?,
+			  scheduled_until = ?, updated_at = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query,
+		chore.Title, chore.Description, chore.Value, chore.Frequency, chore.Category, chore.Priority,
+		chore.AutoApprove, chore.ProofRequired, chore.LatePenaltyPct, chore.ExpireDays, chore.ScheduleStart,
+		chore.ScheduledUntil, time.Now(), chore.ID)
+	return err
 }
 
-func (s *Store) GetRedemptionsByUser(ctx context.Context, userID int) ([]*model.Redemption, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) DeleteChore(ctx context.Context, id int) error {
+	// SQLite connections don't enforce foreign keys, so apply the schema's
+	// ON DELETE actions by hand: keep ledger history, drop the assignments.
+	if _, err := s.q.ExecContext(ctx,
+		`UPDATE ledger SET chore_assignment_id = NULL WHERE chore_assignment_id IN (SELECT id FROM assignments WHERE chore_id = ?)`, id); err != nil {
+		return err
+	}
+	if _, err := s.q.ExecContext(ctx, `DELETE FROM assignments WHERE chore_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := s.q.ExecContext(ctx, `DELETE FROM chores WHERE id = ?`, id)
+	return err
 }
 
-func (s *Store) GetRedemptionsByHousehold(ctx context.Context, householdID int) ([]*model.Redemption, error) {
-	return nil, nil // TODO: Implement
+// Assignment operations
+const assignmentColumns = `a.id, a.chore_id, a.assigned_to, a.due_date, a.percent_complete, a.status, a.approval_notes,
+	a.completed_at, a.approved_at, a.created_at, a.updated_at, ` + choreColumns
+
+func scanAssignment(row scanner, extra ...interface{}) (*model.Assignment, error) {
+	assignment := &model.Assignment{}
+	chore := &model.Chore{}
+	dest := []interface{}{
+		&assignment.ID, &assignment.ChoreID, &assignment.AssignedTo, &assignment.DueDate, &assignment.PercentComplete,
+		&assignment.Status, &assignment.ApprovalNotes, &assignment.CompletedAt, &assignment.ApprovedAt,
+		&assignment.CreatedAt, &assignment.UpdatedAt,
+		&chore.ID, &chore.HouseholdID, &chore.Title, &chore.Description, &chore.Value, &chore.Frequency,
+		&chore.Category, &chore.Priority, &chore.AutoApprove, &chore.ProofRequired, &chore.LatePenaltyPct,
+		&chore.ExpireDays, &chore.CreatedBy, &chore.CreatedAt, &chore.UpdatedAt,
+		&chore.ScheduleStart, &chore.ScheduledUntil,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	assignment.Chore = chore
+	return assignment, nil
 }
 
-func (s *Store) UpdateRedemption(ctx context.Context, redemption *model.Redemption) error {
-	return nil // TODO: Implement
+func (s *Store) queryAssignments(ctx context.Context, query string, args []interface{}, filters model.AssignmentFilters) ([]*model.Assignment, error) {
+	if filters.Status != nil {
+		query += ` AND a.status = ?`
+		args = append(args, *filters.Status)
+	}
+	if filters.ChoreID != nil {
+		query += ` AND a.chore_id = ?`
+		args = append(args, *filters.ChoreID)
+	}
+	if filters.DueBefore != nil {
+		query += ` AND a.due_date <= ?`
+		args = append(args, *filters.DueBefore)
+	}
+	if filters.DueAfter != nil {
+		query += ` AND a.due_date >= ?`
+		args = append(args, *filters.DueAfter)
+	}
+	if filters.Completed != nil {
+		if *filters.Completed {
+			query += ` AND a.completed_at IS NOT NULL`
+		} else {
+			query += ` AND a.completed_at IS NULL`
+		}
+	}
+	if filters.Approved != nil {
+		if *filters.Approved {
+			query += ` AND a.approved_at IS NOT NULL`
+		} else {
+			query += ` AND a.approved_at IS NULL`
+		}
+	}
+
+	query += ` ORDER BY a.due_date ASC, a.id ASC`
+	if filters.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filters.Limit, filters.Offset)
+	}
+
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assignments []*model.Assignment
+	for rows.Next() {
+		assignment, err := scanAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
 }
 
-func (s *Store) CreateLedgerEntry(ctx context.Context, entry *model.LedgerEntry) error {
-	return nil // TODO: Implement
+func (s *Store) CreateAssignment(ctx context.Context, assignment *model.Assignment) error {
+	query := `INSERT INTO assignments (chore_id, assigned_to, due_date, percent_complete, status, proof_image,
+			  proof_image_type, approval_notes, completed_at, approved_at, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query,
+		assignment.ChoreID, assignment.AssignedTo, assignment.DueDate, assignment.PercentComplete, assignment.Status,
+		assignment.ProofImage, assignment.ProofImageType, assignment.ApprovalNotes, assignment.CompletedAt,
+		assignment.ApprovedAt, assignment.CreatedAt, assignment.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	assignment.ID = int(id)
+	return nil
 }
 
-func (s *Store) GetLedgerEntriesByUser(ctx context.Context, userID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) GetAssignmentByID(ctx context.Context, id int) (*model.Assignment, error) {
+	var proofImage []byte
+	var proofImageType *string
+	query := `SELECT ` + assignmentColumns + `, a.proof_image, a.proof_image_type
+			  FROM assignments a JOIN chores c ON c.id = a.chore_id WHERE a.id = ?`
+	assignment, err := scanAssignment(s.q.QueryRowContext(ctx, query, id), &proofImage, &proofImageType)
+	if err != nil {
+		return nil, err
+	}
+	assignment.ProofImage, assignment.ProofImageType = proofImage, proofImageType
+	return assignment, nil
 }
 
-func (s *Store) GetLedgerEntriesByHousehold(ctx context.Context, householdID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) GetAssignmentsByUser(ctx context.Context, householdID, userID int, filters model.AssignmentFilters) ([]*model.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM assignments a JOIN chores c ON c.id = a.chore_id
+			  WHERE c.household_id = ? AND a.assigned_to = ?`
+	return s.queryAssignments(ctx, query, []interface{}{householdID, userID}, filters)
 }
 
-func (s *Store) GetUserBalance(ctx context.Context, userID int) (decimal.Decimal, error) {
-	return decimal.Zero, nil // TODO: Implement
+func (s *Store) GetAssignmentsByHousehold(ctx context.Context, householdID int, filters model.AssignmentFilters) ([]*model.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM assignments a JOIN chores c ON c.id = a.chore_id WHERE c.household_id = ?`
+	return s.queryAssignments(ctx, query, []interface{}{householdID}, filters)
 }
 
-func (s *Store) GetAllUserBalances(ctx context.Context, householdID int) ([]*model.UserBalance, error) {
-	return nil, nil // TODO: Implement
+// GetAssignmentForUpdate loads an assignment for a read-modify-write. SQLite
+// transactions take the write lock at BEGIN, so no row lock is needed.
+func (s *Store) GetAssignmentForUpdate(ctx context.Context, id int) (*model.Assignment, error) {
+	var proofImage []byte
+	var proofImageType *string
+	query := `SELECT ` + assignmentColumns + `, a.proof_image, a.proof_image_type
+			  FROM assignments a JOIN chores c ON c.id = a.chore_id WHERE a.id = ?`
+	assignment, err := scanAssignment(s.q.QueryRowContext(ctx, query, id), &proofImage, &proofImageType)
+	if err != nil {
+		return nil, err
+	}
+	assignment.ProofImage, assignment.ProofImageType = proofImage, proofImageType
+	return assignment, nil
 }
 
-func (s *Store) CreateAuditLog(ctx context.Context, log *model.AuditLog) error {
-	detailsJSON, _ := json.Marshal(log.Details)
-	query := `INSERT INTO audit_logs (household_id, user_id, action, details, created_at) VALUES (?, ?, ?, ?, ?)`
-	_, err := s.db.ExecContext(ctx, query, log.HouseholdID, log.UserID, log.Action, string(detailsJSON), log.CreatedAt)
+func (s *Store) UpdateAssignment(ctx context.Context, assignment *model.Assignment) error {
+	query := `UPDATE assignments SET assigned_to = ?, due_date = ?, percent_complete = ?, status = ?, proof_image = ?,
+			  proof_image_type = ?, approval_notes = ?, completed_at = ?, approved_at = ?, updated_at = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query,
+		assignment.AssignedTo, assignment.DueDate, assignment.PercentComplete, assignment.Status, assignment.ProofImage,
+		assignment.ProofImageType, assignment.ApprovalNotes, assignment.CompletedAt, assignment.ApprovedAt, time.Now(),
+		assignment.ID)
 	return err
 }
 
-func (s *Store) GetAuditLogsByHousehold(ctx context.Context, householdID int, filters model.AuditFilters) ([]*model.AuditLog, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) DeleteAssignment(ctx context.Context, id int) error {
+	// Foreign keys aren't enforced on SQLite connections; keep ledger history
+	if _, err := s.q.ExecContext(ctx, `UPDATE ledger SET chore_assignment_id = NULL WHERE chore_assignment_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := s.q.ExecContext(ctx, `DELETE FROM assignments WHERE id = ?`, id)
+	return err
 }
 
-func (s *Store) GetAuditLogsByUser(ctx context.Context, userID int, filters model.AuditFilters) ([]*model.AuditLog, error) {
-	return nil, nil // TODO: Implement
+func (s *Store) GetOverdueAssignments(ctx context.Context, now time.Time) ([]*model.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM assignments a JOIN chores c ON c.id = a.chore_id
+			  WHERE a.due_date < ? AND (a.status IN ('pending', 'in_progress')
+			  OR (a.status = 'late' AND c.expire_days IS NOT NULL))`
+	return s.queryAssignments(ctx, query, []interface{}{now}, model.AssignmentFilters{})
 }
 
-// Transaction wrapper
-type Tx struct {
-	tx    *sql.Tx
-	store *Store
+// Reward operations
+const rewardColumns = `rw.id, rw.household_id, rw.title, rw.description, rw.cost, rw.is_active, rw.created_at`
+
+func scanReward(row scanner) (*model.Reward, error) {
+	reward := &model.Reward{}
+	err := row.Scan(&reward.ID, &reward.HouseholdID, &reward.Title, &reward.Description, &reward.Cost,
+		&reward.IsActive, &reward.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return reward, nil
 }
 
-func (t *Tx) Commit() error {
-	return t.tx.Commit()
+func (s *Store) CreateReward(ctx context.Context, reward *model.Reward) error {
+	query := `INSERT INTO rewards (household_id, title, description, cost, is_active, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query,
+		reward.HouseholdID, reward.Title, reward.Description, reward.Cost, reward.IsActive, reward.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	reward.ID = int(id)
+	return nil
 }
 
-func (t *Tx) Rollback() error {
-	return t.tx.Rollback()
+func (s *Store) GetRewardByID(ctx context.Context, id int) (*model.Reward, error) {
+	query := `SELECT ` + rewardColumns + ` FROM rewards rw WHERE rw.id = ?`
+	return scanReward(s.q.QueryRowContext(ctx, query, id))
+}
+
+func (s *Store) GetRewardsByHousehold(ctx context.Context, householdID int) ([]*model.Reward, error) {
+	query := `SELECT ` + rewardColumns + ` FROM rewards rw WHERE rw.household_id = ? ORDER BY rw.cost ASC, rw.id ASC`
+	rows, err := s.q.QueryContext(ctx, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rewards []*model.Reward
+	for rows.Next() {
+		reward, err := scanReward(rows)
+		if err != nil {
+			return nil, err
+		}
+		rewards = append(rewards, reward)
+	}
+	return rewards, rows.Err()
 }
 
-// Implement all store methods for transaction - for now, delegate to main store
-func (t *Tx) Close() error                                                                       { return nil }
-func (t *Tx) Ping() error                                                                        { return nil }
-func (t *Tx) BeginTx(ctx context.Context) (interface{}, error)                                     { return t, nil }
-func (t *Tx) CreateHousehold(ctx context.Context, household *model.Household) error             { return t.store.CreateHousehold(ctx, household) }
-func (t *Tx) GetHouseholdByID(ctx context.Context, id int) (*model.Household, error)            { return t.store.GetHouseholdByID(ctx, id) }
-func (t *Tx) GetHouseholdByInviteCode(ctx context.Context, inviteCode string) (*model.Household, error) { return t.store.GetHouseholdByInviteCode(ctx, inviteCode) }
-func (t *Tx) UpdateHouseholdInviteCode(ctx context.Context, id int, inviteCode string) error    { return t.store.UpdateHouseholdInviteCode(ctx, id, inviteCode) }
-func (t *Tx) CreateUser(ctx context.Context, user *model.User) error                            { return t.store.CreateUser(ctx, user) }
-func (t *Tx) GetUserByID(ctx context.Context, id int) (*model.User, error)                      { return t.store.GetUserByID(ctx, id) }
-func (t *Tx) GetUserByEmail(ctx context.Context, email string) (*model.User, error)            { return t.store.GetUserByEmail(ctx, email) }
-func (t *Tx) GetUsersByHousehold(ctx context.Context, householdID int) ([]*model.User, error)  { return t.store.GetUsersByHousehold(ctx, householdID) }
-func (t *Tx) UpdateUser(ctx context.Context, user *model.User) error                           { return t.store.UpdateUser(ctx, user) }
-func (t *Tx) DeleteUser(ctx context.Context, id int) error                                     { return t.store.DeleteUser(ctx, id) }
-
-// Stub implementations for transaction methods
-func (t *Tx) CreateChore(ctx context.Context, chore *model.Chore) error                                                                      { return t.store.CreateChore(ctx, chore) }
-func (t *Tx) GetChoreByID(ctx context.Context, id int) (*model.Chore, error)                                                                 { return t.store.GetChoreByID(ctx, id) }
-func (t *Tx) GetChoresByHousehold(ctx context.Context, householdID int, filters model.ChoreFilters) ([]*model.Chore, error)               { return t.store.GetChoresByHousehold(ctx, householdID, filters) }
-func (t *Tx) UpdateChore(ctx context.Context, chore *model.Chore) error                                                                     { return t.store.UpdateChore(ctx, chore) }
-func (t *Tx) DeleteChore(ctx context.Context, id int) error                                                                                 { return t.store.DeleteChore(ctx, id) }
-func (t *Tx) CreateAssignment(ctx context.Context, assignment *model.Assignment) error                                                      { return t.store.CreateAssignment(ctx, assignment) }
-func (t *Tx) GetAssignmentByID(ctx context.Context, id int) (*model.Assignment, error)                                                      { return t.store.GetAssignmentByID(ctx, id) }
-func (t *Tx) GetAssignmentsByUser(ctx context.Context, userID int, filters model.AssignmentFilters) ([]*model.Assignment, error)         { return t.store.GetAssignmentsByUser(ctx, userID, filters) }
-func (t *Tx) GetAssignmentsByHousehold(ctx context.Context, householdID int, filters model.AssignmentFilters) ([]*model.Assignment, error) { return t.store.GetAssignmentsByHousehold(ctx, householdID, filters) }
-func (t *Tx) UpdateAssignment(ctx context.Context, assignment *model.Assignment) error                                                      { return t.store.UpdateAssignment(ctx, assignment) }
-func (t *Tx) DeleteAssignment(ctx context.Context, id int) error                                                                            { return t.store.DeleteAssignment(ctx, id) }
-func (t *Tx) GetOverdueAssignments(ctx context.Context) ([]*model.Assignment, error)                                                       { return t.store.GetOverdueAssignments(ctx) }
-func (t *Tx) CreateReward(ctx context.Context, reward *model.Reward) error                                                                  { return t.store.CreateReward(ctx, reward) }
-func (t *Tx) GetRewardByID(ctx context.Context, id int) (*model.Reward, error)                                                              { return t.store.GetRewardByID(ctx, id) }
-func (t *Tx) GetRewardsByHousehold(ctx context.Context, householdID int) ([]*model.Reward, error)                                          { return t.store.GetRewardsByHousehold(ctx, householdID) }
-func (t *Tx) UpdateReward(ctx context.Context, reward *model.Reward) error                                                                  { return t.store.UpdateReward(ctx, reward) }
-func (t *Tx) DeleteReward(ctx context.Context, id int) error                                                                                { return t.store.DeleteReward(ctx, id) }
-func (t *Tx) CreateRedemption(ctx context.Context, redemption *model.Redemption) error                                                     { return t.store.CreateRedemption(ctx, redemption) }
-func (t *Tx) GetRedemptionByID(ctx context.Context, id int) (*model.Redemption, error)                                                     { return t.store.GetRedemptionByID(ctx, id) }
-func (t *Tx) GetRedemptionsByUser(ctx context.Context, userID int) ([]*model.Redemption, error)                                           { return t.store.GetRedemptionsByUser(ctx, userID) }
-func (t *Tx) GetRedemptionsByHousehold(ctx context.Context, householdID int) ([]*model.Redemption, error)                                 { return t.store.GetRedemptionsByHousehold(ctx, householdID) }
-func (t *Tx) UpdateRedemption(ctx context.Context, redemption *model.Redemption) error                                                     { return t.store.UpdateRedemption(ctx, redemption) }
-func (t *Tx) CreateLedgerEntry(ctx context.Context, entry *model.LedgerEntry) error                                                        { return t.store.CreateLedgerEntry(ctx, entry) }
-func (t *Tx) GetLedgerEntriesByUser(ctx context.Context, userID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error)          { return t.store.GetLedgerEntriesByUser(ctx, userID, filters) }
-func (t *Tx) GetLedgerEntriesByHousehold(ctx context.Context, householdID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error) { return t.store.GetLedgerEntriesByHousehold(ctx, householdID, filters) }
-func (t *Tx) GetUserBalance(ctx context.Context, userID int) (decimal.Decimal, error)                                                      { return t.store.GetUserBalance(ctx, userID) }
-func (t *Tx) GetAllUserBalances(ctx context.Context, householdID int) ([]*model.UserBalance, error)                                       { return t.store.GetAllUserBalances(ctx, householdID) }
-func (t *Tx) CreateAuditLog(ctx context.Context, log *model.AuditLog) error                                                                 { return t.store.CreateAuditLog(ctx, log) }
-func (t *Tx) GetAuditLogsByHousehold(ctx context.Context, householdID int, filters model.AuditFilters) ([]*model.AuditLog, error)         { return t.store.GetAuditLogsByHousehold(ctx, householdID, filters) }
-func (t *Tx) GetAuditLogsByUser(ctx context.Context, userID int, filters model.AuditFilters) ([]*model.AuditLog, error)                   { return t.store.GetAuditLogsByUser(ctx, userID, filters) }
\ No newline at end of file
+func (s *Store) UpdateReward(ctx context.Context, reward *model.Reward) error {
+	query := `UPDATE rewards SET title = ?, description = ?, cost = ?, is_active = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, reward.Title, reward.Description, reward.Cost, reward.IsActive, reward.ID)
+	return err
+}
+
+func (s *Store) DeleteReward(ctx context.Context, id int) error {
+	// Foreign keys aren't enforced on SQLite connections; apply the schema's
+	// ON DELETE actions by hand
+	if _, err := s.q.ExecContext(ctx, `UPDATE ledger SET redemption_id = NULL
+		WHERE redemption_id IN (SELECT id FROM redemptions WHERE reward_id = ?)`, id); err != nil {
+		return err
+	}
+	if _, err := s.q.ExecContext(ctx, `DELETE FROM redemptions WHERE reward_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := s.q.ExecContext(ctx, `DELETE FROM rewards WHERE id = ?`, id)
+	return err
+}
+
+// Redemption operations
+const redemptionColumns = `r.id, r.reward_id, r.user_id, r.status, r.cost, r.redeemed_at, r.approved_at, ` + rewardColumns
+
+func scanRedemption(row scanner) (*model.Redemption, error) {
+	redemption := &model.Redemption{}
+	reward := &model.Reward{}
+	err := row.Scan(&redemption.ID, &redemption.RewardID, &redemption.UserID, &redemption.Status, &redemption.Cost,
+		&redemption.RedeemedAt, &redemption.ApprovedAt,
+		&reward.ID, &reward.HouseholdID, &reward.Title, &reward.Description, &reward.Cost,
+		&reward.IsActive, &reward.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	redemption.Reward = reward
+	return redemption, nil
+}
+
+func (s *Store) queryRedemptions(ctx context.Context, query string, args []interface{}, filters model.RedemptionFilters) ([]*model.Redemption, error) {
+	if filters.Status != nil {
+		query += ` AND r.status = ?`
+		args = append(args, *filters.Status)
+	}
+	if filters.RewardID != nil {
+		query += ` AND r.reward_id = ?`
+		args = append(args, *filters.RewardID)
+	}
+
+	query += ` ORDER BY r.redeemed_at DESC, r.id DESC`
+	if filters.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filters.Limit, filters.Offset)
+	}
+
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redemptions []*model.Redemption
+	for rows.Next() {
+		redemption, err := scanRedemption(rows)
+		if err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, redemption)
+	}
+	return redemptions, rows.Err()
+}
+
+func (s *Store) CreateRedemption(ctx context.Context, redemption *model.Redemption) error {
+	query := `INSERT INTO redemptions (reward_id, user_id, status, cost, redeemed_at, approved_at) VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query,
+		redemption.RewardID, redemption.UserID, redemption.Status, redemption.Cost, redemption.RedeemedAt, redemption.ApprovedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	redemption.ID = int(id)
+	return nil
+}
+
+func (s *Store) GetRedemptionByID(ctx context.Context, id int) (*model.Redemption, error) {
+	query := `SELECT ` + redemptionColumns + ` FROM redemptions r JOIN rewards rw ON rw.id = r.reward_id WHERE r.id = ?`
+	return scanRedemption(s.q.QueryRowContext(ctx, query, id))
+}
+
+// GetRedemptionForUpdate loads a redemption for a read-modify-write. SQLite
+// transactions take the write lock at BEGIN, so no row lock is needed.
+func (s *Store) GetRedemptionForUpdate(ctx context.Context, id int) (*model.Redemption, error) {
+	return s.GetRedemptionByID(ctx, id)
+}
+
+func (s *Store) GetRedemptionsByUser(ctx context.Context, householdID, userID int, filters model.RedemptionFilters) ([]*model.Redemption, error) {
+	query := `SELECT ` + redemptionColumns + ` FROM redemptions r JOIN rewards rw ON rw.id = r.reward_id
+			  WHERE rw.household_id = ? AND r.user_id = ?`
+	return s.queryRedemptions(ctx, query, []interface{}{householdID, userID}, filters)
+}
+
+func (s *Store) GetRedemptionsByHousehold(ctx context.Context, householdID int, filters model.RedemptionFilters) ([]*model.Redemption, error) {
+	query := `SELECT ` + redemptionColumns + ` FROM redemptions r JOIN rewards rw ON rw.id = r.reward_id WHERE rw.household_id = ?`
+	return s.queryRedemptions(ctx, query, []interface{}{householdID}, filters)
+}
+
+func (s *Store) UpdateRedemption(ctx context.Context, redemption *model.Redemption) error {
+	query := `UPDATE redemptions SET status = ?, cost = ?, approved_at = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, redemption.Status, redemption.Cost, redemption.ApprovedAt, redemption.ID)
+	return err
+}
+
+// Ledger operations
+const ledgerColumns = `l.id, l.household_id, l.user_id, l.type, l.amount, l.description, l.chore_assignment_id, l.redemption_id, l.created_at`
+
+func scanLedgerEntry(row scanner) (*model.LedgerEntry, error) {
+	entry := &model.LedgerEntry{}
+	err := row.Scan(&entry.ID, &entry.HouseholdID, &entry.UserID, &entry.Type, &entry.Amount, &entry.Description,
+		&entry.ChoreAssignmentID, &entry.RedemptionID, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *Store) queryLedgerEntries(ctx context.Context, query string, args []interface{}, filters model.LedgerFilters) ([]*model.LedgerEntry, error) {
+	if filters.Type != nil {
+		query += ` AND l.type = ?`
+		args = append(args, *filters.Type)
+	}
+	if filters.DateFrom != nil {
+		query += ` AND l.created_at >= ?`
+		args = append(args, *filters.DateFrom)
+	}
+	if filters.DateTo != nil {
+		query += ` AND l.created_at <= ?`
+		args = append(args, *filters.DateTo)
+	}
+
+	query += ` ORDER BY l.created_at DESC, l.id DESC`
+	if filters.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filters.Limit, filters.Offset)
+	}
+
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.LedgerEntry
+	for rows.Next() {
+		entry, err := scanLedgerEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *Store) CreateLedgerEntry(ctx context.Context, entry *model.LedgerEntry) error {
+	query := `INSERT INTO ledger (household_id, user_id, type, amount, description, chore_assignment_id, redemption_id, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query,
+		entry.HouseholdID, entry.UserID, entry.Type, entry.Amount, entry.Description, entry.ChoreAssignmentID, entry.RedemptionID, entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	entry.ID = int(id)
+	return nil
+}
+
+func (s *Store) GetLedgerEntriesByUser(ctx context.Context, householdID, userID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error) {
+	query := `SELECT ` + ledgerColumns + ` FROM ledger l WHERE l.household_id = ? AND l.user_id = ?`
+	return s.queryLedgerEntries(ctx, query, []interface{}{householdID, userID}, filters)
+}
+
+func (s *Store) GetLedgerEntriesByHousehold(ctx context.Context, householdID int, filters model.LedgerFilters) ([]*model.LedgerEntry, error) {
+	query := `SELECT ` + ledgerColumns + ` FROM ledger l WHERE l.household_id = ?`
+	return s.queryLedgerEntries(ctx, query, []interface{}{householdID}, filters)
+}
+
+func (s *Store) GetUserBalance(ctx context.Context, householdID, userID int) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	query := `SELECT COALESCE(SUM(amount), 0) FROM ledger WHERE household_id = ? AND user_id = ?`
+	if err := s.q.QueryRowContext(ctx, query, householdID, userID).Scan(&balance); err != nil {
+		return decimal.Zero, err
+	}
+	// NUMERIC columns are stored as REAL in SQLite; undo float drift in the sum
+	return balance.Round(2), nil
+}
+
+func (s *Store) GetHeldBalance(ctx context.Context, householdID, userID int) (decimal.Decimal, error) {
+	var held decimal.Decimal
+	query := `SELECT COALESCE(SUM(r.cost), 0) FROM redemptions r JOIN rewards rw ON rw.id = r.reward_id
+			  WHERE rw.household_id = ? AND r.user_id = ? AND r.status = 'pending'`
+	if err := s.q.QueryRowContext(ctx, query, householdID, userID).Scan(&held); err != nil {
+		return decimal.Zero, err
+	}
+	return held.Round(2), nil
+}
+
+func (s *Store) GetAllUserBalances(ctx context.Context, householdID int) ([]*model.UserBalance, error) {
+	query := `SELECT m.user_id, COALESCE(SUM(l.amount), 0) FROM household_members m
+			  LEFT JOIN ledger l ON l.household_id = m.household_id AND l.user_id = m.user_id
+			  WHERE m.household_id = ? AND m.removed_at IS NULL GROUP BY m.user_id ORDER BY m.user_id`
+	rows, err := s.q.QueryContext(ctx, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []*model.UserBalance
+	for rows.Next() {
+		balance := &model.UserBalance{}
+		if err := rows.Scan(&balance.UserID, &balance.Balance); err != nil {
+			return nil, err
+		}
+		balance.Balance = balance.Balance.Round(2)
+		balances = append(balances, balance)
+	}
+	return balances, rows.Err()
+}
+
+func (s *Store) CreateAuditLog(ctx context.Context, log *model.AuditLog) error {
+	detailsJSON, _ := json.Marshal(log.Details)
+	query := `INSERT INTO audit_logs (household_id, user_id, action, details, created_at) VALUES (?, ?, ?, ?, ?)`
+	_, err := s.q.ExecContext(ctx, query, log.HouseholdID, log.UserID, log.Action, string(detailsJSON), log.CreatedAt)
+	return err
+}
+
+const auditLogColumns = `a.id, a.household_id, a.user_id, a.action, a.details, a.created_at`
+
+func (s *Store) GetAuditLogs(ctx context.Context, filters model.AuditFilters) ([]*model.AuditLog, error) {
+	query := `SELECT ` + auditLogColumns + ` FROM audit_logs a WHERE 1 = 1`
+	return s.queryAuditLogs(ctx, query, nil, filters)
+}
+
+func (s *Store) GetAuditLogsByHousehold(ctx context.Context, householdID int, filters model.AuditFilters) ([]*model.AuditLog, error) {
+	filters.HouseholdID = &householdID
+	return s.GetAuditLogs(ctx, filters)
+}
+
+func (s *Store) GetAuditLogsByUser(ctx context.Context, userID int, filters model.AuditFilters) ([]*model.AuditLog, error) {
+	filters.UserID = &userID
+	return s.GetAuditLogs(ctx, filters)
+}
+
+func (s *Store) queryAuditLogs(ctx context.Context, query string, args []interface{}, filters model.AuditFilters) ([]*model.AuditLog, error) {
+	if filters.HouseholdID != nil {
+		query += ` AND a.household_id = ?`
+		args = append(args, *filters.HouseholdID)
+	}
+	if filters.UserID != nil {
+		query += ` AND a.user_id = ?`
+		args = append(args, *filters.UserID)
+	}
+	if filters.Action != nil {
+		query += ` AND a.action = ?`
+		args = append(args, *filters.Action)
+	}
+	if filters.DateFrom != nil {
+		query += ` AND a.created_at >= ?`
+		args = append(args, *filters.DateFrom)
+	}
+	if filters.DateTo != nil {
+		query += ` AND a.created_at <= ?`
+		args = append(args, *filters.DateTo)
+	}
+
+	query += ` ORDER BY a.created_at DESC, a.id DESC`
+	if filters.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filters.Limit, filters.Offset)
+	}
+
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []*model.AuditLog
+	for rows.Next() {
+		log := &model.AuditLog{}
+		var details []byte
+		if err := rows.Scan(&log.ID, &log.HouseholdID, &log.UserID, &log.Action, &details, &log.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &log.Details); err != nil {
+				return nil, err
+			}
+		}
+		logs = append(logs, log)
+	}
+	return logs, rows.Err()
+}
+
+// Refresh token operations
+func (s *Store) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	token.ID = int(id)
+	return nil
+}
+
+// GetRefreshTokenForUpdate looks up a token for a read-modify-write. SQLite
+// transactions take the write lock at BEGIN, so no row lock is needed.
+func (s *Store) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	token := &model.RefreshToken{}
+	query := `SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+			  FROM refresh_tokens WHERE token_hash = ?`
+	err := s.q.QueryRowContext(ctx, query, tokenHash).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash,
+		&token.ExpiresAt, &token.UsedAt, &token.RevokedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *Store) MarkRefreshTokenUsed(ctx context.Context, id int, usedAt time.Time) error {
+	query := `UPDATE refresh_tokens SET used_at = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, usedAt, id)
+	return err
+}
+
+func (s *Store) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`
+	_, err := s.q.ExecContext(ctx, query, revokedAt, familyID)
+	return err
+}
+
+func (s *Store) RevokeUserRefreshTokens(ctx context.Context, userID int, revokedAt time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`
+	_, err := s.q.ExecContext(ctx, query, revokedAt, userID)
+	return err
+}
+
+func (s *Store) DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM refresh_tokens WHERE expires_at < ?`
+	result, err := s.q.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// User token operations
+func (s *Store) CreateUserToken(ctx context.Context, token *model.UserToken) error {
+	query := `INSERT INTO user_tokens (user_id, purpose, token_hash, email, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query, token.UserID, token.Purpose, token.TokenHash, token.Email,
+		token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	token.ID = int(id)
+	return nil
+}
+
+// GetUserTokenForUpdate looks up a token for a read-modify-write. SQLite
+// transactions take the write lock at BEGIN, so no row lock is needed.
+func (s *Store) GetUserTokenForUpdate(ctx context.Context, tokenHash string) (*model.UserToken, error) {
+	token := &model.UserToken{}
+	query := `SELECT id, user_id, purpose, token_hash, email, expires_at, used_at, created_at
+			  FROM user_tokens WHERE token_hash = ?`
+	err := s.q.QueryRowContext(ctx, query, tokenHash).Scan(&token.ID, &token.UserID, &token.Purpose, &token.TokenHash,
+		&token.Email, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *Store) MarkUserTokenUsed(ctx context.Context, id int, usedAt time.Time) error {
+	query := `UPDATE user_tokens SET used_at = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, usedAt, id)
+	return err
+}
+
+func (s *Store) InvalidateUserTokens(ctx context.Context, userID int, purpose model.TokenPurpose, usedAt time.Time) error {
+	query := `UPDATE user_tokens SET used_at = ? WHERE user_id = ? AND purpose = ? AND used_at IS NULL`
+	_, err := s.q.ExecContext(ctx, query, usedAt, userID, purpose)
+	return err
+}
+
+func (s *Store) DeleteExpiredUserTokens(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM user_tokens WHERE expires_at < ?`
+	result, err := s.q.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Household device operations
+const householdDeviceColumns = `id, household_id, name, token_hash, created_by, last_used_at, revoked_at, created_at`
+
+func scanHouseholdDevice(row scanner) (*model.HouseholdDevice, error) {
+	device := &model.HouseholdDevice{}
+	err := row.Scan(&device.ID, &device.HouseholdID, &device.Name, &device.TokenHash, &device.CreatedBy,
+		&device.LastUsedAt, &device.RevokedAt, &device.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *Store) CreateHouseholdDevice(ctx context.Context, device *model.HouseholdDevice) error {
+	query := `INSERT INTO household_devices (household_id, name, token_hash, created_by, created_at) VALUES (?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query, device.HouseholdID, device.Name, device.TokenHash, device.CreatedBy,
+		device.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	device.ID = int(id)
+	return nil
+}
+
+func (s *Store) GetHouseholdDeviceByID(ctx context.Context, id int) (*model.HouseholdDevice, error) {
+	query := `SELECT ` + householdDeviceColumns + ` FROM household_devices WHERE id = ?`
+	return scanHouseholdDevice(s.q.QueryRowContext(ctx, query, id))
+}
+
+func (s *Store) GetHouseholdDeviceByTokenHash(ctx context.Context, tokenHash string) (*model.HouseholdDevice, error) {
+	query := `SELECT ` + householdDeviceColumns + ` FROM household_devices WHERE token_hash = ?`
+	return scanHouseholdDevice(s.q.QueryRowContext(ctx, query, tokenHash))
+}
+
+func (s *Store) GetHouseholdDevices(ctx context.Context, householdID int) ([]*model.HouseholdDevice, error) {
+	query := `SELECT ` + householdDeviceColumns + ` FROM household_devices WHERE household_id = ? ORDER BY created_at, id`
+	rows, err := s.q.QueryContext(ctx, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []*model.HouseholdDevice
+	for rows.Next() {
+		device, err := scanHouseholdDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, rows.Err()
+}
+
+func (s *Store) TouchHouseholdDevice(ctx context.Context, id int, usedAt time.Time) error {
+	query := `UPDATE household_devices SET last_used_at = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, usedAt, id)
+	return err
+}
+
+func (s *Store) RevokeHouseholdDevice(ctx context.Context, id int, revokedAt time.Time) error {
+	query := `UPDATE household_devices SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	_, err := s.q.ExecContext(ctx, query, revokedAt, id)
+	return err
+}
+
+// API token operations
+const apiTokenColumns = `id, user_id, household_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, created_at`
+
+func scanAPIToken(row scanner) (*model.APIToken, error) {
+	token := &model.APIToken{}
+	var scopes string
+	err := row.Scan(&token.ID, &token.UserID, &token.HouseholdID, &token.Name, &token.TokenPrefix, &token.TokenHash, &scopes,
+		&token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	token.Scopes = strings.Fields(scopes)
+	return token, nil
+}
+
+func (s *Store) CreateAPIToken(ctx context.Context, token *model.APIToken) error {
+	query := `INSERT INTO api_tokens (user_id, household_id, name, token_prefix, token_hash, scopes, expires_at, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query, token.UserID, token.HouseholdID, token.Name, token.TokenPrefix, token.TokenHash,
+		strings.Join(token.Scopes, " "), token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	token.ID = int(id)
+	return nil
+}
+
+func (s *Store) GetAPITokenByID(ctx context.Context, id int) (*model.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE id = ?`
+	return scanAPIToken(s.q.QueryRowContext(ctx, query, id))
+}
+
+func (s *Store) GetAPITokenByHash(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = ?`
+	return scanAPIToken(s.q.QueryRowContext(ctx, query, tokenHash))
+}
+
+func (s *Store) GetAPITokens(ctx context.Context, userID int) ([]*model.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_id = ? ORDER BY created_at, id`
+	rows, err := s.q.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*model.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func (s *Store) TouchAPIToken(ctx context.Context, id int, usedAt time.Time) error {
+	query := `UPDATE api_tokens SET last_used_at = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, usedAt, id)
+	return err
+}
+
+func (s *Store) DeleteAPIToken(ctx context.Context, id int) error {
+	query := `DELETE FROM api_tokens WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, id)
+	return err
+}
+
+// Role permission operations
+func (s *Store) GetRolePermissions(ctx context.Context, householdID int) ([]*model.RolePermission, error) {
+	query := `SELECT household_id, role, permission, granted FROM role_permissions WHERE household_id = ? ORDER BY role, permission`
+	rows, err := s.q.QueryContext(ctx, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []*model.RolePermission
+	for rows.Next() {
+		permission := &model.RolePermission{}
+		if err := rows.Scan(&permission.HouseholdID, &permission.Role, &permission.Permission, &permission.Granted); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
+
+func (s *Store) CreateRolePermission(ctx context.Context, permission *model.RolePermission) error {
+	query := `INSERT INTO role_permissions (household_id, role, permission, granted) VALUES (?, ?, ?, ?)`
+	_, err := s.q.ExecContext(ctx, query, permission.HouseholdID, permission.Role, permission.Permission, permission.Granted)
+	return err
+}
+
+func (s *Store) DeleteRolePermissions(ctx context.Context, householdID int, role model.Role) error {
+	query := `DELETE FROM role_permissions WHERE household_id = ? AND role = ?`
+	_, err := s.q.ExecContext(ctx, query, householdID, role)
+	return err
+}
+
+// Invitation operations
+const invitationColumns = `id, household_id, code, role, email, max_uses, uses, expires_at, revoked_at, created_by, created_at`
+
+func scanInvitation(row scanner) (*model.Invitation, error) {
+	invitation := &model.Invitation{}
+	err := row.Scan(&invitation.ID, &invitation.HouseholdID, &invitation.Code, &invitation.Role, &invitation.Email,
+		&invitation.MaxUses, &invitation.Uses, &invitation.ExpiresAt, &invitation.RevokedAt, &invitation.CreatedBy,
+		&invitation.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (s *Store) CreateInvitation(ctx context.Context, invitation *model.Invitation) error {
+	query := `INSERT INTO invitations (household_id, code, role, email, max_uses, expires_at, created_by, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query, invitation.HouseholdID, invitation.Code, invitation.Role, invitation.Email,
+		invitation.MaxUses, invitation.ExpiresAt, invitation.CreatedBy, invitation.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	invitation.ID = int(id)
+	return nil
+}
+
+func (s *Store) GetInvitationByID(ctx context.Context, id int) (*model.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE id = ?`
+	return scanInvitation(s.q.QueryRowContext(ctx, query, id))
+}
+
+// GetInvitationByCodeForUpdate loads an invitation for counting a use.
+// SQLite transactions take the write lock at BEGIN, so no row lock is needed.
+func (s *Store) GetInvitationByCodeForUpdate(ctx context.Context, code string) (*model.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE code = ?`
+	return scanInvitation(s.q.QueryRowContext(ctx, query, code))
+}
+
+func (s *Store) GetInvitations(ctx context.Context, householdID int) ([]*model.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE household_id = ? ORDER BY created_at DESC, id DESC`
+	rows, err := s.q.QueryContext(ctx, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []*model.Invitation
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, rows.Err()
+}
+
+func (s *Store) UseInvitation(ctx context.Context, id int) error {
+	query := `UPDATE invitations SET uses = uses + 1 WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, id)
+	return err
+}
+
+func (s *Store) RevokeInvitation(ctx context.Context, id int, revokedAt time.Time) error {
+	query := `UPDATE invitations SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	_, err := s.q.ExecContext(ctx, query, revokedAt, id)
+	return err
+}
+
+// User identity operations
+const userIdentityColumns = `id, user_id, provider, subject, email, last_login_at, created_at`
+
+func scanUserIdentity(row scanner) (*model.UserIdentity, error) {
+	identity := &model.UserIdentity{}
+	err := row.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email,
+		&identity.LastLoginAt, &identity.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (s *Store) CreateUserIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	query := `INSERT INTO user_identities (user_id, provider, subject, email, created_at) VALUES (?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query, identity.UserID, identity.Provider, identity.Subject, identity.Email,
+		identity.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	identity.ID = int(id)
+	return nil
+}
+
+func (s *Store) GetUserIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	query := `SELECT ` + userIdentityColumns + ` FROM user_identities WHERE provider = ? AND subject = ?`
+	return scanUserIdentity(s.q.QueryRowContext(ctx, query, provider, subject))
+}
+
+func (s *Store) GetUserIdentityByID(ctx context.Context, id int) (*model.UserIdentity, error) {
+	query := `SELECT ` + userIdentityColumns + ` FROM user_identities WHERE id = ?`
+	return scanUserIdentity(s.q.QueryRowContext(ctx, query, id))
+}
+
+func (s *Store) GetUserIdentities(ctx context.Context, userID int) ([]*model.UserIdentity, error) {
+	query := `SELECT ` + userIdentityColumns + ` FROM user_identities WHERE user_id = ? ORDER BY created_at, id`
+	rows, err := s.q.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []*model.UserIdentity
+	for rows.Next() {
+		identity, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, rows.Err()
+}
+
+func (s *Store) TouchUserIdentity(ctx context.Context, id int, email *string, loginAt time.Time) error {
+	query := `UPDATE user_identities SET email = ?, last_login_at = ? WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, email, loginAt, id)
+	return err
+}
+
+func (s *Store) DeleteUserIdentity(ctx context.Context, id int) error {
+	query := `DELETE FROM user_identities WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, id)
+	return err
+}
+
+// OIDC authorization request operations
+const oidcAuthRequestColumns = `id, state_hash, provider, code_verifier, nonce, user_id, expires_at, created_at`
+
+func (s *Store) CreateOIDCAuthRequest(ctx context.Context, req *model.OIDCAuthRequest) error {
+	query := `INSERT INTO oidc_auth_requests (state_hash, provider, code_verifier, nonce, user_id, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query, req.StateHash, req.Provider, req.CodeVerifier, req.Nonce, req.UserID,
+		req.ExpiresAt, req.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	req.ID = int(id)
+	return nil
+}
+
+// GetOIDCAuthRequestForUpdate looks up a request for a read-modify-write.
+// SQLite transactions take the write lock at BEGIN, so no row lock is needed.
+func (s *Store) GetOIDCAuthRequestForUpdate(ctx context.Context, stateHash string) (*model.OIDCAuthRequest, error) {
+	req := &model.OIDCAuthRequest{}
+	query := `SELECT ` + oidcAuthRequestColumns + ` FROM oidc_auth_requests WHERE state_hash = ?`
+	err := s.q.QueryRowContext(ctx, query, stateHash).Scan(&req.ID, &req.StateHash, &req.Provider, &req.CodeVerifier,
+		&req.Nonce, &req.UserID, &req.ExpiresAt, &req.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (s *Store) DeleteOIDCAuthRequest(ctx context.Context, id int) error {
+	query := `DELETE FROM oidc_auth_requests WHERE id = ?`
+	_, err := s.q.ExecContext(ctx, query, id)
+	return err
+}
+
+func (s *Store) DeleteExpiredOIDCAuthRequests(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM oidc_auth_requests WHERE expires_at < ?`
+	result, err := s.q.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *Store) CreateRecoveryCode(ctx context.Context, code *model.RecoveryCode) error {
+	query := `INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)`
+	result, err := s.q.ExecContext(ctx, query, code.UserID, code.CodeHash, code.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	code.ID = int(id)
+	return nil
+}
+
+func (s *Store) UseRecoveryCode(ctx context.Context, userID int, codeHash string, usedAt time.Time) (bool, error) {
+	query := `UPDATE recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`
+	result, err := s.q.ExecContext(ctx, query, usedAt, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *Store) CountUnusedRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL`
+	err := s.q.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (s *Store) DeleteRecoveryCodes(ctx context.Context, userID int) error {
+	query := `DELETE FROM recovery_codes WHERE user_id = ?`
+	_, err := s.q.ExecContext(ctx, query, userID)
+	return err
+}
+
+// GetRateLimitBucketForUpdate loads a bucket for a read-modify-write. SQLite
+// transactions take the write lock at BEGIN, so no row lock is needed.
+func (s *Store) GetRateLimitBucketForUpdate(ctx context.Context, key string) (*model.RateLimitBucket, error) {
+	bucket := &model.RateLimitBucket{}
+	query := `SELECT bucket_key, tokens, updated_at FROM rate_limits WHERE bucket_key = ?`
+	err := s.q.QueryRowContext(ctx, query, key).Scan(&bucket.Key, &bucket.Tokens, &bucket.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return bucket, nil
+}
+
+func (s *Store) SaveRateLimitBucket(ctx context.Context, bucket *model.RateLimitBucket) error {
+	query := `INSERT INTO rate_limits (bucket_key, tokens, updated_at) VALUES (?, ?, ?)
+			  ON CONFLICT (bucket_key) DO UPDATE SET tokens = excluded.tokens, updated_at = excluded.updated_at`
+	_, err := s.q.ExecContext(ctx, query, bucket.Key, bucket.Tokens, bucket.UpdatedAt)
+	return err
+}
+
+func (s *Store) DeleteRateLimitBucketsBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM rate_limits WHERE updated_at < ?`
+	result, err := s.q.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Tx runs every Store method on a single *sql.Tx. It embeds a Store whose
+// querier is the transaction, so no method needs a separate implementation.
+type Tx struct {
+	*Store
+	tx *sql.Tx
+}
+
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// Close is a no-op; the underlying database is owned by the parent Store.
+func (t *Tx) Close() error {
+	return nil
+}
+
+func (t *Tx) BeginTx(ctx context.Context) (store.Tx, error) {
+	return nil, store.ErrTxInProgress
+}
+
+// WithTx joins the running transaction instead of starting a nested one.
+func (t *Tx) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	return fn(t)
+}
@@ -0,0 +1,15 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/choreme/choreme/internal/config"
+	"github.com/choreme/choreme/internal/store/storetest"
+)
+
+func TestTimesInUTC(t *testing.T) {
+	cfg := config.DatabaseConfig{Type: "sqlite", Name: filepath.Join(t.TempDir(), "choreme.db")}
+	db := storetest.Open(t, cfg.Type, cfg.DriverName(), cfg.ConnectionString())
+	storetest.TestTimesInUTC(t, New(db))
+}
@@ -0,0 +1,126 @@
+// Package storetest holds the tests every store backend runs against a
+// real, migrated database.
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/choreme/choreme/internal/model"
+	"github.com/choreme/choreme/internal/store"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/file"
+)
+
+// Open connects to a database of dbType and migrates it up. The database
+// is closed when the test ends.
+func Open(t *testing.T, dbType, driverName, dsn string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	var driver database.Driver
+	switch dbType {
+	case "postgres":
+		driver, err = postgres.WithInstance(db, &postgres.Config{})
+	case "mysql":
+		driver, err = mysql.WithInstance(db, &mysql.Config{})
+	case "sqlite":
+		driver, err = sqlite3.WithInstance(db, &sqlite3.Config{})
+	}
+	if err != nil {
+		t.Fatalf("failed to get migration driver: %v", err)
+	}
+	_, self, _, _ := runtime.Caller(0)
+	source, err := (&file.File{}).Open("file://" + filepath.Join(filepath.Dir(self), "..", "..", "..", "migrations", dbType))
+	if err != nil {
+		t.Fatalf("failed to open migrations: %v", err)
+	}
+	m, err := migrate.NewWithInstance("file", source, dbType, driver)
+	if err != nil {
+		t.Fatalf("failed to create migrator: %v", err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+// dstTimes are instants around New York's 2026 daylight saving changes,
+// including both 01:30s of the repeated hour in November.
+func dstTimes(t *testing.T) []time.Time {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallBack := time.Date(2026, 11, 1, 1, 30, 0, 0, newYork)
+	return []time.Time{
+		time.Date(2026, 3, 8, 1, 30, 0, 0, newYork),
+		time.Date(2026, 3, 8, 3, 30, 0, 0, newYork),
+		fallBack,
+		fallBack.Add(time.Hour),
+		time.Date(2026, 11, 1, 3, 0, 0, 0, newYork),
+	}
+}
+
+// TestTimesInUTC stores times in a zone with daylight saving and checks
+// they come back as the same instants in UTC, and that a range given in
+// that zone selects the right ones.
+func TestTimesInUTC(t *testing.T, st store.Store) {
+	ctx := context.Background()
+	times := dstTimes(t)
+
+	var household *model.Household
+	for _, created := range times {
+		h := model.NewHousehold("DST "+created.Format(time.RFC3339), created)
+		if err := st.CreateHousehold(ctx, h); err != nil {
+			t.Fatalf("failed to create household: %v", err)
+		}
+		got, err := st.GetHouseholdByID(ctx, h.ID)
+		if err != nil {
+			t.Fatalf("failed to get household: %v", err)
+		}
+		if !got.CreatedAt.Equal(created) {
+			t.Errorf("created_at = %v, want %v", got.CreatedAt, created.UTC())
+		}
+		if _, offset := got.CreatedAt.Zone(); offset != 0 {
+			t.Errorf("created_at = %v, want it in UTC", got.CreatedAt)
+		}
+		household = h
+	}
+
+	for _, created := range times {
+		err := st.CreateAuditLog(ctx, &model.AuditLog{
+			HouseholdID: household.ID,
+			Action:      "dst_test",
+			CreatedAt:   created,
+		})
+		if err != nil {
+			t.Fatalf("failed to create audit log: %v", err)
+		}
+	}
+	// Only the second 01:30, in standard time, falls in this range
+	from, to := times[3].Add(-time.Minute), times[3].Add(time.Minute)
+	logs, err := st.GetAuditLogsByHousehold(ctx, household.ID, model.AuditFilters{DateFrom: &from, DateTo: &to})
+	if err != nil {
+		t.Fatalf("failed to get audit logs: %v", err)
+	}
+	if len(logs) != 1 || !logs[0].CreatedAt.Equal(times[3]) {
+		t.Errorf("got %d audit logs in [%v, %v], want the one at %v", len(logs), from, to, times[3].UTC())
+	}
+}
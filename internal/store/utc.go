@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Querier is the subset of *sql.DB and *sql.Tx the backends need, so every
+// query runs unchanged either directly or inside a transaction.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// UTC wraps q so every time passed to a query is converted to UTC first.
+//
+// Timestamps are stored in UTC on every backend. The columns carry no zone:
+// PostgreSQL drops the offset of TIMESTAMP values, SQLite compares
+// DATETIME values as text and MySQL converts TIMESTAMP values from the
+// session's timezone, so a time in any other zone would be stored as the
+// wrong instant or compare wrongly against the others.
+func UTC(q Querier) Querier {
+	return utcQuerier{q: q}
+}
+
+type utcQuerier struct {
+	q Querier
+}
+
+func (u utcQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return u.q.ExecContext(ctx, query, utcArgs(args)...)
+}
+
+func (u utcQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return u.q.QueryContext(ctx, query, utcArgs(args)...)
+}
+
+func (u utcQuerier) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return u.q.QueryRowContext(ctx, query, utcArgs(args)...)
+}
+
+// utcArgs returns args with times in UTC, copying them only when one
+// needs converting.
+func utcArgs(args []interface{}) []interface{} {
+	var converted []interface{}
+	for i, arg := range args {
+		var t time.Time
+		switch v := arg.(type) {
+		case time.Time:
+			t = v
+		case *time.Time:
+			if v == nil {
+				continue
+			}
+			t = *v
+		default:
+			continue
+		}
+		if converted == nil {
+			converted = append([]interface{}(nil), args...)
+		}
+		converted[i] = t.UTC()
+	}
+	if converted == nil {
+		return args
+	}
+	return converted
+}
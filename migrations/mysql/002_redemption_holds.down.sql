@@ -0,0 +1,3 @@
+DROP INDEX idx_redemptions_user_status ON redemptions;
+ALTER TABLE redemptions DROP COLUMN cost;
+ALTER TABLE households DROP COLUMN allow_negative_balance;
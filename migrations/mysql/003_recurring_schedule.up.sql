@@ -0,0 +1,14 @@
+-- RRULE frequencies don't fit in 50 characters
+ALTER TABLE chores MODIFY frequency VARCHAR(255);
+
+-- First occurrence of a chore's recurrence and the latest occurrence
+-- generated so far
+ALTER TABLE chores ADD COLUMN schedule_start TIMESTAMP NULL;
+ALTER TABLE chores ADD COLUMN scheduled_until TIMESTAMP NULL;
+
+UPDATE chores SET
+    schedule_start = (SELECT MAX(due_date) FROM assignments WHERE assignments.chore_id = chores.id),
+    scheduled_until = (SELECT MAX(due_date) FROM assignments WHERE assignments.chore_id = chores.id);
+
+-- How far ahead recurring chores are generated
+ALTER TABLE households ADD COLUMN schedule_days_ahead INT DEFAULT 30;
@@ -0,0 +1,5 @@
+DELETE FROM audit_logs WHERE user_id IS NULL;
+ALTER TABLE audit_logs MODIFY user_id INT NOT NULL;
+
+UPDATE assignments SET status = 'late' WHERE status = 'expired';
+ALTER TABLE assignments MODIFY status ENUM('pending', 'in_progress', 'completed', 'approved', 'rejected', 'late') DEFAULT 'pending';
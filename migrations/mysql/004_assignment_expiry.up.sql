@@ -0,0 +1,5 @@
+-- Allow the terminal 'expired' assignment status
+ALTER TABLE assignments MODIFY status ENUM('pending', 'in_progress', 'completed', 'approved', 'rejected', 'late', 'expired') DEFAULT 'pending';
+
+-- Entries written by background jobs have no user
+ALTER TABLE audit_logs MODIFY user_id INT NULL;
@@ -0,0 +1,8 @@
+DELETE FROM user_tokens WHERE purpose = 'two_factor_challenge';
+ALTER TABLE user_tokens MODIFY purpose ENUM('password_reset', 'email_verification') NOT NULL;
+
+DROP TABLE IF EXISTS recovery_codes;
+ALTER TABLE households DROP COLUMN require_two_factor;
+ALTER TABLE users DROP COLUMN totp_last_step;
+ALTER TABLE users DROP COLUMN totp_enabled_at;
+ALTER TABLE users DROP COLUMN totp_secret;
@@ -0,0 +1,25 @@
+-- TOTP secret, set on enrollment and in effect once totp_enabled_at is
+-- set. totp_last_step is the last time step a code was accepted for, so a
+-- code can't be replayed.
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64) NULL;
+ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- Makes two-factor authentication mandatory for admins and managers
+ALTER TABLE households ADD COLUMN require_two_factor BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Single-use recovery codes for users who lost their authenticator. Only a
+-- SHA-256 hash of each code is stored.
+CREATE TABLE recovery_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes(user_id);
+
+-- Allow the tokens handed out between the password and the second factor
+ALTER TABLE user_tokens MODIFY purpose ENUM('password_reset', 'email_verification', 'two_factor_challenge') NOT NULL;
@@ -0,0 +1,23 @@
+-- Profiles for young children have no email address
+ALTER TABLE users MODIFY email VARCHAR(150) NULL;
+
+-- Hash of the short PIN members log in with on a household device
+ALTER TABLE users ADD COLUMN pin_hash VARCHAR(255) NULL;
+
+-- Shared devices, such as a family tablet, that list the household's
+-- members and let them log in with their PIN. Only a SHA-256 hash of each
+-- device token is stored.
+CREATE TABLE household_devices (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    household_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_by INT NULL,
+    last_used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (household_id) REFERENCES households(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_household_devices_household_id ON household_devices(household_id);
@@ -0,0 +1,32 @@
+-- Accounts at external OpenID Connect providers linked to users, keyed by
+-- the provider's stable subject identifier.
+CREATE TABLE user_identities (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    provider VARCHAR(100) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    last_login_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+
+-- Pending OpenID Connect authorization requests. The state is stored as a
+-- SHA-256 hash; the PKCE verifier and nonce are needed to finish the login.
+-- A user ID marks a request to link an identity to that user.
+CREATE TABLE oidc_auth_requests (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    state_hash VARCHAR(64) NOT NULL UNIQUE,
+    provider VARCHAR(100) NOT NULL,
+    code_verifier VARCHAR(100) NOT NULL,
+    nonce VARCHAR(100) NOT NULL,
+    user_id INT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_oidc_auth_requests_expires_at ON oidc_auth_requests(expires_at);
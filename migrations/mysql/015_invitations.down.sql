@@ -0,0 +1,6 @@
+-- Bring back one open invite code per household
+UPDATE households h SET invite_code = (
+    SELECT MAX(code) FROM invitations i
+    WHERE i.household_id = h.id AND i.revoked_at IS NULL AND i.email IS NULL
+);
+DROP TABLE IF EXISTS invitations;
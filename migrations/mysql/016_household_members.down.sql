@@ -0,0 +1,15 @@
+ALTER TABLE api_tokens DROP FOREIGN KEY fk_api_tokens_household;
+ALTER TABLE api_tokens DROP COLUMN household_id;
+
+ALTER TABLE ledger DROP FOREIGN KEY fk_ledger_household;
+DROP INDEX idx_ledger_household_user ON ledger;
+ALTER TABLE ledger DROP COLUMN household_id;
+
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN removed_at TIMESTAMP NULL;
+
+-- Users keep the role and status of the household they are currently in
+UPDATE users JOIN household_members m ON m.household_id = users.household_id AND m.user_id = users.id
+SET users.role = m.role, users.deactivated_at = m.deactivated_at, users.removed_at = m.removed_at;
+
+DROP TABLE IF EXISTS household_members;
@@ -0,0 +1,35 @@
+-- Users can belong to several households, with a role in each. A user's
+-- role, deactivation and removal now live on their membership;
+-- users.household_id is the household they are currently in, where logins
+-- and refreshed sessions land, and users.role is no longer read.
+CREATE TABLE household_members (
+    household_id INT NOT NULL,
+    user_id INT NOT NULL,
+    role ENUM('system_admin', 'admin', 'manager', 'worker', 'observer') NOT NULL,
+    deactivated_at TIMESTAMP NULL,
+    removed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (household_id, user_id),
+    INDEX idx_household_members_user_id (user_id),
+    FOREIGN KEY (household_id) REFERENCES households(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO household_members (household_id, user_id, role, deactivated_at, removed_at, created_at)
+SELECT household_id, id, role, deactivated_at, removed_at, created_at FROM users;
+
+ALTER TABLE users DROP COLUMN removed_at;
+ALTER TABLE users DROP COLUMN deactivated_at;
+
+-- Balances are per membership, so every entry belongs to a household
+ALTER TABLE ledger ADD COLUMN household_id INT NULL;
+UPDATE ledger JOIN users ON users.id = ledger.user_id SET ledger.household_id = users.household_id;
+ALTER TABLE ledger MODIFY household_id INT NOT NULL;
+ALTER TABLE ledger ADD CONSTRAINT fk_ledger_household FOREIGN KEY (household_id) REFERENCES households(id) ON DELETE CASCADE;
+CREATE INDEX idx_ledger_household_user ON ledger(household_id, user_id);
+
+-- Personal access tokens act in the household they were created in
+ALTER TABLE api_tokens ADD COLUMN household_id INT NULL;
+UPDATE api_tokens JOIN users ON users.id = api_tokens.user_id SET api_tokens.household_id = users.household_id;
+ALTER TABLE api_tokens MODIFY household_id INT NOT NULL;
+ALTER TABLE api_tokens ADD CONSTRAINT fk_api_tokens_household FOREIGN KEY (household_id) REFERENCES households(id) ON DELETE CASCADE;
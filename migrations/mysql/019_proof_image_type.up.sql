@@ -0,0 +1,3 @@
+-- The content type a proof image was processed into. Images stored before
+-- uploads were processed have none and are served as plain downloads.
+ALTER TABLE assignments ADD COLUMN proof_image_type VARCHAR(32);
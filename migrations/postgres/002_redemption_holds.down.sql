@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_redemptions_reward_id;
+DROP INDEX IF EXISTS idx_redemptions_user_status;
+ALTER TABLE redemptions DROP COLUMN IF EXISTS cost;
+ALTER TABLE households DROP COLUMN IF EXISTS allow_negative_balance;
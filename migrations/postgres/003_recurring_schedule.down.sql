@@ -0,0 +1,4 @@
+ALTER TABLE households DROP COLUMN IF EXISTS schedule_days_ahead;
+ALTER TABLE chores DROP COLUMN IF EXISTS scheduled_until;
+ALTER TABLE chores DROP COLUMN IF EXISTS schedule_start;
+ALTER TABLE chores ALTER COLUMN frequency TYPE VARCHAR(50);
@@ -0,0 +1,7 @@
+DELETE FROM audit_logs WHERE user_id IS NULL;
+ALTER TABLE audit_logs ALTER COLUMN user_id SET NOT NULL;
+
+UPDATE assignments SET status = 'late' WHERE status = 'expired';
+ALTER TABLE assignments DROP CONSTRAINT assignments_status_check;
+ALTER TABLE assignments ADD CONSTRAINT assignments_status_check
+    CHECK (status IN ('pending', 'in_progress', 'completed', 'approved', 'rejected', 'late'));
@@ -0,0 +1,7 @@
+-- Allow the terminal 'expired' assignment status
+ALTER TABLE assignments DROP CONSTRAINT assignments_status_check;
+ALTER TABLE assignments ADD CONSTRAINT assignments_status_check
+    CHECK (status IN ('pending', 'in_progress', 'completed', 'approved', 'rejected', 'late', 'expired'));
+
+-- Entries written by background jobs have no user
+ALTER TABLE audit_logs ALTER COLUMN user_id DROP NOT NULL;
@@ -0,0 +1,3 @@
+-- Bumped whenever a user's household, role, email or password changes, so
+-- access tokens issued before the change stop being accepted
+ALTER TABLE users ADD COLUMN session_epoch INT NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+-- Consecutive failed logins and the lockout they triggered; both reset on
+-- a successful login
+ALTER TABLE users ADD COLUMN failed_logins INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN locked_until TIMESTAMP;
+
+-- Token buckets of the store-backed rate limiter, shared by all instances
+CREATE TABLE rate_limits (
+    bucket_key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_rate_limits_updated_at ON rate_limits(updated_at);
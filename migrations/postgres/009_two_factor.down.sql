@@ -0,0 +1,10 @@
+DELETE FROM user_tokens WHERE purpose = 'two_factor_challenge';
+ALTER TABLE user_tokens DROP CONSTRAINT user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+    CHECK (purpose IN ('password_reset', 'email_verification'));
+
+DROP TABLE IF EXISTS recovery_codes;
+ALTER TABLE households DROP COLUMN require_two_factor;
+ALTER TABLE users DROP COLUMN totp_last_step;
+ALTER TABLE users DROP COLUMN totp_enabled_at;
+ALTER TABLE users DROP COLUMN totp_secret;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS household_devices;
+ALTER TABLE users DROP COLUMN pin_hash;
+
+-- Profiles without an email address can't be kept
+DELETE FROM users WHERE email IS NULL;
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
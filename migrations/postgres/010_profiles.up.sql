@@ -0,0 +1,21 @@
+-- Profiles for young children have no email address
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+
+-- Hash of the short PIN members log in with on a household device
+ALTER TABLE users ADD COLUMN pin_hash VARCHAR(255);
+
+-- Shared devices, such as a family tablet, that list the household's
+-- members and let them log in with their PIN. Only a SHA-256 hash of each
+-- device token is stored.
+CREATE TABLE household_devices (
+    id SERIAL PRIMARY KEY,
+    household_id INT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_household_devices_household_id ON household_devices(household_id);
@@ -0,0 +1,10 @@
+-- Per-household changes to the default permissions of a role. Each row
+-- grants or revokes one permission; permissions without a row keep the
+-- role's default.
+CREATE TABLE role_permissions (
+    household_id INT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    permission VARCHAR(50) NOT NULL,
+    granted BOOLEAN NOT NULL,
+    PRIMARY KEY (household_id, role, permission)
+);
@@ -0,0 +1,5 @@
+-- Deactivated members can't log in until they are reactivated. Removed
+-- members have left the household; their row stays so the ledger and other
+-- history keep pointing at them, but their login details are cleared.
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN removed_at TIMESTAMP;
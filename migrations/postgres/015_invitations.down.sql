@@ -0,0 +1,6 @@
+-- Bring back one open invite code per household
+UPDATE households SET invite_code = (
+    SELECT MAX(code) FROM invitations
+    WHERE invitations.household_id = households.id AND revoked_at IS NULL AND email IS NULL
+);
+DROP TABLE IF EXISTS invitations;
@@ -0,0 +1,23 @@
+-- Invitations to join a household, replacing the single never-expiring
+-- households.invite_code. Each has the role it joins with and may be bound
+-- to an email address, limited in uses, expire and be revoked.
+CREATE TABLE invitations (
+    id SERIAL PRIMARY KEY,
+    household_id INT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    role VARCHAR(20) NOT NULL DEFAULT 'worker',
+    email VARCHAR(150),
+    max_uses INT,
+    uses INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_invitations_household_id ON invitations(household_id);
+
+-- Existing invite codes keep working as worker invitations
+INSERT INTO invitations (household_id, code, role, created_at)
+SELECT id, invite_code, 'worker', CURRENT_TIMESTAMP FROM households WHERE invite_code IS NOT NULL;
+UPDATE households SET invite_code = NULL;
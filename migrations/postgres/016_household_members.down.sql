@@ -0,0 +1,13 @@
+ALTER TABLE api_tokens DROP COLUMN household_id;
+
+DROP INDEX idx_ledger_household_user;
+ALTER TABLE ledger DROP COLUMN household_id;
+
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN removed_at TIMESTAMP;
+
+-- Users keep the role and status of the household they are currently in
+UPDATE users SET role = m.role, deactivated_at = m.deactivated_at, removed_at = m.removed_at
+FROM household_members m WHERE m.household_id = users.household_id AND m.user_id = users.id;
+
+DROP TABLE IF EXISTS household_members;
@@ -0,0 +1,32 @@
+-- Users can belong to several households, with a role in each. A user's
+-- role, deactivation and removal now live on their membership;
+-- users.household_id is the household they are currently in, where logins
+-- and refreshed sessions land, and users.role is no longer read.
+CREATE TABLE household_members (
+    household_id INT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('system_admin', 'admin', 'manager', 'worker', 'observer')),
+    deactivated_at TIMESTAMP,
+    removed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (household_id, user_id)
+);
+
+CREATE INDEX idx_household_members_user_id ON household_members(user_id);
+
+INSERT INTO household_members (household_id, user_id, role, deactivated_at, removed_at, created_at)
+SELECT household_id, id, role, deactivated_at, removed_at, created_at FROM users;
+
+ALTER TABLE users DROP COLUMN removed_at;
+ALTER TABLE users DROP COLUMN deactivated_at;
+
+-- Balances are per membership, so every entry belongs to a household
+ALTER TABLE ledger ADD COLUMN household_id INT REFERENCES households(id) ON DELETE CASCADE;
+UPDATE ledger SET household_id = users.household_id FROM users WHERE users.id = ledger.user_id;
+ALTER TABLE ledger ALTER COLUMN household_id SET NOT NULL;
+CREATE INDEX idx_ledger_household_user ON ledger(household_id, user_id);
+
+-- Personal access tokens act in the household they were created in
+ALTER TABLE api_tokens ADD COLUMN household_id INT REFERENCES households(id) ON DELETE CASCADE;
+UPDATE api_tokens SET household_id = users.household_id FROM users WHERE users.id = api_tokens.user_id;
+ALTER TABLE api_tokens ALTER COLUMN household_id SET NOT NULL;
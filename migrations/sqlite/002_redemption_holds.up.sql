@@ -0,0 +1,8 @@
+-- Household setting allowing redemptions to take a balance below zero
+ALTER TABLE households ADD COLUMN allow_negative_balance INTEGER DEFAULT 0;
+
+-- Cost held by a redemption, captured when it is requested
+ALTER TABLE redemptions ADD COLUMN cost NUMERIC(10,2) NOT NULL DEFAULT 0.00;
+
+CREATE INDEX idx_redemptions_user_status ON redemptions(user_id, status);
+CREATE INDEX idx_redemptions_reward_id ON redemptions(reward_id);
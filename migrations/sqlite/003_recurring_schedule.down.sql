@@ -0,0 +1,3 @@
+ALTER TABLE households DROP COLUMN schedule_days_ahead;
+ALTER TABLE chores DROP COLUMN scheduled_until;
+ALTER TABLE chores DROP COLUMN schedule_start;
@@ -0,0 +1,11 @@
+-- First occurrence of a chore's recurrence and the latest occurrence
+-- generated so far
+ALTER TABLE chores ADD COLUMN schedule_start DATETIME;
+ALTER TABLE chores ADD COLUMN scheduled_until DATETIME;
+
+UPDATE chores SET
+    schedule_start = (SELECT MAX(due_date) FROM assignments WHERE assignments.chore_id = chores.id),
+    scheduled_until = (SELECT MAX(due_date) FROM assignments WHERE assignments.chore_id = chores.id);
+
+-- How far ahead recurring chores are generated
+ALTER TABLE households ADD COLUMN schedule_days_ahead INTEGER DEFAULT 30;
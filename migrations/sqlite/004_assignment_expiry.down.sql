@@ -0,0 +1,39 @@
+DELETE FROM audit_logs WHERE user_id IS NULL;
+CREATE TABLE audit_logs_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action TEXT NOT NULL,
+    details TEXT, -- JSON stored as TEXT
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO audit_logs_old SELECT id, household_id, user_id, action, details, created_at FROM audit_logs;
+DROP TABLE audit_logs;
+ALTER TABLE audit_logs_old RENAME TO audit_logs;
+CREATE INDEX idx_audit_logs_household_id ON audit_logs(household_id);
+CREATE INDEX idx_audit_logs_user_id ON audit_logs(user_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at);
+
+UPDATE assignments SET status = 'late' WHERE status = 'expired';
+CREATE TABLE assignments_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chore_id INTEGER NOT NULL REFERENCES chores(id) ON DELETE CASCADE,
+    assigned_to INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    due_date DATETIME NOT NULL,
+    percent_complete NUMERIC(5,2) DEFAULT 0.00,
+    status TEXT DEFAULT 'pending' CHECK (status IN ('pending', 'in_progress', 'completed', 'approved', 'rejected', 'late')),
+    proof_image BLOB,
+    approval_notes TEXT,
+    completed_at DATETIME,
+    approved_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO assignments_old SELECT id, chore_id, assigned_to, due_date, percent_complete, status, proof_image,
+    approval_notes, completed_at, approved_at, created_at, updated_at FROM assignments;
+DROP TABLE assignments;
+ALTER TABLE assignments_old RENAME TO assignments;
+CREATE INDEX idx_assignments_chore_id ON assignments(chore_id);
+CREATE INDEX idx_assignments_assigned_to ON assignments(assigned_to);
+CREATE INDEX idx_assignments_due_date ON assignments(due_date);
+CREATE INDEX idx_assignments_status ON assignments(status);
@@ -0,0 +1,31 @@
+DROP TABLE IF EXISTS household_devices;
+ALTER TABLE users DROP COLUMN pin_hash;
+
+-- Profiles without an email address can't be kept
+DELETE FROM users WHERE email IS NULL;
+CREATE TABLE users_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    email TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('system_admin', 'admin', 'manager', 'worker', 'observer')),
+    notification_pref_email INTEGER DEFAULT 1,
+    notification_pref_push INTEGER DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    session_epoch INTEGER NOT NULL DEFAULT 0,
+    email_verified_at DATETIME,
+    failed_logins INTEGER NOT NULL DEFAULT 0,
+    locked_until DATETIME,
+    totp_secret TEXT,
+    totp_enabled_at DATETIME,
+    totp_last_step INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO users_new SELECT id, household_id, name, email, password_hash, role, notification_pref_email,
+    notification_pref_push, created_at, updated_at, session_epoch, email_verified_at, failed_logins, locked_until,
+    totp_secret, totp_enabled_at, totp_last_step FROM users;
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+CREATE INDEX idx_users_household_id ON users(household_id);
+CREATE INDEX idx_users_email ON users(email);
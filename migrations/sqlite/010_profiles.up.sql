@@ -0,0 +1,47 @@
+-- Profiles for young children have no email address. SQLite can't drop a
+-- NOT NULL constraint, so rebuild the table.
+CREATE TABLE users_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    email TEXT UNIQUE,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('system_admin', 'admin', 'manager', 'worker', 'observer')),
+    notification_pref_email INTEGER DEFAULT 1,
+    notification_pref_push INTEGER DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    session_epoch INTEGER NOT NULL DEFAULT 0,
+    email_verified_at DATETIME,
+    failed_logins INTEGER NOT NULL DEFAULT 0,
+    locked_until DATETIME,
+    totp_secret TEXT,
+    totp_enabled_at DATETIME,
+    totp_last_step INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO users_new SELECT id, household_id, name, email, password_hash, role, notification_pref_email,
+    notification_pref_push, created_at, updated_at, session_epoch, email_verified_at, failed_logins, locked_until,
+    totp_secret, totp_enabled_at, totp_last_step FROM users;
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+CREATE INDEX idx_users_household_id ON users(household_id);
+CREATE INDEX idx_users_email ON users(email);
+
+-- Hash of the short PIN members log in with on a household device
+ALTER TABLE users ADD COLUMN pin_hash TEXT;
+
+-- Shared devices, such as a family tablet, that list the household's
+-- members and let them log in with their PIN. Only a SHA-256 hash of each
+-- device token is stored.
+CREATE TABLE household_devices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_household_devices_household_id ON household_devices(household_id);
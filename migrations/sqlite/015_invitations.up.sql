@@ -0,0 +1,23 @@
+-- Invitations to join a household, replacing the single never-expiring
+-- households.invite_code. Each has the role it joins with and may be bound
+-- to an email address, limited in uses, expire and be revoked.
+CREATE TABLE invitations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    code TEXT NOT NULL UNIQUE,
+    role TEXT NOT NULL DEFAULT 'worker',
+    email TEXT,
+    max_uses INTEGER,
+    uses INTEGER NOT NULL DEFAULT 0,
+    expires_at DATETIME,
+    revoked_at DATETIME,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_invitations_household_id ON invitations(household_id);
+
+-- Existing invite codes keep working as worker invitations
+INSERT INTO invitations (household_id, code, role, created_at)
+SELECT id, invite_code, 'worker', CURRENT_TIMESTAMP FROM households WHERE invite_code IS NOT NULL;
+UPDATE households SET invite_code = NULL;
@@ -0,0 +1,16 @@
+ALTER TABLE api_tokens DROP COLUMN household_id;
+
+DROP INDEX idx_ledger_household_user;
+ALTER TABLE ledger DROP COLUMN household_id;
+
+ALTER TABLE users ADD COLUMN deactivated_at DATETIME;
+ALTER TABLE users ADD COLUMN removed_at DATETIME;
+
+-- Users keep the role and status of the household they are currently in
+UPDATE users SET
+    role = (SELECT role FROM household_members m WHERE m.household_id = users.household_id AND m.user_id = users.id),
+    deactivated_at = (SELECT deactivated_at FROM household_members m WHERE m.household_id = users.household_id AND m.user_id = users.id),
+    removed_at = (SELECT removed_at FROM household_members m WHERE m.household_id = users.household_id AND m.user_id = users.id)
+WHERE EXISTS (SELECT 1 FROM household_members m WHERE m.household_id = users.household_id AND m.user_id = users.id);
+
+DROP TABLE IF EXISTS household_members;
@@ -0,0 +1,32 @@
+-- Users can belong to several households, with a role in each. A user's
+-- role, deactivation and removal now live on their membership;
+-- users.household_id is the household they are currently in, where logins
+-- and refreshed sessions land, and users.role is no longer read.
+CREATE TABLE household_members (
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('system_admin', 'admin', 'manager', 'worker', 'observer')),
+    deactivated_at DATETIME,
+    removed_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (household_id, user_id)
+);
+
+CREATE INDEX idx_household_members_user_id ON household_members(user_id);
+
+INSERT INTO household_members (household_id, user_id, role, deactivated_at, removed_at, created_at)
+SELECT household_id, id, role, deactivated_at, removed_at, created_at FROM users;
+
+ALTER TABLE users DROP COLUMN removed_at;
+ALTER TABLE users DROP COLUMN deactivated_at;
+
+-- Balances are per membership, so every entry belongs to a household.
+-- SQLite can't add a NOT NULL column without a default; the store always
+-- sets it.
+ALTER TABLE ledger ADD COLUMN household_id INTEGER REFERENCES households(id) ON DELETE CASCADE;
+UPDATE ledger SET household_id = (SELECT household_id FROM users WHERE users.id = ledger.user_id);
+CREATE INDEX idx_ledger_household_user ON ledger(household_id, user_id);
+
+-- Personal access tokens act in the household they were created in
+ALTER TABLE api_tokens ADD COLUMN household_id INTEGER REFERENCES households(id) ON DELETE CASCADE;
+UPDATE api_tokens SET household_id = (SELECT household_id FROM users WHERE users.id = api_tokens.user_id);
@@ -0,0 +1,3 @@
+-- A system administrator can suspend a household. Its members can't log in
+-- to it or use it until the suspension is lifted; nothing is deleted.
+ALTER TABLE households ADD COLUMN suspended_at DATETIME;
@@ -0,0 +1,6 @@
+ALTER TABLE households DROP COLUMN locale;
+ALTER TABLE households DROP COLUMN week_start;
+ALTER TABLE households DROP COLUMN timezone;
+ALTER TABLE households DROP COLUMN decimal_places;
+ALTER TABLE households DROP COLUMN points_label;
+ALTER TABLE households DROP COLUMN currency;
@@ -0,0 +1,12 @@
+-- How a household counts values: in a currency, or in points under a label
+-- of its own, kept to decimal_places places
+ALTER TABLE households ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE households ADD COLUMN points_label VARCHAR(32);
+ALTER TABLE households ADD COLUMN decimal_places INTEGER NOT NULL DEFAULT 2;
+
+-- Where a household lives: its IANA timezone decides its days, and so
+-- "today", recurrences, expiry and report buckets. week_start is the
+-- weekday weeks start on, 0 for Sunday.
+ALTER TABLE households ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE households ADD COLUMN week_start INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE households ADD COLUMN locale VARCHAR(35) NOT NULL DEFAULT 'en-US';
@@ -16,7 +16,10 @@ import './App.css';
 type AuthMode = 'login' | 'register' | 'join';
 
 const AuthFlow: React.FC = () => {
-  const [authMode, setAuthMode] = useState<AuthMode>('login');
+  // Invitation links open the join form
+  const [authMode, setAuthMode] = useState<AuthMode>(
+    new URLSearchParams(window.location.search).has('code') ? 'join' : 'login'
+  );
   
   return (
     <>
@@ -101,7 +101,7 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
   };
 
   const logout = () => {
-    apiService.clearToken();
+    apiService.logout();
     setUser(null);
   };
 
@@ -11,7 +11,7 @@ const JoinHouseholdPage: React.FC<JoinHouseholdPageProps> = ({ onModeChange }) =
     name: '',
     email: '',
     password: '',
-    invite_code: '',
+    invite_code: new URLSearchParams(window.location.search).get('code') || '',
   });
   const [error, setError] = useState('');
   const [isLoading, setIsLoading] = useState(false);
@@ -1,6 +1,7 @@
-import React, { useState } from 'react';
+import React, { useEffect, useState } from 'react';
 import { useAuth } from '../hooks/useAuth';
-import { RegisterRequest } from '../types';
+import { apiService } from '../services/api';
+import { RegisterRequest, SetupStatus } from '../types';
 
 interface RegisterPageProps {
   onModeChange: (mode: 'login' | 'register' | 'join') => void;
@@ -15,9 +16,20 @@ const RegisterPage: React.FC<RegisterPageProps> = ({ onModeChange }) => {
   });
   const [error, setError] = useState('');
   const [isLoading, setIsLoading] = useState(false);
+  const [setup, setSetup] = useState<SetupStatus | null>(null);
   
   const { register } = useAuth();
 
+  useEffect(() => {
+    apiService.getSetupStatus().then(response => {
+      if (response.success && response.data) {
+        setSetup(response.data);
+      }
+    }).catch(() => {});
+  }, []);
+
+  const closed = setup !== null && !setup.setup_required && !setup.registration_open;
+
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     setError('');
@@ -47,7 +59,11 @@ const RegisterPage: React.FC<RegisterPageProps> = ({ onModeChange }) => {
             <span className="text-2xl font-bold text-secondary-600">CM</span>
           </div>
           <h1 className="text-3xl font-bold text-white mb-2">Get Started</h1>
-          <p className="text-secondary-100">Create your household on ChoreMe</p>
+          <p className="text-secondary-100">
+            {setup?.setup_required
+              ? 'Set up ChoreMe as its administrator'
+              : 'Create your household on ChoreMe'}
+          </p>
         </div>
 
         <form onSubmit={handleSubmit} className="space-y-4">
@@ -103,6 +119,27 @@ const RegisterPage: React.FC<RegisterPageProps> = ({ onModeChange }) => {
             />
           </div>
 
+          {setup?.setup_required && (
+            <div>
+              <input
+                type="text"
+                name="setup_token"
+                placeholder="Setup token from the server log"
+                value={formData.setup_token || ''}
+                onChange={handleChange}
+                className="input-field"
+                required
+                autoComplete="off"
+              />
+            </div>
+          )}
+
+          {closed && (
+            <div className="bg-yellow-50 border border-yellow-200 text-yellow-700 px-4 py-3 rounded-lg text-sm">
+              Registration is closed. Ask a household admin for an invitation.
+            </div>
+          )}
+
           {error && (
             <div className="bg-red-50 border border-red-200 text-red-600 px-4 py-3 rounded-lg text-sm">
               {error}
@@ -111,7 +148,7 @@ const RegisterPage: React.FC<RegisterPageProps> = ({ onModeChange }) => {
 
           <button
             type="submit"
-            disabled={isLoading}
+            disabled={isLoading || closed}
             className="btn-primary w-full"
           >
             {isLoading ? 'Creating Account...' : 'Create Account'}
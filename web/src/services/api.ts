@@ -4,26 +4,32 @@ import {
   RegisterRequest, 
   LoginRequest, 
   JoinHouseholdRequest,
+  SetupStatus,
   User,
   Assignment,
   Chore,
   LedgerEntry,
   Reward,
-  Redemption
+  Redemption,
+  ProofImage
 } from '../types';
 
 const API_BASE_URL = process.env.REACT_APP_API_URL || 'http://localhost:8080/api/v1';
 
 class ApiService {
   private token: string | null = null;
+  private refreshToken: string | null = null;
+  private refreshing: Promise<boolean> | null = null;
 
   constructor() {
     this.token = localStorage.getItem('auth_token');
+    this.refreshToken = localStorage.getItem('refresh_token');
   }
 
   private async request<T>(
     endpoint: string, 
-    options: RequestInit = {}
+    options: RequestInit = {},
+    retry = true
   ): Promise<APIResponse<T>> {
     const url = `${API_BASE_URL}${endpoint}`;
     
@@ -38,6 +44,12 @@ class ApiService {
 
     try {
       const response = await fetch(url, config);
+
+      // Access tokens are short-lived; renew once and replay the request
+      if (response.status === 401 && retry && this.token && (await this.refresh())) {
+        return this.request<T>(endpoint, options, false);
+      }
+
       const data = await response.json();
       
       if (!response.ok) {
@@ -56,12 +68,65 @@ class ApiService {
     localStorage.setItem('auth_token', token);
   }
 
+  setTokens(auth: AuthResponse) {
+    this.setToken(auth.token);
+    this.refreshToken = auth.refresh_token;
+    localStorage.setItem('refresh_token', auth.refresh_token);
+  }
+
   clearToken() {
     this.token = null;
+    this.refreshToken = null;
     localStorage.removeItem('auth_token');
+    localStorage.removeItem('refresh_token');
+  }
+
+  // refresh exchanges the refresh token for new tokens. Concurrent callers
+  // share one request, since each refresh token can only be used once.
+  private refresh(): Promise<boolean> {
+    if (!this.refreshToken) {
+      return Promise.resolve(false);
+    }
+    if (!this.refreshing) {
+      this.refreshing = fetch(`${API_BASE_URL}/auth/refresh`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: this.refreshToken }),
+      })
+        .then(async (response) => {
+          const data: APIResponse<AuthResponse> = await response.json();
+          if (!response.ok || !data.success || !data.data) {
+            this.clearToken();
+            return false;
+          }
+          this.setTokens(data.data);
+          return true;
+        })
+        .catch(() => false)
+        .finally(() => {
+          this.refreshing = null;
+        });
+    }
+    return this.refreshing;
+  }
+
+  async logout(): Promise<void> {
+    const refreshToken = this.refreshToken;
+    this.clearToken();
+    if (refreshToken) {
+      await fetch(`${API_BASE_URL}/auth/logout`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: refreshToken }),
+      }).catch(() => undefined);
+    }
   }
 
   // Auth endpoints
+  async getSetupStatus(): Promise<APIResponse<SetupStatus>> {
+    return this.request<SetupStatus>('/auth/setup');
+  }
+
   async register(data: RegisterRequest): Promise<APIResponse<AuthResponse>> {
     const response = await this.request<AuthResponse>('/auth/register', {
       method: 'POST',
@@ -69,7 +134,7 @@ class ApiService {
     });
     
     if (response.success && response.data) {
-      this.setToken(response.data.token);
+      this.setTokens(response.data);
     }
     
     return response;
@@ -82,7 +147,7 @@ class ApiService {
     });
     
     if (response.success && response.data) {
-      this.setToken(response.data.token);
+      this.setTokens(response.data);
     }
     
     return response;
@@ -95,7 +160,7 @@ class ApiService {
     });
     
     if (response.success && response.data) {
-      this.setToken(response.data.token);
+      this.setTokens(response.data);
     }
     
     return response;
@@ -166,8 +231,9 @@ class ApiService {
   }
 
   // Photo upload
-  async uploadPhoto(file: File): Promise<APIResponse<{ url: string }>> {
+  async uploadPhoto(assignmentId: string, file: File): Promise<APIResponse<ProofImage>> {
     const formData = new FormData();
+    formData.append('assignment_id', assignmentId);
     formData.append('photo', file);
 
     const response = await fetch(`${API_BASE_URL}/upload/photo`, {
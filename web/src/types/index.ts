@@ -100,6 +100,8 @@ export interface Redemption {
 
 export interface AuthResponse {
   token: string;
+  refresh_token: string;
+  expires_in: number;
   user: User;
 }
 
@@ -114,6 +116,21 @@ export interface RegisterRequest {
   email: string;
   password: string;
   household_name?: string;
+  setup_token?: string;
+}
+
+export interface ProofImage {
+  assignment_id: number;
+  url: string;
+  content_type: string;
+  width: number;
+  height: number;
+  size: number;
+}
+
+export interface SetupStatus {
+  setup_required: boolean;
+  registration_open: boolean;
 }
 
 export interface LoginRequest {